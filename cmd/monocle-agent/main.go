@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/monocle-dev/monocle/internal/agent"
+)
+
+func main() {
+	var err error
+
+	err = godotenv.Load()
+	if err != nil {
+		log.Println("No .env file found, using environment variables directly")
+	}
+
+	serverURL := os.Getenv("MONOCLE_SERVER_URL")
+
+	if serverURL == "" {
+		log.Fatal("MONOCLE_SERVER_URL environment variable is not set")
+	}
+
+	token := os.Getenv("MONOCLE_AGENT_TOKEN")
+
+	if token == "" {
+		log.Fatal("MONOCLE_AGENT_TOKEN environment variable is not set")
+	}
+
+	syncInterval := 60 * time.Second
+
+	if value := os.Getenv("MONOCLE_AGENT_SYNC_INTERVAL"); value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds <= 0 {
+			log.Fatalf("Invalid MONOCLE_AGENT_SYNC_INTERVAL: %s", value)
+		}
+		syncInterval = time.Duration(seconds) * time.Second
+	}
+
+	// Setup graceful shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	log.Printf("Starting Monocle agent for %s", serverURL)
+
+	if err = agent.New(serverURL, token, syncInterval).Run(ctx); err != nil {
+		log.Fatalf("Agent failed: %v", err)
+	}
+
+	log.Println("Agent stopped")
+}
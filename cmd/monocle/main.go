@@ -8,9 +8,12 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/monocle-dev/monocle/db"
 	"github.com/monocle-dev/monocle/internal/auth"
+	"github.com/monocle-dev/monocle/internal/escalation"
 	"github.com/monocle-dev/monocle/internal/handlers"
+	"github.com/monocle-dev/monocle/internal/outbox"
 	"github.com/monocle-dev/monocle/internal/router"
 	"github.com/monocle-dev/monocle/internal/scheduler"
+	"github.com/monocle-dev/monocle/internal/services"
 )
 
 func main() {
@@ -40,10 +43,17 @@ func main() {
 		log.Fatalf("Failed to initialize JWT secret: %v", err)
 	}
 
+	if err = services.InitMailer(); err != nil {
+		log.Fatalf("Failed to initialize mailer: %v", err)
+	}
+
 	if err = scheduler.Initialize(); err != nil {
 		log.Fatalf("Failed to initialize scheduler: %v", err)
 	}
 
+	escalation.Initialize()
+	outbox.Initialize()
+
 	r := router.NewRouter()
 
 	scheduler.SetBroadcastCallback(func(projectID string) {
@@ -70,4 +80,6 @@ func main() {
 	<-quit
 	log.Println("Shutting down server...")
 	scheduler.Shutdown()
+	escalation.Shutdown()
+	outbox.Shutdown()
 }
@@ -8,9 +8,17 @@ import (
 
 var DB *gorm.DB
 
+// DSN is the connection string used by DB, kept for dedicated listener connections
+var DSN string
+
+// MonitorChangesChannel is the NOTIFY channel that carries monitor changes
+const MonitorChangesChannel = "monocle_monitors"
+
 func ConnectDatabase(dsn string) error {
 	var err error
 
+	DSN = dsn
+
 	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
 
 	if err != nil {
@@ -24,13 +32,103 @@ func MigrateDatabase() error {
 	models := []interface{}{
 		&models.User{},
 		&models.Project{},
+		&models.EscalationPolicy{},
+		&models.OnCallSchedule{},
+		&models.OnCallOverride{},
 		&models.ProjectMembership{},
+		&models.AlertSource{},
 		&models.Monitor{},
 		&models.MonitorCheck{},
 		&models.Incident{},
-		&models.Notification{},
 		&models.NotificationRule{},
+		&models.Notification{},
+		&models.Agent{},
+		&models.MonitorDependency{},
+		&models.IncidentEvent{},
+		&models.Postmortem{},
+		&models.PostmortemActionItem{},
+	}
+
+	if err := backfillProjectColumns(); err != nil {
+		return err
+	}
+
+	if err := DB.AutoMigrate(models...); err != nil {
+		return err
+	}
+
+	return installMonitorNotifyTrigger()
+}
+
+// backfillProjectColumns adds the project column to existing tables that
+// gained one and fills it in, so AutoMigrate does not have to add a NOT NULL
+// column to a table with rows
+func backfillProjectColumns() error {
+	backfills := []struct {
+		model interface{}
+		fill  string
+	}{
+		{&models.Incident{}, `UPDATE incidents SET project_id = monitors.project_id FROM monitors WHERE monitors.id = incidents.monitor_id`},
+		{&models.Notification{}, `UPDATE notifications SET project_id = incidents.project_id FROM incidents WHERE incidents.id = notifications.incident_id`},
 	}
 
-	return DB.AutoMigrate(models...)
+	for _, backfill := range backfills {
+		if !DB.Migrator().HasTable(backfill.model) || DB.Migrator().HasColumn(backfill.model, "ProjectID") {
+			continue
+		}
+
+		stmt := &gorm.Statement{DB: DB}
+		if err := stmt.Parse(backfill.model); err != nil {
+			return err
+		}
+		table := stmt.Schema.Table
+
+		if err := DB.Transaction(func(tx *gorm.DB) error {
+			statements := []string{
+				`ALTER TABLE ` + table + ` ADD COLUMN project_id bigint`,
+				backfill.fill,
+				`ALTER TABLE ` + table + ` ALTER COLUMN project_id SET NOT NULL`,
+			}
+
+			for _, statement := range statements {
+				if err := tx.Exec(statement).Error; err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// installMonitorNotifyTrigger publishes every insert, update and delete on the
+// monitors table, including edits made outside Monocle, to MonitorChangesChannel
+func installMonitorNotifyTrigger() error {
+	statements := []string{
+		`CREATE OR REPLACE FUNCTION monocle_notify_monitor_change() RETURNS trigger AS $$
+		BEGIN
+			PERFORM pg_notify('` + MonitorChangesChannel + `', json_build_object(
+				'op', TG_OP,
+				'id', CASE WHEN TG_OP = 'DELETE' THEN OLD.id ELSE NEW.id END
+			)::text);
+			RETURN NULL;
+		END;
+		$$ LANGUAGE plpgsql`,
+		`DROP TRIGGER IF EXISTS monitors_notify_change ON monitors`,
+		`CREATE TRIGGER monitors_notify_change
+			AFTER INSERT OR UPDATE OR DELETE ON monitors
+			FOR EACH ROW EXECUTE FUNCTION monocle_notify_monitor_change()`,
+	}
+
+	for _, statement := range statements {
+		if err := DB.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
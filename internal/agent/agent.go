@@ -0,0 +1,207 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/monocle-dev/monocle/internal/monitors"
+	"github.com/monocle-dev/monocle/internal/types"
+)
+
+// Agent pulls its assigned monitors from a Monocle server, runs them with the
+// same checkers as the server and pushes the results back
+type Agent struct {
+	serverURL    string
+	token        string
+	syncInterval time.Duration
+	client       *http.Client
+
+	mu   sync.Mutex
+	jobs map[uint]*agentJob // monitor ID -> running job
+}
+
+type agentJob struct {
+	monitor types.AgentMonitor
+	cancel  context.CancelFunc
+}
+
+// New creates an agent for the given server URL and agent token
+func New(serverURL, token string, syncInterval time.Duration) *Agent {
+	return &Agent{
+		serverURL:    strings.TrimSuffix(serverURL, "/"),
+		token:        token,
+		syncInterval: syncInterval,
+		client:       &http.Client{Timeout: 30 * time.Second},
+		jobs:         make(map[uint]*agentJob),
+	}
+}
+
+// Run syncs the assigned monitors until the context is cancelled
+func (a *Agent) Run(ctx context.Context) error {
+	if err := a.sync(ctx); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(a.syncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			a.stopAll()
+			return nil
+		case <-ticker.C:
+			if err := a.sync(ctx); err != nil {
+				log.Printf("Failed to sync monitors: %v", err)
+			}
+		}
+	}
+}
+
+// sync starts, restarts and stops jobs to match the server's assignment
+func (a *Agent) sync(ctx context.Context) error {
+	var assigned []types.AgentMonitor
+
+	if err := a.do(ctx, http.MethodGet, "/api/agent/monitors", nil, &assigned); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	seen := make(map[uint]bool, len(assigned))
+
+	for _, monitor := range assigned {
+		seen[monitor.ID] = true
+
+		if job, exists := a.jobs[monitor.ID]; exists {
+			if job.monitor.UpdatedAt.Equal(monitor.UpdatedAt) {
+				continue
+			}
+			job.cancel()
+		}
+
+		jobCtx, cancel := context.WithCancel(ctx)
+		a.jobs[monitor.ID] = &agentJob{monitor: monitor, cancel: cancel}
+		go a.runMonitor(jobCtx, monitor)
+	}
+
+	for monitorID, job := range a.jobs {
+		if !seen[monitorID] {
+			job.cancel()
+			delete(a.jobs, monitorID)
+			log.Printf("Stopped monitor %d", monitorID)
+		}
+	}
+
+	log.Printf("Synced %d monitors", len(assigned))
+	return nil
+}
+
+// stopAll cancels every running job
+func (a *Agent) stopAll() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for monitorID, job := range a.jobs {
+		job.cancel()
+		delete(a.jobs, monitorID)
+	}
+}
+
+// runMonitor checks a monitor on its interval until the context is cancelled
+func (a *Agent) runMonitor(ctx context.Context, monitor types.AgentMonitor) {
+	checker, err := monitors.NewChecker(monitor.Type, monitor.Config)
+	if err != nil {
+		log.Printf("Cannot run monitor %d: %v", monitor.ID, err)
+		return
+	}
+
+	interval := time.Duration(monitor.Interval) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+		metrics, checkErr := checker(ctx)
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		result := types.AgentResult{
+			MonitorID:    monitor.ID,
+			Status:       "success",
+			ResponseTime: int(time.Since(start).Milliseconds()),
+			Metrics:      metrics,
+			CheckedAt:    start,
+		}
+
+		if checkErr != nil {
+			result.Status = "failure"
+			result.Message = checkErr.Error()
+		}
+
+		if err := a.pushResults(ctx, []types.AgentResult{result}); err != nil {
+			log.Printf("Failed to push result for monitor %d: %v", monitor.ID, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pushResults sends check results to the server
+func (a *Agent) pushResults(ctx context.Context, results []types.AgentResult) error {
+	return a.do(ctx, http.MethodPost, "/api/agent/results", types.AgentResultsRequest{Results: results}, nil)
+}
+
+// do sends an authenticated JSON request to the server
+func (a *Agent) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var payload bytes.Buffer
+
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, a.serverURL+path, &payload)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("server returned status %d", resp.StatusCode)
+	}
+
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+
+	return nil
+}
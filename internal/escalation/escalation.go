@@ -0,0 +1,257 @@
+package escalation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/monocle-dev/monocle/db"
+	"github.com/monocle-dev/monocle/internal/models"
+	"github.com/monocle-dev/monocle/internal/services"
+	"github.com/monocle-dev/monocle/internal/types"
+	"github.com/monocle-dev/monocle/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultInterval = 15 // Seconds between scans for due escalations and reminders
+	batchSize       = 50 // Incidents escalated or reminded about per scan at most
+
+	// Quiet incidents are not paged but may be escalated in severity later
+	quietRetry = time.Minute
+)
+
+// Worker pages the levels of escalation policies and sends reminders for
+// unacknowledged incidents. Its state lives on the incidents, so escalations
+// carry on after a restart and several replicas can run workers side by side.
+type Worker struct {
+	interval time.Duration
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func NewWorker() *Worker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Worker{
+		interval: time.Duration(utils.GetEnvInt("ESCALATION_INTERVAL", defaultInterval)) * time.Second,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Start runs the worker in the background
+func (w *Worker) Start() {
+	log.Println("Starting escalation worker...")
+
+	w.wg.Add(1)
+	go w.run()
+}
+
+// Stop waits for the current scan to finish and stops the worker
+func (w *Worker) Stop() {
+	w.cancel()
+	w.wg.Wait()
+	log.Println("Escalation worker stopped")
+}
+
+func (w *Worker) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+			w.escalateDue()
+			w.remindDue()
+		}
+	}
+}
+
+// escalateDue pages every incident whose next escalation level is due
+func (w *Worker) escalateDue() {
+	for i := 0; i < batchSize; i++ {
+		if w.ctx.Err() != nil {
+			return
+		}
+
+		found, err := claimNext(time.Now())
+		if err != nil {
+			log.Printf("Failed to escalate incident: %v", err)
+			return
+		}
+
+		if !found {
+			return
+		}
+	}
+}
+
+// claimNext moves the next due incident to its following level and queues the
+// page in the notification outbox. Rows locked by another replica are skipped.
+func claimNext(now time.Time) (bool, error) {
+	found := false
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var incident models.Incident
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND escalation_policy_id IS NOT NULL AND next_escalation_at <= ?", models.IncidentStatusActive, now).
+			Order("next_escalation_at").
+			First(&incident).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		found = true
+
+		var policy models.EscalationPolicy
+
+		if err := tx.First(&policy, *incident.EscalationPolicyID).Error; err != nil {
+			return fmt.Errorf("failed to load escalation policy %d: %w", *incident.EscalationPolicyID, err)
+		}
+
+		var levels []types.EscalationLevel
+
+		if err := json.Unmarshal(policy.Levels, &levels); err != nil {
+			log.Printf("Invalid levels in escalation policy %d, stopping escalation of incident %d: %v", policy.ID, incident.ID, err)
+			levels = nil
+		}
+
+		if incident.EscalationLevel >= len(levels) {
+			return tx.Model(&incident).Update("next_escalation_at", nil).Error
+		}
+
+		var project models.Project
+
+		if err := tx.First(&project, incident.ProjectID).Error; err != nil {
+			return fmt.Errorf("failed to load project %d: %w", incident.ProjectID, err)
+		}
+
+		if incident.MonitorID != nil {
+			if err := tx.First(&incident.Monitor, *incident.MonitorID).Error; err != nil {
+				return fmt.Errorf("failed to load monitor %d: %w", *incident.MonitorID, err)
+			}
+		}
+
+		if !services.ShouldNotify(project, incident.Severity) {
+			return tx.Model(&incident).Update("next_escalation_at", now.Add(quietRetry)).Error
+		}
+
+		level := levels[incident.EscalationLevel]
+
+		page, err := levelPage(tx, policy, incident.EscalationLevel, len(levels), level)
+		if err != nil {
+			return err
+		}
+
+		var next *time.Time
+		if incident.EscalationLevel+1 < len(levels) {
+			due := now.Add(time.Duration(level.TimeoutMinutes) * time.Minute)
+			next = &due
+		}
+
+		if err := tx.Model(&incident).Updates(map[string]interface{}{
+			"escalation_level":   incident.EscalationLevel + 1,
+			"next_escalation_at": next,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to advance escalation: %w", err)
+		}
+
+		message := fmt.Sprintf("Paged level %d of %s: %s", page.Level, policy.Name, describeTargets(page))
+		if err := services.RecordIncidentEvent(tx, incident.ID, models.IncidentEventEscalated, nil, message); err != nil {
+			return err
+		}
+
+		return services.QueueEscalationPage(tx, project, incident, page)
+	})
+
+	return found, err
+}
+
+// levelPage resolves the targets of a policy level to users and channels
+func levelPage(tx *gorm.DB, policy models.EscalationPolicy, index, count int, level types.EscalationLevel) (services.EscalationPage, error) {
+	page := services.EscalationPage{
+		Policy: policy.Name,
+		Level:  index + 1,
+		Levels: count,
+	}
+
+	var userIDs []uint
+
+	for _, target := range level.Targets {
+		switch target.Type {
+		case types.EscalationTargetUser:
+			userIDs = append(userIDs, target.UserID)
+		case types.EscalationTargetChannel:
+			page.Channels = append(page.Channels, target.Channel)
+		case types.EscalationTargetSchedule:
+			userID, onCall, err := services.OnCallUser(tx, target.ScheduleID, time.Now())
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					log.Printf("Escalation policy %d targets missing schedule %d", policy.ID, target.ScheduleID)
+					continue
+				}
+				return page, fmt.Errorf("failed to resolve on-call user of schedule %d: %w", target.ScheduleID, err)
+			}
+			if onCall {
+				userIDs = append(userIDs, userID)
+			}
+		}
+	}
+
+	if len(userIDs) > 0 {
+		if err := tx.Where("id IN ?", userIDs).Order("id").Find(&page.Users).Error; err != nil {
+			return page, fmt.Errorf("failed to load paged users: %w", err)
+		}
+	}
+
+	return page, nil
+}
+
+// describeTargets summarises a page for the incident timeline
+func describeTargets(page services.EscalationPage) string {
+	who := "everyone"
+	if len(page.Users) > 0 {
+		names := make([]string, 0, len(page.Users))
+		for _, user := range page.Users {
+			names = append(names, user.Name)
+		}
+		who = strings.Join(names, ", ")
+	}
+
+	where := "all channels"
+	if len(page.Channels) > 0 {
+		where = strings.Join(page.Channels, ", ")
+	}
+
+	return who + " via " + where
+}
+
+// Global escalation worker instance
+var globalWorker *Worker
+
+// Initialize creates and starts the global escalation worker
+func Initialize() {
+	globalWorker = NewWorker()
+	globalWorker.Start()
+}
+
+// Shutdown stops the global escalation worker
+func Shutdown() {
+	if globalWorker != nil {
+		globalWorker.Stop()
+	}
+}
@@ -0,0 +1,103 @@
+package escalation
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/monocle-dev/monocle/db"
+	"github.com/monocle-dev/monocle/internal/models"
+	"github.com/monocle-dev/monocle/internal/services"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// remindDue sends the reminders of every incident that is due for one
+func (w *Worker) remindDue() {
+	for i := 0; i < batchSize; i++ {
+		if w.ctx.Err() != nil {
+			return
+		}
+
+		found, err := claimNextReminder(time.Now())
+		if err != nil {
+			log.Printf("Failed to send incident reminder: %v", err)
+			return
+		}
+
+		if !found {
+			return
+		}
+	}
+}
+
+// claimNextReminder counts the next due reminder, queues it in the
+// notification outbox and schedules the one after it
+func claimNextReminder(now time.Time) (bool, error) {
+	found := false
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var incident models.Incident
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_reminder_at <= ?", models.IncidentStatusActive, now).
+			Order("next_reminder_at").
+			First(&incident).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		found = true
+
+		var project models.Project
+
+		if err := tx.First(&project, incident.ProjectID).Error; err != nil {
+			return fmt.Errorf("failed to load project %d: %w", incident.ProjectID, err)
+		}
+
+		if incident.MonitorID != nil {
+			if err := tx.First(&incident.Monitor, *incident.MonitorID).Error; err != nil {
+				return fmt.Errorf("failed to load monitor %d: %w", *incident.MonitorID, err)
+			}
+		}
+
+		// Quiet incidents are not reminded about but keep their place in case their severity rises
+		if !services.ShouldNotify(project, incident.Severity) {
+			return tx.Model(&incident).Update("next_reminder_at", services.NextReminderAt(project, incident.RemindersSent, now)).Error
+		}
+
+		sent := incident.RemindersSent + 1
+
+		if err := tx.Model(&incident).Updates(map[string]interface{}{
+			"reminders_sent":   sent,
+			"next_reminder_at": services.NextReminderAt(project, sent, now),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to schedule next reminder: %w", err)
+		}
+
+		var latest models.MonitorCheck
+
+		if incident.MonitorID != nil {
+			if err := tx.Where("monitor_id = ? AND status <> ?", *incident.MonitorID, "success").
+				Order("checked_at DESC").
+				First(&latest).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("failed to load latest failed check: %w", err)
+			}
+		}
+
+		if err := services.RecordIncidentEvent(tx, incident.ID, models.IncidentEventReminder, nil, fmt.Sprintf("Sent reminder %d", sent)); err != nil {
+			return err
+		}
+
+		return services.QueueIncidentReminderNotification(tx, project, incident, services.IncidentReminder{
+			Count:       sent,
+			MaxCount:    project.ReminderMaxCount,
+			LatestError: latest.Message,
+		})
+	})
+
+	return found, err
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/monocle-dev/monocle/db"
+	"github.com/monocle-dev/monocle/internal/auth"
+	"github.com/monocle-dev/monocle/internal/models"
+	"github.com/monocle-dev/monocle/internal/scheduler"
+	"github.com/monocle-dev/monocle/internal/types"
+	"github.com/monocle-dev/monocle/internal/utils"
+	"gorm.io/gorm"
+)
+
+type CreateAgentRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Location string `json:"location" binding:"required"`
+}
+
+func CreateAgent(ctx *gin.Context) {
+	var req CreateAgentRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	projectID, err := utils.GetProjectID(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := utils.GetCurrentUserID(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var project models.Project
+
+	if err := db.DB.Where("id = ? AND owner_id = ?", projectID, userID).First(&project).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve project"})
+		}
+		return
+	}
+
+	location := strings.TrimSpace(req.Location)
+
+	if location == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Location is required"})
+		return
+	}
+
+	if location == types.LocalLocation {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Location '" + location + "' is reserved for the server"})
+		return
+	}
+
+	token, tokenHash, err := auth.GenerateToken("mag_")
+
+	if err != nil {
+		log.Printf("Failed to generate agent token: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	agent := models.Agent{
+		ProjectID: project.ID,
+		Name:      strings.TrimSpace(req.Name),
+		Location:  location,
+		TokenHash: tokenHash,
+	}
+
+	if err := db.DB.Create(&agent).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create agent"})
+		return
+	}
+
+	// The token is only shown once, only its hash is stored
+	ctx.JSON(http.StatusCreated, gin.H{"agent": agent, "token": token})
+}
+
+func ListAgents(ctx *gin.Context) {
+	projectID, err := utils.GetProjectID(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := utils.GetCurrentUserID(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var project models.Project
+
+	if err := db.DB.Where("id = ? AND owner_id = ?", projectID, userID).First(&project).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+
+	agents := []models.Agent{}
+
+	if err := db.DB.Where("project_id = ?", projectID).Order("name").Find(&agents).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve agents"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, agents)
+}
+
+func DeleteAgent(ctx *gin.Context) {
+	projectID, err := utils.GetProjectID(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	agentID, err := strconv.ParseUint(ctx.Param("agent_id"), 10, 64)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Agent ID"})
+		return
+	}
+
+	userID, err := utils.GetCurrentUserID(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var agent models.Agent
+
+	if err := db.DB.Joins("JOIN projects ON projects.id = agents.project_id").
+		Where("agents.id = ? AND agents.project_id = ? AND projects.owner_id = ?", agentID, projectID, userID).
+		First(&agent).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Agent not found"})
+		return
+	}
+
+	if err := db.DB.Delete(&agent).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete agent"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// AgentMonitors returns the monitors assigned to the calling agent's location
+func AgentMonitors(ctx *gin.Context) {
+	agent, err := utils.GetCurrentAgent(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var monitors []models.Monitor
+
+	if err := db.DB.Where("project_id = ? AND status = ? AND ? = ANY(locations)", agent.ProjectID, "active", agent.Location).
+		Find(&monitors).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve monitors"})
+		return
+	}
+
+	response := make([]types.AgentMonitor, 0, len(monitors))
+
+	for _, monitor := range monitors {
+		response = append(response, types.AgentMonitor{
+			ID:        monitor.ID,
+			Name:      monitor.Name,
+			Type:      monitor.Type,
+			Interval:  monitor.Interval,
+			Config:    []byte(monitor.Config),
+			UpdatedAt: monitor.UpdatedAt,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// AgentResults stores check results pushed by the calling agent
+func AgentResults(ctx *gin.Context) {
+	agent, err := utils.GetCurrentAgent(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req types.AgentResultsRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accepted, rejected := 0, 0
+
+	for _, result := range req.Results {
+		var monitor models.Monitor
+
+		if err := db.DB.Where("id = ? AND project_id = ? AND status = ? AND ? = ANY(locations)", result.MonitorID, agent.ProjectID, "active", agent.Location).
+			First(&monitor).Error; err != nil {
+			rejected++
+			continue
+		}
+
+		var checkErr error
+		if result.Status == "failure" {
+			message := result.Message
+			if message == "" {
+				message = "check failed"
+			}
+			checkErr = errors.New(message)
+		}
+
+		responseTime := time.Duration(result.ResponseTime) * time.Millisecond
+
+		if _, err := scheduler.RecordResult(monitor, agent.Location, checkErr, responseTime, result.Metrics); err != nil {
+			log.Printf("Failed to record result from agent %d for monitor %d: %v", agent.ID, monitor.ID, err)
+			rejected++
+			continue
+		}
+
+		accepted++
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"accepted": accepted, "rejected": rejected})
+}
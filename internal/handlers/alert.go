@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/monocle-dev/monocle/db"
+	"github.com/monocle-dev/monocle/internal/auth"
+	"github.com/monocle-dev/monocle/internal/models"
+	"github.com/monocle-dev/monocle/internal/services"
+	"github.com/monocle-dev/monocle/internal/types"
+	"github.com/monocle-dev/monocle/internal/utils"
+	"gorm.io/gorm"
+)
+
+type CreateAlertSourceRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Severity string `json:"severity" binding:"omitempty,oneof=low medium high critical"`
+}
+
+// AlertResultResponse is what happened to one received alert
+type AlertResultResponse struct {
+	DedupKey   string `json:"dedup_key"`
+	Outcome    string `json:"outcome"` // "opened", "deduplicated", "resolved" or "ignored"
+	IncidentID *uint  `json:"incident_id"`
+}
+
+func CreateAlertSource(ctx *gin.Context) {
+	var req CreateAlertSourceRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, ok := loadOwnedProject(ctx)
+
+	if !ok {
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+
+	if name == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Name must not be empty"})
+		return
+	}
+
+	severity := req.Severity
+	if severity == "" {
+		severity = models.SeverityMedium
+	}
+
+	token, tokenHash, err := auth.GenerateToken("mal_")
+
+	if err != nil {
+		log.Printf("Failed to generate alert source token: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	source := models.AlertSource{
+		ProjectID: project.ID,
+		Name:      name,
+		Severity:  severity,
+		TokenHash: tokenHash,
+	}
+
+	if err := db.DB.Create(&source).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create alert source"})
+		return
+	}
+
+	// The token is only shown once, only its hash is stored
+	ctx.JSON(http.StatusCreated, gin.H{"alert_source": source, "token": token})
+}
+
+func ListAlertSources(ctx *gin.Context) {
+	project, ok := loadOwnedProject(ctx)
+
+	if !ok {
+		return
+	}
+
+	sources := []models.AlertSource{}
+
+	if err := db.DB.Where("project_id = ?", project.ID).Order("name").Find(&sources).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve alert sources"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sources)
+}
+
+// DeleteAlertSource revokes the token of an alert source. Its incidents are kept.
+func DeleteAlertSource(ctx *gin.Context) {
+	project, ok := loadOwnedProject(ctx)
+
+	if !ok {
+		return
+	}
+
+	sourceID, err := strconv.ParseUint(ctx.Param("source_id"), 10, 64)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Alert Source ID"})
+		return
+	}
+
+	result := db.DB.Where("id = ? AND project_id = ?", sourceID, project.ID).Delete(&models.AlertSource{})
+
+	if result.Error != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete alert source"})
+		return
+	}
+
+	if result.RowsAffected == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Alert source not found"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ReceiveAlert opens or resolves an incident from an alert in Monocle's own format
+func ReceiveAlert(ctx *gin.Context) {
+	source, err := utils.GetCurrentAlertSource(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req types.InboundAlertRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	alert := services.InboundAlert{
+		DedupKey:    strings.TrimSpace(req.DedupKey),
+		Resolved:    req.Action == "resolve",
+		Title:       strings.TrimSpace(req.Title),
+		Description: req.Description,
+		StartedAt:   req.StartedAt,
+	}
+
+	if alert.DedupKey == "" {
+		alert.DedupKey = alert.Title
+	}
+
+	if alert.DedupKey == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Either dedup_key or title is required"})
+		return
+	}
+
+	if alert.Title == "" {
+		alert.Title = alert.DedupKey
+	}
+
+	if req.Severity != "" {
+		alert.Severity = services.AlertSeverity(req.Severity)
+		if alert.Severity == "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Unknown severity '" + req.Severity + "'"})
+			return
+		}
+	}
+
+	results, err := ingestAlerts(source, []services.InboundAlert{alert})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process alert"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, results[0])
+}
+
+// ReceiveAlertmanagerAlerts opens and resolves incidents from a Prometheus Alertmanager webhook
+func ReceiveAlertmanagerAlerts(ctx *gin.Context) {
+	source, err := utils.GetCurrentAlertSource(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var payload types.AlertmanagerWebhook
+
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := ingestAlerts(source, services.AlertmanagerAlerts(payload))
+
+	if err != nil {
+		// Alertmanager retries failed deliveries, alerts stored so far are deduplicated then
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process alerts"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"results": results})
+}
+
+// ingestAlerts stores each alert in its own transaction together with the
+// notifications of the incident it opened or resolved
+func ingestAlerts(source models.AlertSource, alerts []services.InboundAlert) ([]AlertResultResponse, error) {
+	var project models.Project
+
+	if err := db.DB.First(&project, source.ProjectID).Error; err != nil {
+		log.Printf("Failed to load project of alert source %d: %v", source.ID, err)
+		return nil, err
+	}
+
+	results := make([]AlertResultResponse, 0, len(alerts))
+	changed := false
+
+	defer func() {
+		if changed {
+			BroadCastRefresh(strconv.FormatUint(uint64(project.ID), 10))
+		}
+	}()
+
+	for _, alert := range alerts {
+		var outcome string
+		var incident models.Incident
+
+		err := db.DB.Transaction(func(tx *gorm.DB) error {
+			var err error
+			outcome, incident, err = services.IngestAlert(tx, source, alert, time.Now())
+			if err != nil {
+				return err
+			}
+
+			switch outcome {
+			case services.AlertOpened:
+				return services.QueueIncidentCreatedNotification(tx, project, incident)
+			case services.AlertResolved:
+				return services.QueueIncidentResolvedNotification(tx, project, incident)
+			}
+			return nil
+		})
+
+		if err != nil {
+			log.Printf("Failed to ingest alert %q from alert source %d: %v", alert.DedupKey, source.ID, err)
+			return nil, err
+		}
+
+		result := AlertResultResponse{DedupKey: alert.DedupKey, Outcome: outcome}
+
+		if outcome != services.AlertIgnored {
+			result.IncidentID = &incident.ID
+		}
+
+		if outcome == services.AlertOpened || outcome == services.AlertResolved {
+			changed = true
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
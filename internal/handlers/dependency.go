@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/monocle-dev/monocle/db"
+	"github.com/monocle-dev/monocle/internal/models"
+	"github.com/monocle-dev/monocle/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DependencyGraph struct {
+	Nodes []DependencyNode `json:"nodes"`
+	Edges []DependencyEdge `json:"edges"`
+}
+
+type DependencyNode struct {
+	ID         uint   `json:"id"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Status     string `json:"status"`
+	State      string `json:"state"` // "up", "down", "degraded" or "impacted"
+	IncidentID *uint  `json:"incident_id"`
+}
+
+// DependencyEdge points from a monitor to the parent it depends on
+type DependencyEdge struct {
+	MonitorID uint `json:"monitor_id"`
+	ParentID  uint `json:"parent_id"`
+}
+
+// GetMonitorGraph returns the monitors of a project with their dependencies
+// and the state of their open incidents
+func GetMonitorGraph(ctx *gin.Context) {
+	projectID, err := utils.GetProjectID(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := utils.GetCurrentUserID(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var project models.Project
+
+	if err := db.DB.Where("id = ? AND owner_id = ?", projectID, userID).First(&project).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+
+	var monitors []models.Monitor
+
+	if err := db.DB.Where("project_id = ?", projectID).Order("id").Find(&monitors).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve monitors"})
+		return
+	}
+
+	edges, err := projectDependencies(db.DB, project.ID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve dependencies"})
+		return
+	}
+
+	var incidents []models.Incident
+
+	if err := db.DB.Where("project_id = ? AND monitor_id IS NOT NULL AND status IN ?", projectID, models.OpenIncidentStatuses).
+		Find(&incidents).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve incidents"})
+		return
+	}
+
+	openIncidents := make(map[uint]models.Incident, len(incidents))
+	for _, incident := range incidents {
+		openIncidents[*incident.MonitorID] = incident
+	}
+
+	graph := DependencyGraph{
+		Nodes: make([]DependencyNode, 0, len(monitors)),
+		Edges: make([]DependencyEdge, 0, len(edges)),
+	}
+
+	for _, monitor := range monitors {
+		node := DependencyNode{
+			ID:     monitor.ID,
+			Name:   monitor.Name,
+			Type:   monitor.Type,
+			Status: monitor.Status,
+			State:  "up",
+		}
+
+		if incident, exists := openIncidents[monitor.ID]; exists {
+			incidentID := incident.ID
+			node.IncidentID = &incidentID
+
+			switch {
+			case incident.Status == models.IncidentStatusImpacted:
+				node.State = "impacted"
+			case incident.Kind == models.IncidentKindDegraded:
+				node.State = "degraded"
+			default:
+				node.State = "down"
+			}
+		}
+
+		graph.Nodes = append(graph.Nodes, node)
+	}
+
+	for _, edge := range edges {
+		graph.Edges = append(graph.Edges, DependencyEdge{MonitorID: edge.MonitorID, ParentID: edge.ParentID})
+	}
+
+	ctx.JSON(http.StatusOK, graph)
+}
+
+// projectDependencies loads every dependency between monitors of a project
+func projectDependencies(tx *gorm.DB, projectID uint) ([]models.MonitorDependency, error) {
+	var edges []models.MonitorDependency
+
+	err := tx.Joins("JOIN monitors ON monitors.id = monitor_dependencies.monitor_id").
+		Where("monitors.project_id = ?", projectID).
+		Order("monitor_dependencies.monitor_id, monitor_dependencies.parent_id").
+		Find(&edges).Error
+
+	return edges, err
+}
+
+// monitorParents returns the IDs of the monitors a monitor depends on
+func monitorParents(monitorID uint) ([]uint, error) {
+	parents := []uint{}
+
+	err := db.DB.Model(&models.MonitorDependency{}).
+		Where("monitor_id = ?", monitorID).
+		Order("parent_id").
+		Pluck("parent_id", &parents).Error
+
+	return parents, err
+}
+
+// lockProjectMonitors locks the project row until tx ends, so that concurrent
+// changes to the dependencies and groups of its monitors cannot create a cycle
+// together. It must be taken before any of them is read.
+func lockProjectMonitors(tx *gorm.DB, projectID uint) error {
+	// NO KEY UPDATE does not wait for the key share lock inserting a monitor takes
+	return tx.Clauses(clause.Locking{Strength: "NO KEY UPDATE"}).
+		Select("id").
+		First(&models.Project{}, projectID).Error
+}
+
+// validateMonitorDependencies cleans up the parents of a monitor. Parents must
+// belong to the same project and must not depend on the monitor, directly or
+// not. The caller holds lockProjectMonitors.
+func validateMonitorDependencies(tx *gorm.DB, monitor models.Monitor, parentIDs []uint) ([]uint, error) {
+	parents := make([]uint, 0, len(parentIDs))
+	seen := make(map[uint]bool)
+
+	for _, parentID := range parentIDs {
+		if seen[parentID] {
+			continue
+		}
+		if parentID == monitor.ID {
+			return nil, errors.New("A monitor cannot depend on itself")
+		}
+		seen[parentID] = true
+		parents = append(parents, parentID)
+	}
+	sort.Slice(parents, func(i, j int) bool { return parents[i] < parents[j] })
+
+	if len(parents) > 0 {
+		var count int64
+		if err := tx.Model(&models.Monitor{}).
+			Where("id IN ? AND project_id = ?", parents, monitor.ProjectID).
+			Count(&count).Error; err != nil {
+			return nil, err
+		}
+
+		if int(count) != len(parents) {
+			return nil, errors.New("Dependencies must be monitors of the same project")
+		}
+	}
+
+	edges, err := projectDependencies(tx, monitor.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := make(map[uint][]uint)
+	for _, edge := range edges {
+		if edge.MonitorID != monitor.ID {
+			graph[edge.MonitorID] = append(graph[edge.MonitorID], edge.ParentID)
+		}
+	}
+	graph[monitor.ID] = parents
+
+	if path := dependencyCycle(graph, monitor.ID); path != nil {
+		return nil, fmt.Errorf("Dependencies would create a cycle: %v", path)
+	}
+
+	return parents, nil
+}
+
+// saveMonitorDependencies replaces the parents of a monitor
+func saveMonitorDependencies(tx *gorm.DB, monitorID uint, parents []uint) error {
+	if err := tx.Where("monitor_id = ?", monitorID).Delete(&models.MonitorDependency{}).Error; err != nil {
+		return err
+	}
+
+	for _, parentID := range parents {
+		if err := tx.Create(&models.MonitorDependency{MonitorID: monitorID, ParentID: parentID}).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dependencyCycle returns the path of monitor IDs that leads from start back
+// to itself through its parents, or nil when there is no such cycle
+func dependencyCycle(graph map[uint][]uint, start uint) []uint {
+	visited := make(map[uint]bool)
+	var path []uint
+
+	var walk func(id uint) bool
+	walk = func(id uint) bool {
+		path = append(path, id)
+
+		for _, parentID := range graph[id] {
+			if parentID == start {
+				path = append(path, start)
+				return true
+			}
+			if visited[parentID] {
+				continue
+			}
+			visited[parentID] = true
+			if walk(parentID) {
+				return true
+			}
+		}
+
+		path = path[:len(path)-1]
+		return false
+	}
+
+	if walk(start) {
+		return path
+	}
+
+	return nil
+}
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/monocle-dev/monocle/db"
+	"github.com/monocle-dev/monocle/internal/models"
+	"github.com/monocle-dev/monocle/internal/types"
+	"github.com/monocle-dev/monocle/internal/utils"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type EscalationPolicyRequest struct {
+	Name   string                  `json:"name" binding:"required"`
+	Levels []types.EscalationLevel `json:"levels" binding:"required"` // Paged in order until the incident is acknowledged
+}
+
+func CreateEscalationPolicy(ctx *gin.Context) {
+	var req EscalationPolicyRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, ok := loadOwnedProject(ctx)
+
+	if !ok {
+		return
+	}
+
+	levels, err := prepareEscalationLevels(project, req.Levels)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy := models.EscalationPolicy{
+		ProjectID: project.ID,
+		Name:      strings.TrimSpace(req.Name),
+		Levels:    levels,
+	}
+
+	if err := db.DB.Create(&policy).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create escalation policy"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, policy)
+}
+
+func ListEscalationPolicies(ctx *gin.Context) {
+	project, ok := loadOwnedProject(ctx)
+
+	if !ok {
+		return
+	}
+
+	policies := []models.EscalationPolicy{}
+
+	if err := db.DB.Where("project_id = ?", project.ID).Order("name").Find(&policies).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve escalation policies"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, policies)
+}
+
+func GetEscalationPolicy(ctx *gin.Context) {
+	_, policy, ok := loadEscalationPolicy(ctx)
+
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, policy)
+}
+
+func UpdateEscalationPolicy(ctx *gin.Context) {
+	var req EscalationPolicyRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, policy, ok := loadEscalationPolicy(ctx)
+
+	if !ok {
+		return
+	}
+
+	levels, err := prepareEscalationLevels(project, req.Levels)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy.Name = strings.TrimSpace(req.Name)
+	policy.Levels = levels
+
+	// Open incidents keep their current level, so a shorter policy simply ends sooner
+	if err := db.DB.Save(&policy).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update escalation policy"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, policy)
+}
+
+func DeleteEscalationPolicy(ctx *gin.Context) {
+	_, policy, ok := loadEscalationPolicy(ctx)
+
+	if !ok {
+		return
+	}
+
+	// Monitors and incidents drop the policy through their foreign keys, the
+	// project default has no constraint and is cleared here
+	if err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Project{}).
+			Where("escalation_policy_id = ?", policy.ID).
+			Update("escalation_policy_id", nil).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&policy).Error
+	}); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete escalation policy"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// loadOwnedProject loads the project of the request if it belongs to the
+// current user, and responds with an error otherwise
+func loadOwnedProject(ctx *gin.Context) (models.Project, bool) {
+	projectID, err := utils.GetProjectID(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return models.Project{}, false
+	}
+
+	userID, err := utils.GetCurrentUserID(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return models.Project{}, false
+	}
+
+	var project models.Project
+
+	if err := db.DB.Where("id = ? AND owner_id = ?", projectID, userID).First(&project).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve project"})
+		}
+		return models.Project{}, false
+	}
+
+	return project, true
+}
+
+// loadEscalationPolicy loads the escalation policy of the request from a project of the current user
+func loadEscalationPolicy(ctx *gin.Context) (models.Project, models.EscalationPolicy, bool) {
+	var policy models.EscalationPolicy
+
+	policyID, err := strconv.ParseUint(ctx.Param("policy_id"), 10, 64)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Escalation Policy ID"})
+		return models.Project{}, policy, false
+	}
+
+	project, ok := loadOwnedProject(ctx)
+
+	if !ok {
+		return project, policy, false
+	}
+
+	if err := db.DB.Where("id = ? AND project_id = ?", policyID, project.ID).First(&policy).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Escalation policy not found"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve escalation policy"})
+		}
+		return project, policy, false
+	}
+
+	return project, policy, true
+}
+
+// prepareEscalationLevels validates the levels of an escalation policy. Users
+// must own or be members of the project.
+func prepareEscalationLevels(project models.Project, levels []types.EscalationLevel) (datatypes.JSON, error) {
+	if len(levels) == 0 {
+		return nil, errors.New("An escalation policy needs at least one level")
+	}
+
+	members, err := projectUsers(project)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, level := range levels {
+		if level.TimeoutMinutes < 1 {
+			return nil, fmt.Errorf("Level %d needs a timeout of at least one minute", i+1)
+		}
+
+		if len(level.Targets) == 0 {
+			return nil, fmt.Errorf("Level %d needs at least one target", i+1)
+		}
+
+		for _, target := range level.Targets {
+			switch target.Type {
+			case types.EscalationTargetUser:
+				if !members[target.UserID] {
+					return nil, fmt.Errorf("Level %d targets user %d who is not a member of the project", i+1, target.UserID)
+				}
+			case types.EscalationTargetChannel:
+				if target.Channel != "discord" && target.Channel != "slack" {
+					return nil, fmt.Errorf("Level %d targets unknown channel '%s'", i+1, target.Channel)
+				}
+			case types.EscalationTargetSchedule:
+				var count int64
+				if err := db.DB.Model(&models.OnCallSchedule{}).Where("id = ? AND project_id = ?", target.ScheduleID, project.ID).Count(&count).Error; err != nil {
+					return nil, err
+				}
+				if count == 0 {
+					return nil, fmt.Errorf("Level %d targets schedule %d which is not in the project", i+1, target.ScheduleID)
+				}
+			default:
+				return nil, fmt.Errorf("Level %d has a target of unknown type '%s'", i+1, target.Type)
+			}
+		}
+	}
+
+	levelsJSON, err := json.Marshal(levels)
+	if err != nil {
+		return nil, err
+	}
+
+	return datatypes.JSON(levelsJSON), nil
+}
+
+// projectUsers returns the IDs of the owner and the members of a project
+func projectUsers(project models.Project) (map[uint]bool, error) {
+	users := map[uint]bool{project.OwnerID: true}
+
+	var memberIDs []uint
+	if err := db.DB.Model(&models.ProjectMembership{}).Where("project_id = ?", project.ID).Pluck("user_id", &memberIDs).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range memberIDs {
+		users[id] = true
+	}
+
+	return users, nil
+}
+
+// validateEscalationPolicy checks that a policy chosen for a project or monitor belongs to the project
+func validateEscalationPolicy(tx *gorm.DB, projectID uint, policyID *uint) error {
+	if policyID == nil {
+		return nil
+	}
+
+	var count int64
+
+	if err := tx.Model(&models.EscalationPolicy{}).Where("id = ? AND project_id = ?", *policyID, projectID).Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return errors.New("Escalation policy not found in this project")
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/monocle-dev/monocle/internal/models"
+	"github.com/monocle-dev/monocle/internal/types"
+	"gorm.io/gorm"
+)
+
+// validateGroupMembers checks that the members of a group monitor belong to
+// its project and that no group contains itself through nested groups. The
+// caller holds lockProjectMonitors.
+func validateGroupMembers(tx *gorm.DB, monitor models.Monitor) error {
+	if monitor.Type != "group" {
+		return nil
+	}
+
+	if len(monitor.Locations) > 0 {
+		return errors.New("Group monitors are evaluated on the server and cannot have locations")
+	}
+
+	var cfg types.GroupConfig
+	if err := json.Unmarshal(monitor.Config, &cfg); err != nil {
+		return errors.New("Invalid config format")
+	}
+
+	members := make(map[uint]bool, len(cfg.Members))
+	for _, memberID := range cfg.Members {
+		if memberID == monitor.ID {
+			return errors.New("A group cannot contain itself")
+		}
+		members[memberID] = true
+	}
+
+	memberIDs := make([]uint, 0, len(members))
+	for memberID := range members {
+		memberIDs = append(memberIDs, memberID)
+	}
+
+	var count int64
+	if err := tx.Model(&models.Monitor{}).
+		Where("id IN ? AND project_id = ?", memberIDs, monitor.ProjectID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+
+	if int(count) != len(memberIDs) {
+		return errors.New("Group members must be monitors of the same project")
+	}
+
+	var groups []models.Monitor
+	if err := tx.Where("project_id = ? AND type = ? AND id <> ?", monitor.ProjectID, "group", monitor.ID).
+		Find(&groups).Error; err != nil {
+		return err
+	}
+
+	graph := map[uint][]uint{monitor.ID: memberIDs}
+	for _, group := range groups {
+		var groupCfg types.GroupConfig
+		if json.Unmarshal(group.Config, &groupCfg) == nil {
+			graph[group.ID] = groupCfg.Members
+		}
+	}
+
+	if path := dependencyCycle(graph, monitor.ID); path != nil {
+		return fmt.Errorf("Group members would create a cycle: %v", path)
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/monocle-dev/monocle/internal/scheduler"
 )
 
 func HealthCheck(c *gin.Context) {
@@ -12,5 +13,6 @@ func HealthCheck(c *gin.Context) {
 		"status":    "ok",
 		"message":   "Monocle is running",
 		"timestamp": time.Now().Format(time.RFC3339),
+		"scheduler": scheduler.GetStatus(),
 	})
 }
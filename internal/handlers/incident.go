@@ -0,0 +1,731 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/monocle-dev/monocle/db"
+	"github.com/monocle-dev/monocle/internal/models"
+	"github.com/monocle-dev/monocle/internal/services"
+	"github.com/monocle-dev/monocle/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultIncidentsPerPage = 25
+	maxIncidentsPerPage     = 100
+)
+
+type IncidentResponse struct {
+	ID               uint       `json:"id"`
+	ProjectID        uint       `json:"project_id"`
+	MonitorID        *uint      `json:"monitor_id"`
+	MonitorName      string     `json:"monitor_name"`
+	Source           string     `json:"source"`
+	Kind             string     `json:"kind"`
+	Severity         string     `json:"severity"`
+	Status           string     `json:"status"`
+	Title            string     `json:"title"`
+	Description      string     `json:"description"`
+	StartedAt        *time.Time `json:"started_at"`
+	ResolvedAt       *time.Time `json:"resolved_at"`
+	AcknowledgedAt   *time.Time `json:"acknowledged_at"`
+	AcknowledgedByID *uint      `json:"acknowledged_by_id"`
+	ResolvedByID     *uint      `json:"resolved_by_id"`
+	ParentIncidentID *uint      `json:"parent_incident_id"`
+	Duration         string     `json:"duration"`
+
+	EscalationPolicyID *uint      `json:"escalation_policy_id"`
+	EscalationLevel    int        `json:"escalation_level"`
+	NextEscalationAt   *time.Time `json:"next_escalation_at"`
+
+	RemindersSent  int        `json:"reminders_sent"`
+	NextReminderAt *time.Time `json:"next_reminder_at"`
+
+	LinkedMonitorIDs []uint `json:"linked_monitor_ids"`
+	AlertSourceID    *uint  `json:"alert_source_id"`
+	DedupKey         string `json:"dedup_key,omitempty"`
+}
+
+type IncidentEventResponse struct {
+	ID        uint      `json:"id"`
+	Type      string    `json:"type"`
+	UserID    *uint     `json:"user_id"`
+	UserName  string    `json:"user_name,omitempty"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateIncidentRequest struct {
+	Title       string `json:"title" binding:"required"`
+	Description string `json:"description"`
+	Severity    string `json:"severity" binding:"omitempty,oneof=low medium high critical"`
+	MonitorIDs  []uint `json:"monitor_ids"` // Monitors the incident is about, if any
+}
+
+type UpdateIncidentRequest struct {
+	Severity string `json:"severity" binding:"required,oneof=low medium high critical"`
+}
+
+type IncidentCommentRequest struct {
+	Message string `json:"message" binding:"required"`
+}
+
+func ListIncidents(ctx *gin.Context) {
+	projectID, err := utils.GetProjectID(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := utils.GetCurrentUserID(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var project models.Project
+
+	if err := db.DB.Where("id = ? AND owner_id = ?", projectID, userID).First(&project).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+
+	query := db.DB.Model(&models.Incident{}).Where("incidents.project_id = ?", projectID)
+
+	switch status := ctx.Query("status"); status {
+	case "":
+	case "open":
+		query = query.Where("incidents.status IN ?", models.OpenIncidentStatuses)
+	default:
+		query = query.Where("incidents.status = ?", status)
+	}
+
+	if severity := ctx.Query("severity"); severity != "" {
+		query = query.Where("incidents.severity = ?", severity)
+	}
+
+	if monitorIDStr := ctx.Query("monitor_id"); monitorIDStr != "" {
+		monitorID, err := strconv.ParseUint(monitorIDStr, 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid monitor_id"})
+			return
+		}
+		query = query.Where("incidents.monitor_id = ? OR incidents.id IN (?)", monitorID,
+			db.DB.Table("incident_monitors").Select("incident_id").Where("monitor_id = ?", monitorID))
+	}
+
+	if source := ctx.Query("source"); source != "" {
+		query = query.Where("incidents.source = ?", source)
+	}
+
+	if fromStr := ctx.Query("from"); fromStr != "" {
+		from, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from, expected an RFC 3339 time"})
+			return
+		}
+		query = query.Where("incidents.started_at >= ?", from)
+	}
+
+	if toStr := ctx.Query("to"); toStr != "" {
+		to, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to, expected an RFC 3339 time"})
+			return
+		}
+		query = query.Where("incidents.started_at < ?", to)
+	}
+
+	page, perPage, err := parsePagination(ctx, defaultIncidentsPerPage, maxIncidentsPerPage)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+
+	if err := query.Count(&total).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve incidents"})
+		return
+	}
+
+	var incidents []models.Incident
+
+	if err := query.Select("incidents.*").
+		Preload("Monitor").
+		Preload("LinkedMonitors").
+		Order("incidents.started_at DESC, incidents.id DESC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&incidents).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve incidents"})
+		return
+	}
+
+	responses := make([]IncidentResponse, 0, len(incidents))
+	for _, incident := range incidents {
+		responses = append(responses, buildIncidentResponse(incident))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"incidents": responses,
+		"total":     total,
+		"page":      page,
+		"per_page":  perPage,
+	})
+}
+
+// CreateIncident opens an incident by hand, optionally about some of the project's monitors
+func CreateIncident(ctx *gin.Context) {
+	var req CreateIncidentRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, ok := loadOwnedProject(ctx)
+
+	if !ok {
+		return
+	}
+
+	userID, err := utils.GetCurrentUserID(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	title := strings.TrimSpace(req.Title)
+
+	if title == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Title must not be empty"})
+		return
+	}
+
+	incident := models.Incident{
+		ProjectID:          project.ID,
+		Source:             models.IncidentSourceManual,
+		Severity:           req.Severity,
+		SeverityOverridden: req.Severity != "",
+		Title:              title,
+		Description:        req.Description,
+	}
+
+	if incident.Severity == "" {
+		incident.Severity = models.SeverityMedium
+	}
+
+	if len(req.MonitorIDs) > 0 {
+		if err := db.DB.Where("project_id = ? AND id IN ?", project.ID, req.MonitorIDs).
+			Order("id").
+			Find(&incident.LinkedMonitors).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve monitors"})
+			return
+		}
+
+		requested := make(map[uint]bool, len(req.MonitorIDs))
+		for _, id := range req.MonitorIDs {
+			requested[id] = true
+		}
+
+		if len(incident.LinkedMonitors) != len(requested) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Monitors must belong to the project"})
+			return
+		}
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := services.OpenIncident(tx, &incident, &userID, "Opened manually", time.Now()); err != nil {
+			return err
+		}
+		return services.QueueIncidentCreatedNotification(tx, project, incident)
+	})
+
+	if err != nil {
+		log.Printf("Failed to create incident: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create incident"})
+		return
+	}
+
+	BroadCastRefresh(strconv.FormatUint(uint64(project.ID), 10))
+
+	ctx.JSON(http.StatusCreated, buildIncidentResponse(incident))
+}
+
+func GetIncident(ctx *gin.Context) {
+	incident, _, ok := loadProjectIncident(ctx)
+
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, buildIncidentResponse(incident))
+}
+
+// UpdateIncident changes the severity of an incident. Rules no longer
+// escalate an incident once a user has set its severity.
+func UpdateIncident(ctx *gin.Context) {
+	var req UpdateIncidentRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	incident, userID, ok := loadProjectIncident(ctx)
+
+	if !ok {
+		return
+	}
+
+	err := updateIncident(&incident, func(tx *gorm.DB, current *models.Incident) error {
+		previous := current.Severity
+		current.Severity = req.Severity
+		current.SeverityOverridden = true
+
+		if previous == req.Severity {
+			return nil
+		}
+
+		message := "Severity changed from " + previous + " to " + req.Severity
+		return services.RecordIncidentEvent(tx, current.ID, models.IncidentEventSeverity, &userID, message)
+	})
+
+	if !respondIncidentUpdate(ctx, err, "Failed to update incident") {
+		return
+	}
+
+	BroadCastRefresh(strconv.FormatUint(uint64(incident.ProjectID), 10))
+
+	ctx.JSON(http.StatusOK, buildIncidentResponse(incident))
+}
+
+// AcknowledgeIncident records that a user is working on an active incident
+func AcknowledgeIncident(ctx *gin.Context) {
+	incident, userID, ok := loadProjectIncident(ctx)
+
+	if !ok {
+		return
+	}
+
+	err := updateIncident(&incident, func(tx *gorm.DB, current *models.Incident) error {
+		switch current.Status {
+		case models.IncidentStatusActive:
+		case models.IncidentStatusAcknowledged:
+			return incidentConflict{"Incident is already acknowledged"}
+		case models.IncidentStatusImpacted:
+			return incidentConflict{"Impacted incidents follow their root cause incident"}
+		default:
+			return incidentConflict{"Incident is resolved"}
+		}
+
+		now := time.Now()
+		current.Status = models.IncidentStatusAcknowledged
+		current.AcknowledgedAt = &now
+		current.AcknowledgedByID = &userID
+
+		if err := services.RecordIncidentEvent(tx, current.ID, models.IncidentEventAcknowledged, &userID, ""); err != nil {
+			return err
+		}
+
+		return queueIncidentNotification(tx, *current, services.QueueIncidentAcknowledgedNotification)
+	})
+
+	if !respondIncidentUpdate(ctx, err, "Failed to acknowledge incident") {
+		return
+	}
+
+	BroadCastRefresh(strconv.FormatUint(uint64(incident.ProjectID), 10))
+
+	ctx.JSON(http.StatusOK, buildIncidentResponse(incident))
+}
+
+// ResolveIncident closes an open incident by hand. A monitor that is still
+// failing opens a new incident on its next check.
+func ResolveIncident(ctx *gin.Context) {
+	incident, userID, ok := loadProjectIncident(ctx)
+
+	if !ok {
+		return
+	}
+
+	err := updateIncident(&incident, func(tx *gorm.DB, current *models.Incident) error {
+		if current.Status == models.IncidentStatusResolved {
+			return incidentConflict{"Incident is already resolved"}
+		}
+
+		wasImpacted := current.Status == models.IncidentStatusImpacted
+
+		now := time.Now()
+		current.Status = models.IncidentStatusResolved
+		current.ResolvedAt = &now
+		current.ResolvedByID = &userID
+
+		if err := services.RecordIncidentEvent(tx, current.ID, models.IncidentEventResolved, &userID, "Resolved manually"); err != nil {
+			return err
+		}
+
+		// Impacted incidents are covered by the notifications of their root cause
+		if wasImpacted {
+			return nil
+		}
+
+		return queueIncidentNotification(tx, *current, services.QueueIncidentResolvedNotification)
+	})
+
+	if !respondIncidentUpdate(ctx, err, "Failed to resolve incident") {
+		return
+	}
+
+	BroadCastRefresh(strconv.FormatUint(uint64(incident.ProjectID), 10))
+
+	ctx.JSON(http.StatusOK, buildIncidentResponse(incident))
+}
+
+// ReopenIncident turns a resolved incident back into an active one
+func ReopenIncident(ctx *gin.Context) {
+	incident, userID, ok := loadProjectIncident(ctx)
+
+	if !ok {
+		return
+	}
+
+	err := updateIncident(&incident, func(tx *gorm.DB, current *models.Incident) error {
+		if current.Status != models.IncidentStatusResolved {
+			return incidentConflict{"Incident is not resolved"}
+		}
+
+		var openCount int64
+
+		// Monitors and alert keys have at most one open incident
+		if current.MonitorID != nil {
+			if err := tx.Model(&models.Incident{}).
+				Where("monitor_id = ? AND status IN ?", *current.MonitorID, models.OpenIncidentStatuses).
+				Count(&openCount).Error; err != nil {
+				return err
+			}
+
+			if openCount > 0 {
+				return incidentConflict{"Monitor already has an open incident"}
+			}
+		}
+
+		if current.AlertSourceID != nil {
+			if err := tx.Model(&models.Incident{}).
+				Where("alert_source_id = ? AND dedup_key = ? AND status IN ?", *current.AlertSourceID, current.DedupKey, models.OpenIncidentStatuses).
+				Count(&openCount).Error; err != nil {
+				return err
+			}
+
+			if openCount > 0 {
+				return incidentConflict{"Alert already has an open incident"}
+			}
+		}
+
+		current.Status = models.IncidentStatusActive
+		current.ResolvedAt = nil
+		current.ResolvedByID = nil
+		current.AcknowledgedAt = nil
+		current.AcknowledgedByID = nil
+		current.ParentIncidentID = nil
+
+		now := time.Now()
+
+		if err := services.StartEscalation(tx, current, now); err != nil {
+			return err
+		}
+
+		if err := services.ScheduleReminders(tx, current, now); err != nil {
+			return err
+		}
+
+		return services.RecordIncidentEvent(tx, current.ID, models.IncidentEventReopened, &userID, "")
+	})
+
+	if !respondIncidentUpdate(ctx, err, "Failed to reopen incident") {
+		return
+	}
+
+	BroadCastRefresh(strconv.FormatUint(uint64(incident.ProjectID), 10))
+
+	ctx.JSON(http.StatusOK, buildIncidentResponse(incident))
+}
+
+// GetIncidentTimeline returns the events of an incident, oldest first
+func GetIncidentTimeline(ctx *gin.Context) {
+	incident, _, ok := loadProjectIncident(ctx)
+
+	if !ok {
+		return
+	}
+
+	var events []models.IncidentEvent
+
+	if err := db.DB.Preload("User").
+		Where("incident_id = ?", incident.ID).
+		Order("created_at, id").
+		Find(&events).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve timeline"})
+		return
+	}
+
+	responses := make([]IncidentEventResponse, 0, len(events))
+	for _, event := range events {
+		responses = append(responses, buildIncidentEventResponse(event))
+	}
+
+	ctx.JSON(http.StatusOK, responses)
+}
+
+func CreateIncidentComment(ctx *gin.Context) {
+	var req IncidentCommentRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	message := strings.TrimSpace(req.Message)
+
+	if message == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Message must not be empty"})
+		return
+	}
+
+	incident, userID, ok := loadProjectIncident(ctx)
+
+	if !ok {
+		return
+	}
+
+	event := models.IncidentEvent{
+		IncidentID: incident.ID,
+		Type:       models.IncidentEventComment,
+		UserID:     &userID,
+		Message:    message,
+	}
+
+	if err := db.DB.Create(&event).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add comment"})
+		return
+	}
+
+	var user models.User
+	if err := db.DB.First(&user, userID).Error; err == nil {
+		event.User = &user
+	}
+
+	ctx.JSON(http.StatusCreated, buildIncidentEventResponse(event))
+}
+
+// loadProjectIncident loads the incident addressed by the request if the
+// current user owns its project, and writes the error response otherwise
+func loadProjectIncident(ctx *gin.Context) (models.Incident, uint, bool) {
+	userID, err := utils.GetCurrentUserID(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return models.Incident{}, 0, false
+	}
+
+	projectID, incidentID, err := utils.GetProjectIncidentID(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return models.Incident{}, 0, false
+	}
+
+	var incident models.Incident
+
+	if err := db.DB.Select("incidents.*").
+		Preload("Monitor").
+		Preload("LinkedMonitors").
+		Joins("JOIN projects ON projects.id = incidents.project_id").
+		Where("incidents.id = ? AND incidents.project_id = ? AND projects.owner_id = ?", incidentID, projectID, userID).
+		First(&incident).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Incident not found"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve incident"})
+		}
+		return models.Incident{}, 0, false
+	}
+
+	return incident, userID, true
+}
+
+// incidentConflict is returned when an incident is not in a state that allows a change
+type incidentConflict struct {
+	message string
+}
+
+func (e incidentConflict) Error() string {
+	return e.message
+}
+
+// updateIncident applies a change to the latest state of an incident while
+// holding the lock on its monitor row, the lock the scheduler takes when it
+// stores results, so the change cannot race an automatic open or resolve.
+// Incidents without a monitor are locked by their own row.
+func updateIncident(incident *models.Incident, change func(tx *gorm.DB, current *models.Incident) error) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if incident.MonitorID != nil {
+			var monitor models.Monitor
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&monitor, *incident.MonitorID).Error; err != nil {
+				return err
+			}
+		}
+
+		var current models.Incident
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, incident.ID).Error; err != nil {
+			return err
+		}
+
+		if err := change(tx, &current); err != nil {
+			return err
+		}
+
+		if err := tx.Omit(clause.Associations).Save(&current).Error; err != nil {
+			return err
+		}
+
+		current.Monitor = incident.Monitor
+		current.LinkedMonitors = incident.LinkedMonitors
+		*incident = current
+		return nil
+	})
+}
+
+// queueIncidentNotification queues a notification about an incident for its project
+func queueIncidentNotification(tx *gorm.DB, incident models.Incident, queue func(*gorm.DB, models.Project, models.Incident) error) error {
+	var project models.Project
+
+	if err := tx.First(&project, incident.ProjectID).Error; err != nil {
+		return err
+	}
+
+	return queue(tx, project, incident)
+}
+
+// respondIncidentUpdate writes the error response for a failed incident update
+// and reports whether the update succeeded
+func respondIncidentUpdate(ctx *gin.Context, err error, failure string) bool {
+	if err == nil {
+		return true
+	}
+
+	var conflict incidentConflict
+	if errors.As(err, &conflict) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": conflict.message})
+	} else {
+		log.Printf("%s: %v", failure, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": failure})
+	}
+
+	return false
+}
+
+// parsePagination reads the page and per_page query parameters
+func parsePagination(ctx *gin.Context, defaultPerPage, maxPerPage int) (int, int, error) {
+	page, perPage := 1, defaultPerPage
+
+	if pageStr := ctx.Query("page"); pageStr != "" {
+		value, err := strconv.Atoi(pageStr)
+		if err != nil || value < 1 {
+			return 0, 0, errors.New("Invalid page")
+		}
+		page = value
+	}
+
+	if perPageStr := ctx.Query("per_page"); perPageStr != "" {
+		value, err := strconv.Atoi(perPageStr)
+		if err != nil || value < 1 || value > maxPerPage {
+			return 0, 0, errors.New("per_page must be between 1 and " + strconv.Itoa(maxPerPage))
+		}
+		perPage = value
+	}
+
+	return page, perPage, nil
+}
+
+func buildIncidentResponse(incident models.Incident) IncidentResponse {
+	response := IncidentResponse{
+		ID:               incident.ID,
+		ProjectID:        incident.ProjectID,
+		MonitorID:        incident.MonitorID,
+		Source:           incident.Source,
+		Kind:             incident.Kind,
+		Severity:         incident.Severity,
+		Status:           incident.Status,
+		Title:            incident.Title,
+		Description:      incident.Description,
+		StartedAt:        incident.StartedAt,
+		ResolvedAt:       incident.ResolvedAt,
+		AcknowledgedAt:   incident.AcknowledgedAt,
+		AcknowledgedByID: incident.AcknowledgedByID,
+		ResolvedByID:     incident.ResolvedByID,
+		ParentIncidentID: incident.ParentIncidentID,
+
+		EscalationPolicyID: incident.EscalationPolicyID,
+		EscalationLevel:    incident.EscalationLevel,
+
+		RemindersSent: incident.RemindersSent,
+
+		LinkedMonitorIDs: make([]uint, 0, len(incident.LinkedMonitors)),
+		AlertSourceID:    incident.AlertSourceID,
+		DedupKey:         incident.DedupKey,
+	}
+
+	if incident.MonitorID != nil {
+		response.MonitorName = incident.Monitor.Name
+	}
+
+	for _, monitor := range incident.LinkedMonitors {
+		response.LinkedMonitorIDs = append(response.LinkedMonitorIDs, monitor.ID)
+	}
+
+	if incident.Status == models.IncidentStatusActive {
+		response.NextEscalationAt = incident.NextEscalationAt
+		response.NextReminderAt = incident.NextReminderAt
+	}
+
+	if incident.StartedAt != nil {
+		end := time.Now()
+		if incident.ResolvedAt != nil {
+			end = *incident.ResolvedAt
+		}
+		response.Duration = formatDuration(end.Sub(*incident.StartedAt))
+	}
+
+	return response
+}
+
+func buildIncidentEventResponse(event models.IncidentEvent) IncidentEventResponse {
+	response := IncidentEventResponse{
+		ID:        event.ID,
+		Type:      event.Type,
+		UserID:    event.UserID,
+		Message:   event.Message,
+		CreatedAt: event.CreatedAt,
+	}
+
+	if event.User != nil {
+		response.UserName = event.User.Name
+	}
+
+	return response
+}
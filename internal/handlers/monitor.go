@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -11,41 +12,109 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
 	"github.com/monocle-dev/monocle/db"
 	"github.com/monocle-dev/monocle/internal/models"
+	"github.com/monocle-dev/monocle/internal/monitors"
 	"github.com/monocle-dev/monocle/internal/scheduler"
+	"github.com/monocle-dev/monocle/internal/types"
 	"github.com/monocle-dev/monocle/internal/utils"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
+// maxCheckDuration bounds checks run synchronously from a request
+const maxCheckDuration = 60 * time.Second
+
 type CreateMonitorRequest struct {
-	Name     string                 `json:"name" binding:"required"`
-	Type     string                 `json:"type" binding:"required"`     // "http", "https", "ssl", "dns", "database"
-	Interval int                    `json:"interval" binding:"required"` // Interval in seconds
-	Config   map[string]interface{} `json:"config" binding:"required"`   // Configuration specific to the monitor type
+	Name      string                 `json:"name" binding:"required"`
+	Type      string                 `json:"type" binding:"required"`     // "http", "https", "ssl", "dns", "database", "group"
+	Interval  int                    `json:"interval" binding:"required"` // Interval in seconds
+	Config    map[string]interface{} `json:"config" binding:"required"`   // Configuration specific to the monitor type
+	Locations []string               `json:"locations"`                   // Locations to check from, empty means the server only
+	Quorum    int                    `json:"quorum"`                      // Failing locations needed to open an incident
+
+	BackoffEnabled     bool `json:"backoff_enabled"`      // Stretch the interval while an incident stays open
+	BackoffMaxInterval int  `json:"backoff_max_interval"` // Upper bound in seconds, defaults to one hour
+
+	WarningThreshold  int                              `json:"warning_threshold"`  // Response time in ms that marks a check degraded
+	CriticalThreshold int                              `json:"critical_threshold"` // Response time in ms that fails a check
+	MetricThresholds  map[string]types.MetricThreshold `json:"metric_thresholds"`  // Limits for other checker metrics
+	DegradedIncidents bool                             `json:"degraded_incidents"` // Open an incident while checks are degraded
+
+	DependsOn []uint `json:"depends_on"` // Parent monitors whose outages explain failures of this one
+
+	DefaultSeverity string               `json:"default_severity"` // Severity of new incidents, defaults to medium
+	SeverityRules   []types.SeverityRule `json:"severity_rules"`   // Rules that escalate open incidents
+
+	EscalationPolicyID *uint `json:"escalation_policy_id"` // Overrides the project's escalation policy
+
+	Tags []string `json:"tags"` // Labels notification rules can filter on
 }
 
 type UpdateMonitorRequest struct {
-	Name     string                 `json:"name" binding:"required"`
-	Type     string                 `json:"type" binding:"required"`
-	Interval int                    `json:"interval" binding:"required"`
-	Config   map[string]interface{} `json:"config" binding:"required"`
+	Name      string                 `json:"name" binding:"required"`
+	Type      string                 `json:"type" binding:"required"`
+	Interval  int                    `json:"interval" binding:"required"`
+	Config    map[string]interface{} `json:"config" binding:"required"`
+	Locations []string               `json:"locations"`
+	Quorum    int                    `json:"quorum"`
+
+	BackoffEnabled     bool `json:"backoff_enabled"`
+	BackoffMaxInterval int  `json:"backoff_max_interval"`
+
+	WarningThreshold  int                              `json:"warning_threshold"`
+	CriticalThreshold int                              `json:"critical_threshold"`
+	MetricThresholds  map[string]types.MetricThreshold `json:"metric_thresholds"`
+	DegradedIncidents bool                             `json:"degraded_incidents"`
+
+	DependsOn []uint `json:"depends_on"`
+
+	DefaultSeverity string               `json:"default_severity"`
+	SeverityRules   []types.SeverityRule `json:"severity_rules"`
+
+	EscalationPolicyID *uint `json:"escalation_policy_id"`
+
+	Tags []string `json:"tags"`
+}
+
+type TestMonitorRequest struct {
+	Type   string                 `json:"type" binding:"required"`
+	Config map[string]interface{} `json:"config" binding:"required"`
+
+	WarningThreshold  int                              `json:"warning_threshold"`
+	CriticalThreshold int                              `json:"critical_threshold"`
+	MetricThresholds  map[string]types.MetricThreshold `json:"metric_thresholds"`
 }
 
 type MonitorSummary struct {
-	ID           uint                   `json:"id"`
-	Name         string                 `json:"name"`
-	Type         string                 `json:"type"`
-	Status       string                 `json:"status"`
-	Interval     int                    `json:"interval"`
-	Config       map[string]interface{} `json:"config"`
-	LastCheck    *MonitorCheckSummary   `json:"last_check"`
-	Uptime       float64                `json:"uptime_percentage"`
-	ResponseTime float64                `json:"avg_response_time"`
+	ID                 uint                   `json:"id"`
+	Name               string                 `json:"name"`
+	Type               string                 `json:"type"`
+	Status             string                 `json:"status"`
+	Interval           int                    `json:"interval"`
+	Config             map[string]interface{} `json:"config"`
+	Locations          []string               `json:"locations"`
+	Quorum             int                    `json:"quorum"`
+	BackoffEnabled     bool                   `json:"backoff_enabled"`
+	EffectiveInterval  int                    `json:"effective_interval"` // Interval in use, longer than interval while backed off
+	WarningThreshold   int                    `json:"warning_threshold"`
+	CriticalThreshold  int                    `json:"critical_threshold"`
+	MetricThresholds   json.RawMessage        `json:"metric_thresholds,omitempty"`
+	DegradedIncidents  bool                   `json:"degraded_incidents"`
+	DependsOn          []uint                 `json:"depends_on"`
+	DefaultSeverity    string                 `json:"default_severity"`
+	SeverityRules      json.RawMessage        `json:"severity_rules,omitempty"`
+	EscalationPolicyID *uint                  `json:"escalation_policy_id"`
+	Tags               []string               `json:"tags"`
+	LastCheck          *MonitorCheckSummary   `json:"last_check"`
+	Uptime             float64                `json:"uptime_percentage"`
+	ResponseTime       float64                `json:"avg_response_time"`
 }
 
 type MonitorCheckSummary struct {
 	ID           uint      `json:"id"`
+	Location     string    `json:"location"`
 	Status       string    `json:"status"`
 	ResponseTime int       `json:"response_time"`
 	Message      string    `json:"message"`
@@ -75,6 +144,8 @@ type MonitorsSummary struct {
 type IncidentSummary struct {
 	ID          uint       `json:"id"`
 	MonitorName string     `json:"monitor_name"`
+	Kind        string     `json:"kind"` // "down" or "degraded"
+	Severity    string     `json:"severity"`
 	Title       string     `json:"title"`
 	Description string     `json:"description"`
 	Status      string     `json:"status"`
@@ -116,38 +187,33 @@ func CreateMonitor(ctx *gin.Context) {
 		return
 	}
 
-	configJSON, err := json.Marshal(req.Config)
+	configJSON, err := prepareMonitorConfig(req.Type, req.Config)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid config format"})
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Validate and clean DNS monitor config
-	if req.Type == "dns" {
-		var dnsConfig map[string]interface{}
-		if err := json.Unmarshal(configJSON, &dnsConfig); err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid DNS config format"})
-			return
-		}
+	locations, quorum, err := prepareMonitorLocations(req.Locations, req.Quorum)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-		// Extract and clean the domain
-		if domainValue, exists := dnsConfig["domain"]; exists {
-			if domainStr, ok := domainValue.(string); ok {
-				cleanDomain, err := utils.ExtractRawDomain(domainStr)
-				if err != nil {
-					ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain: " + err.Error()})
-					return
-				}
-				dnsConfig["domain"] = cleanDomain
+	if req.BackoffMaxInterval != 0 && req.BackoffMaxInterval < req.Interval {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Backoff max interval must not be shorter than the interval"})
+		return
+	}
 
-				// Re-marshal the cleaned config
-				configJSON, err = json.Marshal(dnsConfig)
-				if err != nil {
-					ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to process DNS config"})
-					return
-				}
-			}
-		}
+	metricThresholds, err := prepareMonitorThresholds(req.WarningThreshold, req.CriticalThreshold, req.MetricThresholds)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	defaultSeverity, severityRules, err := prepareSeverityRules(req.DefaultSeverity, req.SeverityRules)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
 	monitor := models.Monitor{
@@ -157,10 +223,57 @@ func CreateMonitor(ctx *gin.Context) {
 		Status:    "active",
 		Interval:  req.Interval,
 		Config:    configJSON,
+		Locations: locations,
+		Quorum:    quorum,
+
+		BackoffEnabled:     req.BackoffEnabled,
+		BackoffMaxInterval: req.BackoffMaxInterval,
+
+		WarningThreshold:  req.WarningThreshold,
+		CriticalThreshold: req.CriticalThreshold,
+		MetricThresholds:  metricThresholds,
+		DegradedIncidents: req.DegradedIncidents,
+
+		DefaultSeverity: defaultSeverity,
+		SeverityRules:   severityRules,
+
+		EscalationPolicyID: req.EscalationPolicyID,
+
+		Tags: prepareMonitorTags(req.Tags),
 	}
 
-	if err := db.DB.Create(&monitor).Error; err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create monitor"})
+	var validationErr error
+
+	if err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockProjectMonitors(tx, monitor.ProjectID); err != nil {
+			return err
+		}
+
+		if validationErr = validateEscalationPolicy(tx, monitor.ProjectID, monitor.EscalationPolicyID); validationErr != nil {
+			return validationErr
+		}
+
+		if err := tx.Create(&monitor).Error; err != nil {
+			return err
+		}
+
+		if validationErr = validateGroupMembers(tx, monitor); validationErr != nil {
+			return validationErr
+		}
+
+		var parents []uint
+		parents, validationErr = validateMonitorDependencies(tx, monitor, req.DependsOn)
+		if validationErr != nil {
+			return validationErr
+		}
+
+		return saveMonitorDependencies(tx, monitor.ID, parents)
+	}); err != nil {
+		if validationErr != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create monitor"})
+		}
 		return
 	}
 
@@ -289,9 +402,15 @@ func GetMonitorChecks(ctx *gin.Context) {
 		return
 	}
 
+	query := db.DB.Select("id, monitor_id, location, status, response_time, message, metrics, checked_at, created_at").
+		Where("monitor_id = ?", monitorID)
+
+	if location := ctx.Query("location"); location != "" {
+		query = query.Where("location = ?", location)
+	}
+
 	var checks []models.MonitorCheck
-	if err := db.DB.Select("id, monitor_id, status, response_time, message, checked_at, created_at").
-		Where("monitor_id = ?", monitorID).
+	if err := query.
 		Order("checked_at DESC").
 		Limit(50).
 		Find(&checks).Error; err != nil {
@@ -336,54 +455,372 @@ func UpdateMonitor(ctx *gin.Context) {
 	monitor.Name = req.Name
 	monitor.Type = req.Type
 	monitor.Interval = req.Interval
-	configJSON, err := json.Marshal(req.Config)
 
+	configJSON, err := prepareMonitorConfig(req.Type, req.Config)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid config format"})
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Validate and clean DNS monitor config
-	if req.Type == "dns" {
-		var dnsConfig map[string]interface{}
-		if err := json.Unmarshal(configJSON, &dnsConfig); err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid DNS config format"})
-			return
+	locations, quorum, err := prepareMonitorLocations(req.Locations, req.Quorum)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.BackoffMaxInterval != 0 && req.BackoffMaxInterval < req.Interval {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Backoff max interval must not be shorter than the interval"})
+		return
+	}
+
+	metricThresholds, err := prepareMonitorThresholds(req.WarningThreshold, req.CriticalThreshold, req.MetricThresholds)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	defaultSeverity, severityRules, err := prepareSeverityRules(req.DefaultSeverity, req.SeverityRules)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	monitor.Config = configJSON
+	monitor.Locations = locations
+	monitor.Quorum = quorum
+	monitor.BackoffEnabled = req.BackoffEnabled
+	monitor.BackoffMaxInterval = req.BackoffMaxInterval
+	monitor.EffectiveInterval = 0
+	monitor.WarningThreshold = req.WarningThreshold
+	monitor.CriticalThreshold = req.CriticalThreshold
+	monitor.MetricThresholds = metricThresholds
+	monitor.DegradedIncidents = req.DegradedIncidents
+	monitor.DefaultSeverity = defaultSeverity
+	monitor.SeverityRules = severityRules
+	monitor.EscalationPolicyID = req.EscalationPolicyID
+	monitor.Tags = prepareMonitorTags(req.Tags)
+
+	var validationErr error
+
+	if err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockProjectMonitors(tx, monitor.ProjectID); err != nil {
+			return err
 		}
 
+		if validationErr = validateEscalationPolicy(tx, monitor.ProjectID, monitor.EscalationPolicyID); validationErr != nil {
+			return validationErr
+		}
+
+		if err := tx.Save(&monitor).Error; err != nil {
+			return err
+		}
+
+		if validationErr = validateGroupMembers(tx, monitor); validationErr != nil {
+			return validationErr
+		}
+
+		var parents []uint
+		parents, validationErr = validateMonitorDependencies(tx, monitor, req.DependsOn)
+		if validationErr != nil {
+			return validationErr
+		}
+
+		return saveMonitorDependencies(tx, monitor.ID, parents)
+	}); err != nil {
+		if validationErr != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update monitor"})
+		}
+		return
+	}
+
+	scheduler.UpdateMonitor(monitor)
+
+	// Broadcast immediate refresh for monitor configuration update
+	BroadCastRefresh(strconv.FormatUint(uint64(projectID), 10))
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Monitor updated successfully", "monitor_id": monitor.ID})
+}
+
+func RunMonitor(ctx *gin.Context) {
+	userID, err := utils.GetCurrentUserID(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	projectID, monitorID, err := utils.GetProjectMonitorID(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var monitor models.Monitor
+
+	if err := db.DB.Joins("JOIN projects ON projects.id = monitors.project_id").
+		Where("monitors.id = ? AND monitors.project_id = ? AND projects.owner_id = ?", monitorID, projectID, userID).
+		First(&monitor).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Monitor not found"})
+		return
+	}
+
+	if monitor.Status != "active" {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Monitor is not active"})
+		return
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx.Request.Context(), maxCheckDuration)
+	defer cancel()
+
+	result, err := scheduler.RunNow(checkCtx, monitor)
+
+	if errors.Is(err, scheduler.ErrRunsRemotely) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Monitor is only checked by agents"})
+		return
+	}
+
+	if err != nil {
+		log.Printf("Failed to run check for monitor %d: %v", monitor.ID, err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run check"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func TestMonitor(ctx *gin.Context) {
+	var req TestMonitorRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	projectID, err := utils.GetProjectID(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := utils.GetCurrentUserID(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var project models.Project
+
+	if err := db.DB.Where("id = ? AND owner_id = ?", projectID, userID).First(&project).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve project"})
+		}
+		return
+	}
+
+	configJSON, err := prepareMonitorConfig(req.Type, req.Config)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Type == "group" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Group monitors are evaluated from their members and cannot be tested"})
+		return
+	}
+
+	checker, err := monitors.NewChecker(req.Type, configJSON)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := prepareMonitorThresholds(req.WarningThreshold, req.CriticalThreshold, req.MetricThresholds); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx.Request.Context(), maxCheckDuration)
+	defer cancel()
+
+	// Run the check once without storing a result or opening an incident
+	start := time.Now()
+	metrics, checkErr := checker(checkCtx)
+	responseTime := time.Since(start)
+
+	if metrics == nil {
+		metrics = monitors.Metrics{}
+	}
+	metrics[monitors.MetricResponseTime] = float64(responseTime.Milliseconds())
+
+	result := types.CheckResult{
+		ResponseTime: int(responseTime.Milliseconds()),
+		Metrics:      metrics,
+		CheckedAt:    start,
+	}
+
+	if checkErr != nil {
+		result.Status = monitors.StatusFailure
+		result.Message = checkErr.Error()
+	} else {
+		thresholds := monitors.Thresholds(req.WarningThreshold, req.CriticalThreshold, req.MetricThresholds)
+		result.Status, result.Message = monitors.EvaluateThresholds(thresholds, metrics)
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// prepareMonitorLocations cleans up the location list of a monitor and checks
+// that the quorum can be reached
+func prepareMonitorLocations(locations []string, quorum int) (pq.StringArray, int, error) {
+	cleaned := pq.StringArray{}
+	seen := make(map[string]bool)
+
+	for _, location := range locations {
+		location = strings.TrimSpace(location)
+		if location == "" || seen[location] {
+			continue
+		}
+		seen[location] = true
+		cleaned = append(cleaned, location)
+	}
+
+	if quorum == 0 {
+		quorum = 1
+	}
+
+	if quorum < 1 || quorum > max(len(cleaned), 1) {
+		return nil, 0, errors.New("Quorum must be between 1 and the number of locations")
+	}
+
+	return cleaned, quorum, nil
+}
+
+// prepareMonitorTags trims, lowercases and deduplicates the tags of a monitor
+func prepareMonitorTags(tags []string) pq.StringArray {
+	cleaned := pq.StringArray{}
+	seen := make(map[string]bool)
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		cleaned = append(cleaned, tag)
+	}
+
+	return cleaned
+}
+
+// prepareMonitorThresholds validates the latency and metric thresholds of a
+// monitor and returns the metric thresholds as JSON
+func prepareMonitorThresholds(warning, critical int, metric map[string]types.MetricThreshold) (datatypes.JSON, error) {
+	if warning < 0 || critical < 0 {
+		return nil, errors.New("Thresholds must not be negative")
+	}
+
+	if err := monitors.ValidateThresholds(monitors.Thresholds(warning, critical, metric)); err != nil {
+		return nil, errors.New("Invalid thresholds: " + err.Error())
+	}
+
+	if len(metric) == 0 {
+		return nil, nil
+	}
+
+	thresholdsJSON, err := json.Marshal(metric)
+	if err != nil {
+		return nil, errors.New("Invalid metric thresholds format")
+	}
+
+	return thresholdsJSON, nil
+}
+
+// prepareSeverityRules validates the default severity and severity rules of a
+// monitor and returns the rules as JSON
+func prepareSeverityRules(defaultSeverity string, rules []types.SeverityRule) (string, datatypes.JSON, error) {
+	if defaultSeverity == "" {
+		defaultSeverity = models.SeverityMedium
+	}
+
+	if !models.ValidSeverity(defaultSeverity) {
+		return "", nil, errors.New("Default severity must be low, medium, high or critical")
+	}
+
+	for _, rule := range rules {
+		if !models.ValidSeverity(rule.Severity) {
+			return "", nil, errors.New("Severity rules need a severity of low, medium, high or critical")
+		}
+		if rule.AfterMinutes < 0 || rule.FailingLocations < 0 {
+			return "", nil, errors.New("Severity rule conditions must not be negative")
+		}
+		if rule.AfterMinutes == 0 && rule.FailingLocations == 0 {
+			return "", nil, errors.New("Severity rules need after_minutes or failing_locations")
+		}
+	}
+
+	if len(rules) == 0 {
+		return defaultSeverity, nil, nil
+	}
+
+	rulesJSON, err := json.Marshal(rules)
+	if err != nil {
+		return "", nil, errors.New("Invalid severity rules format")
+	}
+
+	return defaultSeverity, rulesJSON, nil
+}
+
+// prepareMonitorConfig validates a monitor config and returns it as JSON,
+// cleaning up fields that users commonly enter in a different format
+func prepareMonitorConfig(monitorType string, config map[string]interface{}) ([]byte, error) {
+	// Validate and clean DNS monitor config
+	if monitorType == "dns" {
 		// Extract and clean the domain
-		if domainValue, exists := dnsConfig["domain"]; exists {
+		if domainValue, exists := config["domain"]; exists {
 			if domainStr, ok := domainValue.(string); ok {
 				cleanDomain, err := utils.ExtractRawDomain(domainStr)
 				if err != nil {
-					ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid domain: " + err.Error()})
-					return
-				}
-				dnsConfig["domain"] = cleanDomain
-
-				// Re-marshal the cleaned config
-				configJSON, err = json.Marshal(dnsConfig)
-				if err != nil {
-					ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to process DNS config"})
-					return
+					return nil, errors.New("Invalid domain: " + err.Error())
 				}
+				config["domain"] = cleanDomain
 			}
 		}
 	}
 
-	monitor.Config = configJSON
+	// Drop repeated group members, so min is checked against the members evaluated
+	if monitorType == "group" {
+		if members, ok := config["members"].([]interface{}); ok {
+			unique := make([]interface{}, 0, len(members))
+			seen := make(map[interface{}]bool)
 
-	if err := db.DB.Save(&monitor).Error; err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update monitor"})
-		return
+			for _, member := range members {
+				if !seen[member] {
+					seen[member] = true
+					unique = append(unique, member)
+				}
+			}
+
+			config["members"] = unique
+		}
 	}
 
-	scheduler.UpdateMonitor(monitor)
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return nil, errors.New("Invalid config format")
+	}
 
-	// Broadcast immediate refresh for monitor configuration update
-	BroadCastRefresh(strconv.FormatUint(uint64(projectID), 10))
+	if err := monitors.ValidateConfig(monitorType, configJSON); err != nil {
+		return nil, errors.New("Invalid config: " + err.Error())
+	}
 
-	ctx.JSON(http.StatusOK, gin.H{"message": "Monitor updated successfully", "monitor_id": monitor.ID})
+	return configJSON, nil
 }
 
 func buildMonitorSummary(monitor models.Monitor) (MonitorSummary, error) {
@@ -415,20 +852,44 @@ func buildMonitorSummary(monitor models.Monitor) (MonitorSummary, error) {
 	// Sanitize config to remove sensitive data
 	sanitizedConfig := sanitizeConfig(config, monitor.Type)
 
+	parents, err := monitorParents(monitor.ID)
+	if err != nil {
+		log.Printf("Error fetching dependencies for monitor %d: %v", monitor.ID, err)
+		return MonitorSummary{}, err
+	}
+
 	summary := MonitorSummary{
-		ID:           monitor.ID,
-		Name:         monitor.Name,
-		Type:         monitor.Type,
-		Status:       monitor.Status,
-		Interval:     monitor.Interval,
-		Config:       sanitizedConfig,
-		Uptime:       uptime,
-		ResponseTime: avgResponseTime,
+		ID:                 monitor.ID,
+		Name:               monitor.Name,
+		Type:               monitor.Type,
+		Status:             monitor.Status,
+		Interval:           monitor.Interval,
+		Config:             sanitizedConfig,
+		Locations:          monitor.Locations,
+		Quorum:             monitor.Quorum,
+		BackoffEnabled:     monitor.BackoffEnabled,
+		EffectiveInterval:  monitor.Interval,
+		WarningThreshold:   monitor.WarningThreshold,
+		CriticalThreshold:  monitor.CriticalThreshold,
+		MetricThresholds:   json.RawMessage(monitor.MetricThresholds),
+		DegradedIncidents:  monitor.DegradedIncidents,
+		DependsOn:          parents,
+		DefaultSeverity:    monitor.DefaultSeverity,
+		SeverityRules:      json.RawMessage(monitor.SeverityRules),
+		EscalationPolicyID: monitor.EscalationPolicyID,
+		Tags:               monitor.Tags,
+		Uptime:             uptime,
+		ResponseTime:       avgResponseTime,
+	}
+
+	if monitor.EffectiveInterval > 0 {
+		summary.EffectiveInterval = monitor.EffectiveInterval
 	}
 
 	if lastCheckFound {
 		summary.LastCheck = &MonitorCheckSummary{
 			ID:           lastCheck.ID,
+			Location:     lastCheck.Location,
 			Status:       lastCheck.Status,
 			ResponseTime: lastCheck.ResponseTime,
 			Message:      lastCheck.Message,
@@ -447,9 +908,9 @@ func calculateUptime(monitorID uint) float64 {
 		Where("monitor_id = ? AND checked_at > ?", monitorID, time.Now().Add(-24*time.Hour)).
 		Count(&total)
 
-	// Count successful checks
+	// Count checks where the monitor was up, degraded checks were slow but reachable
 	db.DB.Model(&models.MonitorCheck{}).
-		Where("monitor_id = ? AND status = 'success' AND checked_at > ?", monitorID, time.Now().Add(-24*time.Hour)).
+		Where("monitor_id = ? AND status IN ('success', 'degraded') AND checked_at > ?", monitorID, time.Now().Add(-24*time.Hour)).
 		Count(&successful)
 
 	if total == 0 {
@@ -464,7 +925,7 @@ func calculateAverageResponseTime(monitorID uint) float64 {
 
 	db.DB.Model(&models.MonitorCheck{}).
 		Select("AVG(response_time)").
-		Where("monitor_id = ? AND status = 'success' AND checked_at > ?", monitorID, time.Now().Add(-24*time.Hour)).
+		Where("monitor_id = ? AND status IN ('success', 'degraded') AND checked_at > ?", monitorID, time.Now().Add(-24*time.Hour)).
 		Scan(&avg)
 
 	if avg.Valid {
@@ -533,16 +994,15 @@ func GetDashboard(ctx *gin.Context) {
 
 	// Get recent incidents
 	var incidents []models.Incident
-	db.DB.Joins("JOIN monitors ON monitors.id = incidents.monitor_id").
-		Where("monitors.project_id = ? AND incidents.created_at > ?", projectID, time.Now().Add(-7*24*time.Hour)).
-		Order("incidents.created_at DESC").
+	db.DB.Preload("Monitor").
+		Where("project_id = ? AND created_at > ?", projectID, time.Now().Add(-7*24*time.Hour)).
+		Order("created_at DESC").
 		Limit(10).
 		Find(&incidents)
 
 	var incidentSummaries []IncidentSummary
 	for _, incident := range incidents {
-		var monitor models.Monitor
-		db.DB.First(&monitor, incident.MonitorID)
+		monitor := incident.Monitor
 
 		startedAt := time.Time{}
 		if incident.StartedAt != nil {
@@ -559,6 +1019,8 @@ func GetDashboard(ctx *gin.Context) {
 		incidentSummaries = append(incidentSummaries, IncidentSummary{
 			ID:          incident.ID,
 			MonitorName: monitor.Name,
+			Kind:        incident.Kind,
+			Severity:    incident.Severity,
 			Title:       incident.Title,
 			Description: incident.Description,
 			Status:      incident.Status,
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/monocle-dev/monocle/db"
+	"github.com/monocle-dev/monocle/internal/models"
+)
+
+// NotificationResponse is an entry of the delivery log
+type NotificationResponse struct {
+	ID            uint       `json:"id"`
+	EventType     string     `json:"event_type"`
+	Channel       string     `json:"channel"`
+	RuleID        *uint      `json:"rule_id"`
+	UserID        *uint      `json:"user_id"`
+	MonitorID     *uint      `json:"monitor_id"`
+	Status        string     `json:"status"` // "pending", "sent" or "failed"
+	Message       string     `json:"message"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error"`
+	NextAttemptAt *time.Time `json:"next_attempt_at"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// ListIncidentNotifications returns the delivery log of an incident
+func ListIncidentNotifications(ctx *gin.Context) {
+	incident, _, ok := loadProjectIncident(ctx)
+
+	if !ok {
+		return
+	}
+
+	var notifications []models.Notification
+
+	if err := db.DB.Where("incident_id = ?", incident.ID).
+		Order("created_at, id").
+		Find(&notifications).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve notifications"})
+		return
+	}
+
+	responses := make([]NotificationResponse, 0, len(notifications))
+	for _, notification := range notifications {
+		responses = append(responses, buildNotificationResponse(notification))
+	}
+
+	ctx.JSON(http.StatusOK, responses)
+}
+
+func buildNotificationResponse(notification models.Notification) NotificationResponse {
+	return NotificationResponse{
+		ID:            notification.ID,
+		EventType:     notification.EventType,
+		Channel:       notification.Channel,
+		RuleID:        notification.RuleID,
+		UserID:        notification.UserID,
+		MonitorID:     notification.MonitorID,
+		Status:        notification.Status,
+		Message:       notification.Message,
+		Attempts:      notification.Attempts,
+		LastError:     notification.LastError,
+		NextAttemptAt: notification.NextAttemptAt,
+		SentAt:        notification.SentAt,
+		CreatedAt:     notification.CreatedAt,
+	}
+}
@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/monocle-dev/monocle/db"
+	"github.com/monocle-dev/monocle/internal/models"
+	"github.com/monocle-dev/monocle/internal/services"
+	"github.com/monocle-dev/monocle/internal/utils"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type NotificationRuleRequest struct {
+	Name        string          `json:"name"`
+	TriggerType string          `json:"trigger_type" binding:"required"` // e.g. "incident_created" or "ssl_expiring"
+	Channel     string          `json:"channel" binding:"required"`      // A channel of services.notifiers, e.g. "discord", "email" or "pagerduty"
+	Config      json.RawMessage `json:"config"`                          // Settings of the channel
+	IsActive    *bool           `json:"is_active"`                       // Defaults to true
+	UserID      *uint           `json:"user_id"`                         // User the rule notifies, defaults to the current user
+	ScheduleID  *uint           `json:"schedule_id"`                     // Notify whoever is on call instead of a user
+	MonitorIDs  []uint          `json:"monitor_ids"`
+	Tags        []string        `json:"tags"`
+	MinSeverity string          `json:"min_severity" binding:"omitempty,oneof=low medium high critical"`
+
+	TitleTemplate string `json:"title_template"` // Go templates replacing the project's templates
+	BodyTemplate  string `json:"body_template"`
+
+	Digest    string `json:"digest" binding:"omitempty,oneof=hourly daily"` // Send a summary of the events instead
+	RateLimit int    `json:"rate_limit" binding:"min=0"`                    // Messages per minute, 0 for the channel's default
+}
+
+type NotificationRuleResponse struct {
+	ID          uint            `json:"id"`
+	Name        string          `json:"name"`
+	UserID      *uint           `json:"user_id"`
+	ScheduleID  *uint           `json:"schedule_id"`
+	TriggerType string          `json:"trigger_type"`
+	Channel     string          `json:"channel"`
+	Config      json.RawMessage `json:"config"`
+	IsActive    bool            `json:"is_active"`
+	MonitorIDs  []int64         `json:"monitor_ids"`
+	Tags        []string        `json:"tags"`
+	MinSeverity string          `json:"min_severity"`
+
+	TitleTemplate string `json:"title_template"`
+	BodyTemplate  string `json:"body_template"`
+
+	Digest    string `json:"digest"`
+	RateLimit int    `json:"rate_limit"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func CreateNotificationRule(ctx *gin.Context) {
+	var req NotificationRuleRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, ok := loadOwnedProject(ctx)
+
+	if !ok {
+		return
+	}
+
+	userID, err := utils.GetCurrentUserID(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	rule := models.NotificationRule{ProjectID: project.ID, UserID: &userID, IsActive: true}
+
+	if err := applyNotificationRuleRequest(project, &rule, req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := db.DB.Create(&rule).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create notification rule"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, buildNotificationRuleResponse(rule))
+}
+
+func ListNotificationRules(ctx *gin.Context) {
+	project, ok := loadOwnedProject(ctx)
+
+	if !ok {
+		return
+	}
+
+	query := db.DB.Where("project_id = ?", project.ID)
+
+	if trigger := ctx.Query("trigger_type"); trigger != "" {
+		query = query.Where("trigger_type = ?", trigger)
+	}
+
+	var rules []models.NotificationRule
+
+	if err := query.Order("id").Find(&rules).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve notification rules"})
+		return
+	}
+
+	responses := make([]NotificationRuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		responses = append(responses, buildNotificationRuleResponse(rule))
+	}
+
+	ctx.JSON(http.StatusOK, responses)
+}
+
+func GetNotificationRule(ctx *gin.Context) {
+	_, rule, ok := loadNotificationRule(ctx)
+
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, buildNotificationRuleResponse(rule))
+}
+
+func UpdateNotificationRule(ctx *gin.Context) {
+	var req NotificationRuleRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, rule, ok := loadNotificationRule(ctx)
+
+	if !ok {
+		return
+	}
+
+	if err := applyNotificationRuleRequest(project, &rule, req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := db.DB.Save(&rule).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification rule"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, buildNotificationRuleResponse(rule))
+}
+
+func DeleteNotificationRule(ctx *gin.Context) {
+	_, rule, ok := loadNotificationRule(ctx)
+
+	if !ok {
+		return
+	}
+
+	// Notifications of the rule are kept and lose the reference through their
+	// foreign key, those still waiting in the outbox are given up on
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Notification{}).
+			Where("rule_id = ? AND status = ?", rule.ID, models.NotificationStatusPending).
+			Updates(map[string]interface{}{
+				"status":          models.NotificationStatusFailed,
+				"next_attempt_at": nil,
+				"last_error":      "Notification rule was deleted",
+			}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&rule).Error
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete notification rule"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// TestNotificationRule sends made up data over the channel of a rule, so its settings can be checked
+func TestNotificationRule(ctx *gin.Context) {
+	project, rule, ok := loadNotificationRule(ctx)
+
+	if !ok {
+		return
+	}
+
+	if err := services.SendTestNotification(project, rule); err != nil {
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": "Failed to send test notification: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Test notification sent"})
+}
+
+// loadNotificationRule loads the notification rule of the request from a project of the current user
+func loadNotificationRule(ctx *gin.Context) (models.Project, models.NotificationRule, bool) {
+	var rule models.NotificationRule
+
+	ruleID, err := strconv.ParseUint(ctx.Param("rule_id"), 10, 64)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Notification Rule ID"})
+		return models.Project{}, rule, false
+	}
+
+	project, ok := loadOwnedProject(ctx)
+
+	if !ok {
+		return project, rule, false
+	}
+
+	if err := db.DB.Where("id = ? AND project_id = ?", ruleID, project.ID).First(&rule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Notification rule not found"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve notification rule"})
+		}
+		return project, rule, false
+	}
+
+	return project, rule, true
+}
+
+// applyNotificationRuleRequest validates a rule request and copies it onto the
+// rule. The notified user or schedule and the monitors must belong to the project.
+func applyNotificationRuleRequest(project models.Project, rule *models.NotificationRule, req NotificationRuleRequest) error {
+	if !slices.Contains(models.NotificationTriggers, req.TriggerType) {
+		return fmt.Errorf("Unknown trigger type '%s'", req.TriggerType)
+	}
+
+	if err := services.ValidateNotificationChannel(req.Channel, req.Config); err != nil {
+		return err
+	}
+
+	if err := services.ValidateDigest(req.Channel, req.TriggerType, req.Digest); err != nil {
+		return err
+	}
+
+	switch {
+	case req.UserID != nil && req.ScheduleID != nil:
+		return errors.New("A rule notifies either a user_id or a schedule_id, not both")
+	case req.UserID != nil:
+		members, err := projectUsers(project)
+		if err != nil {
+			return err
+		}
+
+		if !members[*req.UserID] {
+			return fmt.Errorf("User %d is not a member of the project", *req.UserID)
+		}
+
+		rule.UserID = req.UserID
+		rule.ScheduleID = nil
+	case req.ScheduleID != nil:
+		var count int64
+		if err := db.DB.Model(&models.OnCallSchedule{}).Where("id = ? AND project_id = ?", *req.ScheduleID, project.ID).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count == 0 {
+			return fmt.Errorf("Schedule %d is not in the project", *req.ScheduleID)
+		}
+
+		rule.ScheduleID = req.ScheduleID
+		rule.UserID = nil
+	}
+
+	monitorIDs := pq.Int64Array{}
+	seen := make(map[uint]bool)
+
+	for _, id := range req.MonitorIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		monitorIDs = append(monitorIDs, int64(id))
+	}
+
+	if len(monitorIDs) > 0 {
+		var count int64
+		if err := db.DB.Model(&models.Monitor{}).Where("project_id = ? AND id IN ?", project.ID, req.MonitorIDs).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if int(count) != len(monitorIDs) {
+			return errors.New("Monitors must belong to the project")
+		}
+	}
+
+	rule.Name = strings.TrimSpace(req.Name)
+	rule.TriggerType = req.TriggerType
+	rule.Channel = req.Channel
+	rule.Config = datatypes.JSON(req.Config)
+	rule.MonitorIDs = monitorIDs
+	rule.Tags = prepareMonitorTags(req.Tags)
+	rule.MinSeverity = req.MinSeverity
+	rule.TitleTemplate = req.TitleTemplate
+	rule.BodyTemplate = req.BodyTemplate
+	rule.Digest = req.Digest
+	rule.RateLimit = req.RateLimit
+
+	templates := services.NotificationTemplates{Title: rule.TitleTemplate, Body: rule.BodyTemplate}
+	if err := services.ValidateNotificationTemplates(templates, rule.TriggerType); err != nil {
+		return fmt.Errorf("Invalid notification template: %v", err)
+	}
+
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	return nil
+}
+
+func buildNotificationRuleResponse(rule models.NotificationRule) NotificationRuleResponse {
+	return NotificationRuleResponse{
+		ID:          rule.ID,
+		Name:        rule.Name,
+		UserID:      rule.UserID,
+		ScheduleID:  rule.ScheduleID,
+		TriggerType: rule.TriggerType,
+		Channel:     rule.Channel,
+		Config:      json.RawMessage(rule.Config),
+		IsActive:    rule.IsActive,
+		MonitorIDs:  rule.MonitorIDs,
+		Tags:        rule.Tags,
+		MinSeverity: rule.MinSeverity,
+
+		TitleTemplate: rule.TitleTemplate,
+		BodyTemplate:  rule.BodyTemplate,
+
+		Digest:    rule.Digest,
+		RateLimit: rule.RateLimit,
+
+		CreatedAt: rule.CreatedAt,
+		UpdatedAt: rule.UpdatedAt,
+	}
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/monocle-dev/monocle/db"
+	"github.com/monocle-dev/monocle/internal/models"
+	"github.com/monocle-dev/monocle/internal/services"
+	"gorm.io/gorm"
+)
+
+type NotificationPreviewRequest struct {
+	TitleTemplate string `json:"title_template"` // The project's template when empty
+	BodyTemplate  string `json:"body_template"`  // The project's template when empty
+	TriggerType   string `json:"trigger_type"`   // Defaults to incident_created
+	IncidentID    *uint  `json:"incident_id"`    // Renders a real incident of the project instead of made up data
+}
+
+type NotificationPreviewResponse struct {
+	Title  string                  `json:"title"`
+	Body   string                  `json:"body"`
+	Level  string                  `json:"level"`  // "critical", "warning", "resolved" or "info"
+	Fields []services.MessageField `json:"fields"` // Empty when the body is templated
+}
+
+// PreviewNotificationTemplate renders notification templates the way chat
+// channels would show them
+func PreviewNotificationTemplate(ctx *gin.Context) {
+	var req NotificationPreviewRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	project, ok := loadOwnedProject(ctx)
+
+	if !ok {
+		return
+	}
+
+	if req.TriggerType == "" {
+		req.TriggerType = models.TriggerIncidentCreated
+	}
+
+	if !slices.Contains(models.NotificationTriggers, req.TriggerType) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Unknown trigger type '" + req.TriggerType + "'"})
+		return
+	}
+
+	var incident *models.Incident
+
+	if req.IncidentID != nil {
+		incident = &models.Incident{}
+
+		if err := db.DB.Preload("Monitor").
+			Where("id = ? AND project_id = ?", *req.IncidentID, project.ID).
+			First(incident).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "Incident not found"})
+			} else {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve incident"})
+			}
+			return
+		}
+	}
+
+	now := time.Now()
+	templates := services.NotificationTemplates{Title: req.TitleTemplate, Body: req.BodyTemplate}.
+		Or(services.ProjectNotificationTemplates(project))
+
+	message, err := services.PreviewNotification(project, templates, services.PreviewEvent(req.TriggerType, incident, now), now)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification template: " + err.Error()})
+		return
+	}
+
+	fields := message.Fields
+	if fields == nil {
+		fields = []services.MessageField{}
+	}
+
+	ctx.JSON(http.StatusOK, NotificationPreviewResponse{
+		Title:  message.Heading(),
+		Body:   message.Summary,
+		Level:  message.Level,
+		Fields: fields,
+	})
+}
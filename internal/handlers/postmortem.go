@@ -0,0 +1,502 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/monocle-dev/monocle/db"
+	"github.com/monocle-dev/monocle/internal/models"
+	"github.com/monocle-dev/monocle/internal/services"
+	"gorm.io/gorm"
+)
+
+type CreatePostmortemRequest struct {
+	Title               string   `json:"title"`   // Defaults to the incident title
+	Content             string   `json:"content"` // Rendered from the project's template when empty
+	ContributingFactors []string `json:"contributing_factors"`
+}
+
+type UpdatePostmortemRequest struct {
+	Title               string   `json:"title" binding:"required"`
+	Content             string   `json:"content"`
+	ContributingFactors []string `json:"contributing_factors"`
+	Status              string   `json:"status" binding:"omitempty,oneof=draft published"`
+}
+
+type ActionItemRequest struct {
+	Description string     `json:"description" binding:"required"`
+	OwnerID     *uint      `json:"owner_id"`
+	DueDate     *time.Time `json:"due_date"`
+	Status      string     `json:"status" binding:"omitempty,oneof=open done"`
+}
+
+type ActionItemResponse struct {
+	models.PostmortemActionItem
+	IncidentID      uint   `json:"incident_id"`
+	PostmortemTitle string `json:"postmortem_title"`
+	OwnerName       string `json:"owner_name,omitempty"`
+}
+
+type PostmortemExport struct {
+	Postmortem models.Postmortem       `json:"postmortem"`
+	Incident   IncidentResponse        `json:"incident"`
+	Timeline   []IncidentEventResponse `json:"timeline"`
+}
+
+// CreatePostmortem starts the postmortem of an incident, pre-filled from the
+// project's template with the timeline, failed checks and affected monitors
+func CreatePostmortem(ctx *gin.Context) {
+	var req CreatePostmortemRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	incident, userID, ok := loadProjectIncident(ctx)
+
+	if !ok {
+		return
+	}
+
+	var existing int64
+
+	if err := db.DB.Model(&models.Postmortem{}).Where("incident_id = ?", incident.ID).Count(&existing).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check for a postmortem"})
+		return
+	}
+
+	if existing > 0 {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Incident already has a postmortem"})
+		return
+	}
+
+	postmortem := models.Postmortem{
+		IncidentID:          incident.ID,
+		Title:               strings.TrimSpace(req.Title),
+		Content:             req.Content,
+		ContributingFactors: pq.StringArray(cleanContributingFactors(req.ContributingFactors)),
+		Status:              models.PostmortemStatusDraft,
+		AuthorID:            &userID,
+	}
+
+	if postmortem.Title == "" {
+		postmortem.Title = "Postmortem: " + incident.Title
+	}
+
+	if strings.TrimSpace(postmortem.Content) == "" {
+		var project models.Project
+
+		if err := db.DB.First(&project, incident.ProjectID).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve project"})
+			return
+		}
+
+		data, err := services.LoadPostmortemData(db.DB, project, incident)
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to collect incident data"})
+			return
+		}
+
+		content, err := services.RenderPostmortem(data)
+
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to render the project's postmortem template: " + err.Error()})
+			return
+		}
+
+		postmortem.Content = content
+	}
+
+	if err := db.DB.Create(&postmortem).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create postmortem"})
+		return
+	}
+
+	postmortem.ActionItems = []models.PostmortemActionItem{}
+
+	ctx.JSON(http.StatusCreated, postmortem)
+}
+
+func GetPostmortem(ctx *gin.Context) {
+	_, postmortem, ok := loadIncidentPostmortem(ctx)
+
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, postmortem)
+}
+
+func UpdatePostmortem(ctx *gin.Context) {
+	var req UpdatePostmortemRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	_, postmortem, ok := loadIncidentPostmortem(ctx)
+
+	if !ok {
+		return
+	}
+
+	postmortem.Title = strings.TrimSpace(req.Title)
+	postmortem.Content = req.Content
+	postmortem.ContributingFactors = pq.StringArray(cleanContributingFactors(req.ContributingFactors))
+
+	if req.Status != "" {
+		postmortem.Status = req.Status
+	}
+
+	if err := db.DB.Omit("ActionItems").Save(&postmortem).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update postmortem"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, postmortem)
+}
+
+func DeletePostmortem(ctx *gin.Context) {
+	_, postmortem, ok := loadIncidentPostmortem(ctx)
+
+	if !ok {
+		return
+	}
+
+	if err := db.DB.Delete(&postmortem).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete postmortem"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ExportPostmortem exports a postmortem as Markdown or, with format=json, as
+// JSON together with the incident and its timeline
+func ExportPostmortem(ctx *gin.Context) {
+	incident, postmortem, ok := loadIncidentPostmortem(ctx)
+
+	if !ok {
+		return
+	}
+
+	switch ctx.DefaultQuery("format", "markdown") {
+	case "markdown", "md":
+		names, err := actionItemOwnerNames(postmortem.ActionItems)
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve action item owners"})
+			return
+		}
+
+		markdown := services.ExportPostmortemMarkdown(postmortem, incident, names)
+
+		ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="postmortem-%d.md"`, incident.ID))
+		ctx.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(markdown))
+	case "json":
+		var events []models.IncidentEvent
+
+		if err := db.DB.Preload("User").Where("incident_id = ?", incident.ID).Order("created_at, id").Find(&events).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve timeline"})
+			return
+		}
+
+		export := PostmortemExport{
+			Postmortem: postmortem,
+			Incident:   buildIncidentResponse(incident),
+			Timeline:   make([]IncidentEventResponse, 0, len(events)),
+		}
+
+		for _, event := range events {
+			export.Timeline = append(export.Timeline, buildIncidentEventResponse(event))
+		}
+
+		ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="postmortem-%d.json"`, incident.ID))
+		ctx.JSON(http.StatusOK, export)
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format, expected markdown or json"})
+	}
+}
+
+func CreateActionItem(ctx *gin.Context) {
+	var req ActionItemRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	incident, postmortem, ok := loadIncidentPostmortem(ctx)
+
+	if !ok {
+		return
+	}
+
+	item := models.PostmortemActionItem{PostmortemID: postmortem.ID}
+
+	if !applyActionItemRequest(ctx, incident.ProjectID, &item, req) {
+		return
+	}
+
+	if err := db.DB.Create(&item).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create action item"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, item)
+}
+
+func UpdateActionItem(ctx *gin.Context) {
+	var req ActionItemRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, item, ok := loadProjectActionItem(ctx)
+
+	if !ok {
+		return
+	}
+
+	if !applyActionItemRequest(ctx, project.ID, &item, req) {
+		return
+	}
+
+	if err := db.DB.Save(&item).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update action item"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, item)
+}
+
+func DeleteActionItem(ctx *gin.Context) {
+	_, item, ok := loadProjectActionItem(ctx)
+
+	if !ok {
+		return
+	}
+
+	if err := db.DB.Delete(&item).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete action item"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListActionItems lists the action items of all postmortems of a project,
+// the open ones by default, oldest due date first
+func ListActionItems(ctx *gin.Context) {
+	project, ok := loadOwnedProject(ctx)
+
+	if !ok {
+		return
+	}
+
+	status := ctx.DefaultQuery("status", models.ActionItemStatusOpen)
+
+	query := projectActionItems(project.ID)
+
+	switch status {
+	case models.ActionItemStatusOpen, models.ActionItemStatusDone:
+		query = query.Where("postmortem_action_items.status = ?", status)
+	case "all":
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status, expected open, done or all"})
+		return
+	}
+
+	var items []models.PostmortemActionItem
+
+	if err := query.Preload("Postmortem").Preload("Owner").
+		Order("postmortem_action_items.due_date ASC NULLS LAST, postmortem_action_items.id").
+		Find(&items).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve action items"})
+		return
+	}
+
+	responses := make([]ActionItemResponse, 0, len(items))
+
+	for _, item := range items {
+		response := ActionItemResponse{
+			PostmortemActionItem: item,
+			IncidentID:           item.Postmortem.IncidentID,
+			PostmortemTitle:      item.Postmortem.Title,
+		}
+
+		if item.Owner != nil {
+			response.OwnerName = item.Owner.Name
+		}
+
+		responses = append(responses, response)
+	}
+
+	ctx.JSON(http.StatusOK, responses)
+}
+
+// projectActionItems scopes a query to the action items of a project's postmortems
+func projectActionItems(projectID uint) *gorm.DB {
+	return db.DB.Model(&models.PostmortemActionItem{}).
+		Select("postmortem_action_items.*").
+		Joins("JOIN postmortems ON postmortems.id = postmortem_action_items.postmortem_id").
+		Joins("JOIN incidents ON incidents.id = postmortems.incident_id").
+		Where("incidents.project_id = ?", projectID)
+}
+
+// loadIncidentPostmortem loads the postmortem of the incident addressed by the
+// request with its action items, and writes the error response otherwise
+func loadIncidentPostmortem(ctx *gin.Context) (models.Incident, models.Postmortem, bool) {
+	var postmortem models.Postmortem
+
+	incident, _, ok := loadProjectIncident(ctx)
+
+	if !ok {
+		return incident, postmortem, false
+	}
+
+	if err := db.DB.Preload("ActionItems", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("id")
+	}).Where("incident_id = ?", incident.ID).First(&postmortem).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Postmortem not found"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve postmortem"})
+		}
+		return incident, postmortem, false
+	}
+
+	return incident, postmortem, true
+}
+
+// loadProjectActionItem loads the action item addressed by the request from a project of the current user
+func loadProjectActionItem(ctx *gin.Context) (models.Project, models.PostmortemActionItem, bool) {
+	var item models.PostmortemActionItem
+
+	itemID, err := strconv.ParseUint(ctx.Param("item_id"), 10, 64)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Action Item ID"})
+		return models.Project{}, item, false
+	}
+
+	project, ok := loadOwnedProject(ctx)
+
+	if !ok {
+		return project, item, false
+	}
+
+	if err := projectActionItems(project.ID).Where("postmortem_action_items.id = ?", itemID).First(&item).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Action item not found"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve action item"})
+		}
+		return project, item, false
+	}
+
+	return project, item, true
+}
+
+// applyActionItemRequest validates an action item request and copies it onto
+// the item, writing the error response when it is invalid
+func applyActionItemRequest(ctx *gin.Context, projectID uint, item *models.PostmortemActionItem, req ActionItemRequest) bool {
+	description := strings.TrimSpace(req.Description)
+
+	if description == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Description must not be empty"})
+		return false
+	}
+
+	if req.OwnerID != nil {
+		var project models.Project
+
+		if err := db.DB.First(&project, projectID).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve project"})
+			return false
+		}
+
+		users, err := projectUsers(project)
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve project members"})
+			return false
+		}
+
+		if !users[*req.OwnerID] {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Owner is not a member of the project"})
+			return false
+		}
+	}
+
+	status := req.Status
+	if status == "" {
+		status = models.ActionItemStatusOpen
+	}
+
+	if status == models.ActionItemStatusDone && item.Status != models.ActionItemStatusDone {
+		now := time.Now()
+		item.CompletedAt = &now
+	} else if status == models.ActionItemStatusOpen {
+		item.CompletedAt = nil
+	}
+
+	item.Description = description
+	item.OwnerID = req.OwnerID
+	item.DueDate = req.DueDate
+	item.Status = status
+
+	return true
+}
+
+// actionItemOwnerNames maps the owners of action items to their names
+func actionItemOwnerNames(items []models.PostmortemActionItem) (map[uint]string, error) {
+	names := make(map[uint]string)
+
+	var ownerIDs []uint
+	for _, item := range items {
+		if item.OwnerID != nil {
+			ownerIDs = append(ownerIDs, *item.OwnerID)
+		}
+	}
+
+	if len(ownerIDs) == 0 {
+		return names, nil
+	}
+
+	var users []models.User
+
+	if err := db.DB.Select("id, name").Where("id IN ?", ownerIDs).Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		names[user.ID] = user.Name
+	}
+
+	return names, nil
+}
+
+// cleanContributingFactors drops empty contributing factors
+func cleanContributingFactors(factors []string) []string {
+	cleaned := []string{}
+
+	for _, factor := range factors {
+		if factor = strings.TrimSpace(factor); factor != "" {
+			cleaned = append(cleaned, factor)
+		}
+	}
+
+	return cleaned
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/monocle-dev/monocle/db"
 	"github.com/monocle-dev/monocle/internal/models"
 	"github.com/monocle-dev/monocle/internal/scheduler"
+	"github.com/monocle-dev/monocle/internal/services"
 	"github.com/monocle-dev/monocle/internal/utils"
 	"gorm.io/gorm"
 )
@@ -18,6 +19,19 @@ type CreateProjectRequest struct {
 	Description    string `json:"description"`
 	DiscordWebhook string `json:"discord_webhook"`
 	SlackWebhook   string `json:"slack_webhook"`
+
+	NotifyMinSeverity string `json:"notify_min_severity" binding:"omitempty,oneof=low medium high critical"` // Quieter incidents send no notifications
+
+	ReminderInterval int     `json:"reminder_interval" binding:"omitempty,min=0"`
+	ReminderBackoff  float64 `json:"reminder_backoff" binding:"omitempty,min=1"`
+	ReminderMaxCount int     `json:"reminder_max_count" binding:"omitempty,min=0"`
+
+	PostmortemTemplate string `json:"postmortem_template"`
+
+	NotificationTitleTemplate string `json:"notification_title_template"`
+	NotificationBodyTemplate  string `json:"notification_body_template"`
+	NotificationUsername      string `json:"notification_username"`
+	NotificationAvatarURL     string `json:"notification_avatar_url"`
 }
 
 type UpdateProjectRequest struct {
@@ -25,6 +39,21 @@ type UpdateProjectRequest struct {
 	Description    string `json:"description"`
 	DiscordWebhook string `json:"discord_webhook"`
 	SlackWebhook   string `json:"slack_webhook"`
+
+	NotifyMinSeverity string `json:"notify_min_severity" binding:"omitempty,oneof=low medium high critical"` // Quieter incidents send no notifications
+
+	ReminderInterval int     `json:"reminder_interval" binding:"omitempty,min=0"`  // Minutes before the first reminder, 0 disables reminders
+	ReminderBackoff  float64 `json:"reminder_backoff" binding:"omitempty,min=1"`   // Multiplier applied to the wait after every reminder
+	ReminderMaxCount int     `json:"reminder_max_count" binding:"omitempty,min=0"` // Reminders per incident at most, 0 means no limit
+
+	PostmortemTemplate string `json:"postmortem_template"` // Go template for new postmortems, empty for the built-in one
+
+	NotificationTitleTemplate string `json:"notification_title_template"` // Go template for the title of chat, push and email messages
+	NotificationBodyTemplate  string `json:"notification_body_template"`  // Go template for their body, replacing the built-in fields
+	NotificationUsername      string `json:"notification_username"`       // Name chat messages are posted as
+	NotificationAvatarURL     string `json:"notification_avatar_url"`     // Avatar of chat messages
+
+	EscalationPolicyID *uint `json:"escalation_policy_id"` // Default policy for incidents of the project's monitors
 }
 
 type GetProjectResponse struct {
@@ -34,6 +63,21 @@ type GetProjectResponse struct {
 	OwnerID        uint   `json:"owner_id"`
 	DiscordWebhook string `json:"discord_webhook"`
 	SlackWebhook   string `json:"slack_webhook"`
+
+	NotifyMinSeverity string `json:"notify_min_severity"`
+
+	ReminderInterval int     `json:"reminder_interval"`
+	ReminderBackoff  float64 `json:"reminder_backoff"`
+	ReminderMaxCount int     `json:"reminder_max_count"`
+
+	PostmortemTemplate string `json:"postmortem_template"`
+
+	NotificationTitleTemplate string `json:"notification_title_template"`
+	NotificationBodyTemplate  string `json:"notification_body_template"`
+	NotificationUsername      string `json:"notification_username"`
+	NotificationAvatarURL     string `json:"notification_avatar_url"`
+
+	EscalationPolicyID *uint `json:"escalation_policy_id"`
 }
 
 func CreateProject(ctx *gin.Context) {
@@ -57,6 +101,37 @@ func CreateProject(ctx *gin.Context) {
 		OwnerID:        userID,
 		DiscordWebhook: body.DiscordWebhook,
 		SlackWebhook:   body.SlackWebhook,
+
+		NotifyMinSeverity: body.NotifyMinSeverity,
+
+		ReminderInterval: body.ReminderInterval,
+		ReminderBackoff:  body.ReminderBackoff,
+		ReminderMaxCount: body.ReminderMaxCount,
+
+		PostmortemTemplate: body.PostmortemTemplate,
+
+		NotificationTitleTemplate: body.NotificationTitleTemplate,
+		NotificationBodyTemplate:  body.NotificationBodyTemplate,
+		NotificationUsername:      body.NotificationUsername,
+		NotificationAvatarURL:     body.NotificationAvatarURL,
+	}
+
+	if _, err := services.ParsePostmortemTemplate(project.PostmortemTemplate); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid postmortem template: " + err.Error()})
+		return
+	}
+
+	if err := services.ValidateProjectNotifications(project); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if project.NotifyMinSeverity == "" {
+		project.NotifyMinSeverity = models.SeverityLow
+	}
+
+	if project.ReminderBackoff == 0 {
+		project.ReminderBackoff = 1
 	}
 
 	if err := db.DB.Create(&project).Error; err != nil {
@@ -71,6 +146,21 @@ func CreateProject(ctx *gin.Context) {
 		OwnerID:        project.OwnerID,
 		DiscordWebhook: project.DiscordWebhook,
 		SlackWebhook:   project.SlackWebhook,
+
+		NotifyMinSeverity: project.NotifyMinSeverity,
+
+		ReminderInterval: project.ReminderInterval,
+		ReminderBackoff:  project.ReminderBackoff,
+		ReminderMaxCount: project.ReminderMaxCount,
+
+		PostmortemTemplate: project.PostmortemTemplate,
+
+		NotificationTitleTemplate: project.NotificationTitleTemplate,
+		NotificationBodyTemplate:  project.NotificationBodyTemplate,
+		NotificationUsername:      project.NotificationUsername,
+		NotificationAvatarURL:     project.NotificationAvatarURL,
+
+		EscalationPolicyID: project.EscalationPolicyID,
 	})
 }
 
@@ -84,7 +174,7 @@ func ListProjects(ctx *gin.Context) {
 
 	var projects []models.Project
 
-	if err := db.DB.Select("id, name, description, owner_id, discord_webhook, slack_webhook").Where("owner_id = ?", userID).Find(&projects).Error; err != nil {
+	if err := db.DB.Select("id, name, description, owner_id, discord_webhook, slack_webhook, notify_min_severity, reminder_interval, reminder_backoff, reminder_max_count, postmortem_template, notification_title_template, notification_body_template, notification_username, notification_avatar_url, escalation_policy_id").Where("owner_id = ?", userID).Find(&projects).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve projects"})
 		return
 	}
@@ -99,6 +189,21 @@ func ListProjects(ctx *gin.Context) {
 			OwnerID:        project.OwnerID,
 			DiscordWebhook: project.DiscordWebhook,
 			SlackWebhook:   project.SlackWebhook,
+
+			NotifyMinSeverity: project.NotifyMinSeverity,
+
+			ReminderInterval: project.ReminderInterval,
+			ReminderBackoff:  project.ReminderBackoff,
+			ReminderMaxCount: project.ReminderMaxCount,
+
+			PostmortemTemplate: project.PostmortemTemplate,
+
+			NotificationTitleTemplate: project.NotificationTitleTemplate,
+			NotificationBodyTemplate:  project.NotificationBodyTemplate,
+			NotificationUsername:      project.NotificationUsername,
+			NotificationAvatarURL:     project.NotificationAvatarURL,
+
+			EscalationPolicyID: project.EscalationPolicyID,
 		})
 	}
 
@@ -144,6 +249,40 @@ func UpdateProject(ctx *gin.Context) {
 	project.DiscordWebhook = body.DiscordWebhook
 	project.SlackWebhook = body.SlackWebhook
 
+	if body.NotifyMinSeverity != "" {
+		project.NotifyMinSeverity = body.NotifyMinSeverity
+	}
+
+	project.ReminderInterval = body.ReminderInterval
+	project.ReminderBackoff = body.ReminderBackoff
+	project.ReminderMaxCount = body.ReminderMaxCount
+	project.PostmortemTemplate = body.PostmortemTemplate
+	project.NotificationTitleTemplate = body.NotificationTitleTemplate
+	project.NotificationBodyTemplate = body.NotificationBodyTemplate
+	project.NotificationUsername = body.NotificationUsername
+	project.NotificationAvatarURL = body.NotificationAvatarURL
+
+	if _, err := services.ParsePostmortemTemplate(project.PostmortemTemplate); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid postmortem template: " + err.Error()})
+		return
+	}
+
+	if err := services.ValidateProjectNotifications(project); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if project.ReminderBackoff == 0 {
+		project.ReminderBackoff = 1
+	}
+
+	if err := validateEscalationPolicy(db.DB, project.ID, body.EscalationPolicyID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project.EscalationPolicyID = body.EscalationPolicyID
+
 	if err := db.DB.Save(&project).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update project"})
 		return
@@ -156,6 +295,21 @@ func UpdateProject(ctx *gin.Context) {
 		OwnerID:        project.OwnerID,
 		DiscordWebhook: project.DiscordWebhook,
 		SlackWebhook:   project.SlackWebhook,
+
+		NotifyMinSeverity: project.NotifyMinSeverity,
+
+		ReminderInterval: project.ReminderInterval,
+		ReminderBackoff:  project.ReminderBackoff,
+		ReminderMaxCount: project.ReminderMaxCount,
+
+		PostmortemTemplate: project.PostmortemTemplate,
+
+		NotificationTitleTemplate: project.NotificationTitleTemplate,
+		NotificationBodyTemplate:  project.NotificationBodyTemplate,
+		NotificationUsername:      project.NotificationUsername,
+		NotificationAvatarURL:     project.NotificationAvatarURL,
+
+		EscalationPolicyID: project.EscalationPolicyID,
 	})
 }
 
@@ -0,0 +1,479 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/monocle-dev/monocle/db"
+	"github.com/monocle-dev/monocle/internal/models"
+	"github.com/monocle-dev/monocle/internal/oncall"
+	"github.com/monocle-dev/monocle/internal/services"
+	"github.com/monocle-dev/monocle/internal/types"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultShiftRange = 28 * 24 * time.Hour
+	maxShiftRange     = 366 * 24 * time.Hour
+)
+
+type ScheduleRequest struct {
+	Name     string                `json:"name" binding:"required"`
+	Timezone string                `json:"timezone"` // IANA name, defaults to UTC
+	Layers   []types.RotationLayer `json:"layers" binding:"required"`
+}
+
+type OnCallOverrideRequest struct {
+	UserID   uint      `json:"user_id" binding:"required"`
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+	EndsAt   time.Time `json:"ends_at" binding:"required"`
+}
+
+type OnCallUserResponse struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type OnCallResponse struct {
+	ScheduleID uint                `json:"schedule_id"`
+	At         time.Time           `json:"at"`
+	OnCall     bool                `json:"on_call"`
+	User       *OnCallUserResponse `json:"user"`
+	Shift      *oncall.Shift       `json:"shift"`
+}
+
+func CreateSchedule(ctx *gin.Context) {
+	var req ScheduleRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, ok := loadOwnedProject(ctx)
+
+	if !ok {
+		return
+	}
+
+	timezone, layers, err := prepareScheduleLayers(project, req.Timezone, req.Layers)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	schedule := models.OnCallSchedule{
+		ProjectID: project.ID,
+		Name:      strings.TrimSpace(req.Name),
+		Timezone:  timezone,
+		Layers:    layers,
+	}
+
+	if err := db.DB.Create(&schedule).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create schedule"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, schedule)
+}
+
+func ListSchedules(ctx *gin.Context) {
+	project, ok := loadOwnedProject(ctx)
+
+	if !ok {
+		return
+	}
+
+	schedules := []models.OnCallSchedule{}
+
+	if err := db.DB.Where("project_id = ?", project.ID).Order("name").Find(&schedules).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve schedules"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedules)
+}
+
+// GetSchedule returns a schedule with its overrides that have not ended yet
+func GetSchedule(ctx *gin.Context) {
+	_, schedule, ok := loadSchedule(ctx)
+
+	if !ok {
+		return
+	}
+
+	if err := db.DB.Where("schedule_id = ? AND ends_at > ?", schedule.ID, time.Now()).
+		Order("starts_at").
+		Find(&schedule.Overrides).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve overrides"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedule)
+}
+
+func UpdateSchedule(ctx *gin.Context) {
+	var req ScheduleRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, schedule, ok := loadSchedule(ctx)
+
+	if !ok {
+		return
+	}
+
+	timezone, layers, err := prepareScheduleLayers(project, req.Timezone, req.Layers)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	schedule.Name = strings.TrimSpace(req.Name)
+	schedule.Timezone = timezone
+	schedule.Layers = layers
+
+	if err := db.DB.Save(&schedule).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update schedule"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedule)
+}
+
+// DeleteSchedule deletes a schedule unless an escalation policy still pages it
+func DeleteSchedule(ctx *gin.Context) {
+	_, schedule, ok := loadSchedule(ctx)
+
+	if !ok {
+		return
+	}
+
+	target := fmt.Sprintf(`[{"targets":[{"type":%q,"schedule_id":%d}]}]`, types.EscalationTargetSchedule, schedule.ID)
+
+	var policies int64
+
+	if err := db.DB.Model(&models.EscalationPolicy{}).Where("levels @> ?::jsonb", target).Count(&policies).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check escalation policies"})
+		return
+	}
+
+	if policies > 0 {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Schedule is used by an escalation policy"})
+		return
+	}
+
+	var rules int64
+
+	if err := db.DB.Model(&models.NotificationRule{}).Where("schedule_id = ?", schedule.ID).Count(&rules).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check notification rules"})
+		return
+	}
+
+	if rules > 0 {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Schedule is used by a notification rule"})
+		return
+	}
+
+	if err := db.DB.Delete(&schedule).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete schedule"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// GetOnCall returns who is on call for a schedule, now or at the time given in `at`
+func GetOnCall(ctx *gin.Context) {
+	_, schedule, ok := loadSchedule(ctx)
+
+	if !ok {
+		return
+	}
+
+	at := time.Now()
+
+	if atStr := ctx.Query("at"); atStr != "" {
+		parsed, err := time.Parse(time.RFC3339, atStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid at, expected an RFC 3339 time"})
+			return
+		}
+		at = parsed
+	}
+
+	_, rotation, err := services.LoadOnCallSchedule(db.DB, schedule.ID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load schedule"})
+		return
+	}
+
+	response := OnCallResponse{ScheduleID: schedule.ID, At: at}
+
+	if shift, onCall := rotation.At(at); onCall {
+		response.OnCall = true
+		response.Shift = &shift
+
+		var user models.User
+		if err := db.DB.Select("id, name, email").First(&user, shift.UserID).Error; err == nil {
+			response.User = &OnCallUserResponse{ID: user.ID, Name: user.Name, Email: user.Email}
+		}
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// GetScheduleShifts returns the final shifts of a schedule between `from` and `to`
+func GetScheduleShifts(ctx *gin.Context) {
+	_, schedule, ok := loadSchedule(ctx)
+
+	if !ok {
+		return
+	}
+
+	shifts, _, ok := scheduleShifts(ctx, schedule)
+
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, shifts)
+}
+
+// ExportScheduleICal exports the shifts of a schedule between `from` and `to` as iCalendar
+func ExportScheduleICal(ctx *gin.Context) {
+	_, schedule, ok := loadSchedule(ctx)
+
+	if !ok {
+		return
+	}
+
+	shifts, names, ok := scheduleShifts(ctx, schedule)
+
+	if !ok {
+		return
+	}
+
+	calendar := oncall.ICal(schedule.Name, fmt.Sprintf("schedule-%d", schedule.ID), shifts, names)
+
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="schedule-%d.ics"`, schedule.ID))
+	ctx.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(calendar))
+}
+
+func CreateOnCallOverride(ctx *gin.Context) {
+	var req OnCallOverrideRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, schedule, ok := loadSchedule(ctx)
+
+	if !ok {
+		return
+	}
+
+	if !req.EndsAt.After(req.StartsAt) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ends_at must be after starts_at"})
+		return
+	}
+
+	users, err := projectUsers(project)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve project members"})
+		return
+	}
+
+	if !users[req.UserID] {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "User is not a member of the project"})
+		return
+	}
+
+	override := models.OnCallOverride{
+		ScheduleID: schedule.ID,
+		UserID:     req.UserID,
+		StartsAt:   req.StartsAt,
+		EndsAt:     req.EndsAt,
+	}
+
+	if err := db.DB.Create(&override).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create override"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, override)
+}
+
+func DeleteOnCallOverride(ctx *gin.Context) {
+	_, schedule, ok := loadSchedule(ctx)
+
+	if !ok {
+		return
+	}
+
+	overrideID, err := strconv.ParseUint(ctx.Param("override_id"), 10, 64)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Override ID"})
+		return
+	}
+
+	result := db.DB.Where("id = ? AND schedule_id = ?", overrideID, schedule.ID).Delete(&models.OnCallOverride{})
+
+	if result.Error != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete override"})
+		return
+	}
+
+	if result.RowsAffected == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Override not found"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// loadSchedule loads the on-call schedule of the request from a project of the current user
+func loadSchedule(ctx *gin.Context) (models.Project, models.OnCallSchedule, bool) {
+	var schedule models.OnCallSchedule
+
+	scheduleID, err := strconv.ParseUint(ctx.Param("schedule_id"), 10, 64)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Schedule ID"})
+		return models.Project{}, schedule, false
+	}
+
+	project, ok := loadOwnedProject(ctx)
+
+	if !ok {
+		return project, schedule, false
+	}
+
+	if err := db.DB.Where("id = ? AND project_id = ?", scheduleID, project.ID).First(&schedule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve schedule"})
+		}
+		return project, schedule, false
+	}
+
+	return project, schedule, true
+}
+
+// scheduleShifts computes the shifts of a schedule for the `from` and `to`
+// query parameters, four weeks from now by default, and the names of their users
+func scheduleShifts(ctx *gin.Context, schedule models.OnCallSchedule) ([]oncall.Shift, map[uint]string, bool) {
+	from := time.Now()
+
+	if fromStr := ctx.Query("from"); fromStr != "" {
+		parsed, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from, expected an RFC 3339 time"})
+			return nil, nil, false
+		}
+		from = parsed
+	}
+
+	to := from.Add(defaultShiftRange)
+
+	if toStr := ctx.Query("to"); toStr != "" {
+		parsed, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to, expected an RFC 3339 time"})
+			return nil, nil, false
+		}
+		to = parsed
+	}
+
+	if !to.After(from) || to.Sub(from) > maxShiftRange {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "to must be after from and at most a year later"})
+		return nil, nil, false
+	}
+
+	_, rotation, err := services.LoadOnCallSchedule(db.DB, schedule.ID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load schedule"})
+		return nil, nil, false
+	}
+
+	shifts := rotation.Timeline(from, to)
+
+	userIDs := make([]uint, 0, len(shifts))
+	for _, shift := range shifts {
+		userIDs = append(userIDs, shift.UserID)
+	}
+
+	names := make(map[uint]string)
+
+	if len(userIDs) > 0 {
+		var users []models.User
+		if err := db.DB.Select("id, name").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve users"})
+			return nil, nil, false
+		}
+		for _, user := range users {
+			names[user.ID] = user.Name
+		}
+	}
+
+	if shifts == nil {
+		shifts = []oncall.Shift{}
+	}
+
+	return shifts, names, true
+}
+
+// prepareScheduleLayers validates the timezone and rotation layers of a
+// schedule. Participants must own or be members of the project.
+func prepareScheduleLayers(project models.Project, timezone string, layers []types.RotationLayer) (string, datatypes.JSON, error) {
+	if timezone == "" {
+		timezone = "UTC"
+	}
+
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return "", nil, fmt.Errorf("Unknown timezone '%s'", timezone)
+	}
+
+	if err := oncall.ValidateLayers(layers); err != nil {
+		return "", nil, fmt.Errorf("Invalid rotation layers: %v", err)
+	}
+
+	users, err := projectUsers(project)
+	if err != nil {
+		return "", nil, err
+	}
+
+	for i, layer := range layers {
+		for _, userID := range layer.Participants {
+			if !users[userID] {
+				return "", nil, fmt.Errorf("Layer %d includes user %d who is not a member of the project", i+1, userID)
+			}
+		}
+	}
+
+	layersJSON, err := json.Marshal(layers)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return timezone, datatypes.JSON(layersJSON), nil
+}
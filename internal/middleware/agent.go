@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/monocle-dev/monocle/db"
+	"github.com/monocle-dev/monocle/internal/auth"
+	"github.com/monocle-dev/monocle/internal/models"
+	"github.com/monocle-dev/monocle/internal/types"
+)
+
+func AgentAuthMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		header := ctx.GetHeader("Authorization")
+		token, found := strings.CutPrefix(header, "Bearer ")
+
+		if !found || token == "" {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Agent token is required"})
+			return
+		}
+
+		var agent models.Agent
+
+		if err := db.DB.Where("token_hash = ?", auth.HashToken(token)).First(&agent).Error; err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid agent token"})
+			return
+		}
+
+		now := time.Now()
+		db.DB.Model(&agent).UpdateColumn("last_seen_at", now)
+		agent.LastSeenAt = &now
+
+		ctx.Set(types.ContextAgentKey, agent)
+		ctx.Next()
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/monocle-dev/monocle/db"
+	"github.com/monocle-dev/monocle/internal/auth"
+	"github.com/monocle-dev/monocle/internal/models"
+	"github.com/monocle-dev/monocle/internal/types"
+)
+
+func AlertSourceAuthMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		header := ctx.GetHeader("Authorization")
+		token, found := strings.CutPrefix(header, "Bearer ")
+
+		if !found || token == "" {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Alert source token is required"})
+			return
+		}
+
+		var source models.AlertSource
+
+		if err := db.DB.Where("token_hash = ?", auth.HashToken(token)).First(&source).Error; err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid alert source token"})
+			return
+		}
+
+		ctx.Set(types.ContextAlertSourceKey, source)
+		ctx.Next()
+	}
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+type Agent struct {
+	BaseModel
+
+	ProjectID  uint       `gorm:"not null;index" json:"project_id"`
+	Name       string     `gorm:"not null" json:"name"`
+	Location   string     `gorm:"not null;index" json:"location"` // e.g., "eu-west", "us-east"
+	TokenHash  string     `gorm:"not null;uniqueIndex" json:"-"`
+	LastSeenAt *time.Time `json:"last_seen_at"`
+
+	// Relationships
+	Project Project `gorm:"foreignKey:ProjectID;constraint:OnUpdate:Cascade,OnDelete:CASCADE" json:"-"`
+}
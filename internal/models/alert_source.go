@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// AlertSource is another system that opens and resolves incidents of a
+// project by sending alerts with its token
+type AlertSource struct {
+	BaseModel
+
+	ProjectID   uint       `gorm:"not null;index" json:"project_id"`
+	Name        string     `gorm:"not null" json:"name"`
+	Severity    string     `gorm:"not null;default:medium" json:"severity"` // Used for alerts without a known severity
+	TokenHash   string     `gorm:"not null;uniqueIndex" json:"-"`
+	LastAlertAt *time.Time `json:"last_alert_at"`
+
+	// Relationships
+	Project Project `gorm:"foreignKey:ProjectID;constraint:OnUpdate:Cascade,OnDelete:CASCADE" json:"-"`
+}
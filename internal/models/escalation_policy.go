@@ -0,0 +1,12 @@
+package models
+
+import "gorm.io/datatypes"
+
+// EscalationPolicy pages its levels one after the other until an incident is acknowledged
+type EscalationPolicy struct {
+	BaseModel
+
+	ProjectID uint           `gorm:"not null;index" json:"project_id"`
+	Name      string         `gorm:"not null" json:"name"`
+	Levels    datatypes.JSON `gorm:"type:jsonb;not null" json:"levels"` // Ordered []types.EscalationLevel
+}
@@ -4,17 +4,68 @@ import (
 	"time"
 )
 
+const (
+	IncidentKindDown     = "down"
+	IncidentKindDegraded = "degraded"
+)
+
+const (
+	IncidentStatusActive       = "active"
+	IncidentStatusImpacted     = "impacted" // Caused by an open incident of a parent monitor
+	IncidentStatusAcknowledged = "acknowledged"
+	IncidentStatusResolved     = "resolved"
+)
+
+const (
+	IncidentSourceCheck  = "check" // Opened by the scheduler for a failing monitor
+	IncidentSourceManual = "manual"
+	IncidentSourceAlert  = "alert" // Opened by an alert from another system
+)
+
+// OpenIncidentStatuses lists the statuses of incidents that are not resolved yet
+var OpenIncidentStatuses = []string{IncidentStatusActive, IncidentStatusImpacted, IncidentStatusAcknowledged}
+
 type Incident struct {
 	BaseModel
 
-	MonitorID   uint   `gorm:"not null;index"`
-	Status      string `gorm:"not null"` // e.g., "Active", "Resolved"
+	ProjectID   uint   `gorm:"not null;index"`
+	MonitorID   *uint  `gorm:"index"`                   // Nil for manual and alert incidents
+	Source      string `gorm:"not null;default:check"`  // "check", "manual" or "alert"
+	Kind        string `gorm:"not null;default:down"`   // "down" or "degraded"
+	Severity    string `gorm:"not null;default:medium"` // "low", "medium", "high" or "critical"
+	Status      string `gorm:"not null"`                // "active", "impacted", "acknowledged" or "resolved"
 	Title       string `gorm:"not null"`
 	Description string
 	StartedAt   *time.Time
 	ResolvedAt  *time.Time
 
+	ParentIncidentID *uint `gorm:"index"` // Root cause incident of an impacted incident
+
+	AcknowledgedAt   *time.Time
+	AcknowledgedByID *uint `gorm:"index"`
+	ResolvedByID     *uint `gorm:"index"` // Set when a user resolved the incident by hand
+
+	SeverityOverridden bool `gorm:"not null;default:false"` // A user set the severity, rules no longer escalate it
+
+	EscalationPolicyID *uint      `gorm:"index"`
+	EscalationLevel    int        `gorm:"not null;default:0"` // Levels of the policy paged so far
+	NextEscalationAt   *time.Time `gorm:"index"`              // When the next level is due, nil once the policy is exhausted
+
+	AlertSourceID *uint  `gorm:"index"`
+	DedupKey      string `gorm:"index"` // Alerts with the same key from the same source share an open incident
+
+	RemindersSent  int        `gorm:"not null;default:0"`
+	NextReminderAt *time.Time `gorm:"index"` // Nil when reminders are off or the limit is reached
+
 	// Relationships
-	Monitor       Monitor        `gorm:"foreignKey:MonitorID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
-	Notifications []Notification `gorm:"foreignKey:IncidentID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
+	Project          Project           `gorm:"foreignKey:ProjectID;constraint:OnUpdate:Cascade,OnDelete:CASCADE" json:"-"`
+	Monitor          Monitor           `gorm:"foreignKey:MonitorID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
+	LinkedMonitors   []Monitor         `gorm:"many2many:incident_monitors;constraint:OnUpdate:Cascade,OnDelete:CASCADE" json:"-"` // Monitors a manual incident is about
+	AlertSource      *AlertSource      `gorm:"foreignKey:AlertSourceID;constraint:OnUpdate:Cascade,OnDelete:SET NULL" json:"-"`
+	ParentIncident   *Incident         `gorm:"foreignKey:ParentIncidentID;constraint:OnUpdate:Cascade,OnDelete:SET NULL" json:"-"`
+	AcknowledgedBy   *User             `gorm:"foreignKey:AcknowledgedByID;constraint:OnUpdate:Cascade,OnDelete:SET NULL" json:"-"`
+	ResolvedBy       *User             `gorm:"foreignKey:ResolvedByID;constraint:OnUpdate:Cascade,OnDelete:SET NULL" json:"-"`
+	EscalationPolicy *EscalationPolicy `gorm:"foreignKey:EscalationPolicyID;constraint:OnUpdate:Cascade,OnDelete:SET NULL" json:"-"`
+	Events           []IncidentEvent   `gorm:"foreignKey:IncidentID;constraint:OnUpdate:Cascade,OnDelete:CASCADE" json:"-"`
+	Notifications    []Notification    `gorm:"foreignKey:IncidentID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
 }
@@ -0,0 +1,31 @@
+package models
+
+// Incident timeline event types
+const (
+	IncidentEventOpened       = "opened"
+	IncidentEventImpacted     = "impacted"
+	IncidentEventCheckFailed  = "check_failed"
+	IncidentEventDeduplicated = "deduplicated" // A repeated alert matched the open incident
+	IncidentEventNotification = "notification"
+	IncidentEventAcknowledged = "acknowledged"
+	IncidentEventSeverity     = "severity_changed"
+	IncidentEventEscalated    = "escalated"
+	IncidentEventReminder     = "reminder"
+	IncidentEventResolved     = "resolved"
+	IncidentEventReopened     = "reopened"
+	IncidentEventComment      = "comment"
+)
+
+// IncidentEvent is an entry in the timeline of an incident
+type IncidentEvent struct {
+	BaseModel
+
+	IncidentID uint   `gorm:"not null;index" json:"incident_id"`
+	Type       string `gorm:"not null" json:"type"`
+	UserID     *uint  `gorm:"index" json:"user_id"` // Set for events caused by a user
+	Message    string `json:"message"`
+
+	// Relationships
+	Incident Incident `gorm:"foreignKey:IncidentID;constraint:OnUpdate:Cascade,OnDelete:CASCADE" json:"-"`
+	User     *User    `gorm:"foreignKey:UserID;constraint:OnUpdate:Cascade,OnDelete:SET NULL" json:"-"`
+}
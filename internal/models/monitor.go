@@ -1,6 +1,9 @@
 package models
 
 import (
+	"time"
+
+	"github.com/lib/pq"
 	"gorm.io/datatypes"
 )
 
@@ -9,13 +12,34 @@ type Monitor struct {
 
 	ProjectID uint           `gorm:"not null;index"` // Foreign key to the Project
 	Name      string         `gorm:"not null"`
-	Type      string         `gorm:"not null"` // "http", "ping", "database", etc.
+	Type      string         `gorm:"not null"` // "http", "ping", "database", "group", etc.
 	Status    string         `gorm:"not null"` // "active", "inactive", "error", etc.
 	Interval  int            `gorm:"not null"` // Interval in seconds for the monitor to run
 	Config    datatypes.JSON `gorm:"type:jsonb"`
+	Locations pq.StringArray `gorm:"type:text[]"`        // Locations that run the check, empty means the server only
+	Quorum    int            `gorm:"not null;default:1"` // Failing locations needed to open an incident
+
+	BackoffEnabled     bool `gorm:"not null;default:false"` // Stretch the interval while an incident stays open
+	BackoffMaxInterval int  // Upper bound in seconds for the stretched interval
+	EffectiveInterval  int  `gorm:"not null;default:0"` // Interval currently used in seconds, 0 when not backed off
+
+	WarningThreshold  int            // Response time in ms above which a check is degraded, 0 disables
+	CriticalThreshold int            // Response time in ms above which a check fails, 0 disables
+	MetricThresholds  datatypes.JSON `gorm:"type:jsonb"`             // Limits for other checker metrics, keyed by metric name
+	DegradedIncidents bool           `gorm:"not null;default:false"` // Open a degraded incident while checks are degraded
+
+	DefaultSeverity string         `gorm:"not null;default:medium"` // Severity of new down incidents
+	SeverityRules   datatypes.JSON `gorm:"type:jsonb"`              // Rules that escalate the severity of open incidents
+
+	EscalationPolicyID *uint `gorm:"index"` // Overrides the project's escalation policy
+
+	Tags                pq.StringArray `gorm:"type:text[]"` // Labels notification rules can filter on
+	SSLExpiryNotifiedAt *time.Time     // Last expiring certificate notification, cleared once renewed
+	RunRequestedAt      *time.Time     // A manual check asked for on a follower, cleared once the leader picks it up
 
 	// Relationships
-	Project       Project        `gorm:"foreignKey:ProjectID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
-	MonitorChecks []MonitorCheck `gorm:"foreignKey:MonitorID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
-	Incidents     []Incident     `gorm:"foreignKey:MonitorID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
+	Project          Project           `gorm:"foreignKey:ProjectID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
+	MonitorChecks    []MonitorCheck    `gorm:"foreignKey:MonitorID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
+	Incidents        []Incident        `gorm:"foreignKey:MonitorID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
+	EscalationPolicy *EscalationPolicy `gorm:"foreignKey:EscalationPolicyID;constraint:OnUpdate:Cascade,OnDelete:SET NULL"`
 }
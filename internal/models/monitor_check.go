@@ -2,16 +2,20 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/datatypes"
 )
 
 type MonitorCheck struct {
 	BaseModel
 
 	MonitorID    uint   `gorm:"not null;index"`
+	Location     string `gorm:"not null;default:local;index"` // Where the check ran
 	Status       string `gorm:"not null"`
 	ResponseTime int    `gorm:"not null"`
 	Message      string
-	CheckedAt    time.Time `gorm:"not null"`
+	Metrics      datatypes.JSON `gorm:"type:jsonb"` // Measurements reported by the checker
+	CheckedAt    time.Time      `gorm:"not null"`
 
 	// Relationships
 	Monitor Monitor `gorm:"foreignKey:MonitorID;constraint:OnUpdate:Cascade,OnDelete:CASCADE" json:"-"`
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// MonitorDependency records that a monitor depends on a parent monitor, so the
+// parent's outages explain failures of the monitor
+type MonitorDependency struct {
+	MonitorID uint      `gorm:"primaryKey" json:"monitor_id"`
+	ParentID  uint      `gorm:"primaryKey;index" json:"parent_id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relationships
+	Monitor Monitor `gorm:"foreignKey:MonitorID;constraint:OnUpdate:Cascade,OnDelete:CASCADE" json:"-"`
+	Parent  Monitor `gorm:"foreignKey:ParentID;constraint:OnUpdate:Cascade,OnDelete:CASCADE" json:"-"`
+}
@@ -2,19 +2,48 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Notification delivery statuses
+const (
+	NotificationStatusPending = "pending" // Waiting in the outbox for its next attempt
+	NotificationStatusSent    = "sent"
+	NotificationStatusFailed  = "failed" // Given up on
 )
 
+// Notification is the delivery of an event over a channel. It is queued in the
+// same transaction as the change it announces and delivered by the outbox worker.
 type Notification struct {
 	BaseModel
 
-	IncidentID uint   `gorm:"not null;index"`
-	UserID     uint   `gorm:"not null;index"`
+	ProjectID  uint   `gorm:"not null;index"`
+	IncidentID *uint  `gorm:"index"` // Nil for monitor events such as expiring certificates
+	MonitorID  *uint  `gorm:"index"`
+	RuleID     *uint  `gorm:"index"` // Nil for the project's own webhooks and escalation pages
+	UserID     *uint  `gorm:"index"` // The notified user, if any
+	EventType  string `gorm:"not null;default:''"`
 	Channel    string `gorm:"not null"`
-	Status     string `gorm:"not null"`
+	Status     string `gorm:"not null;index"` // "pending", "sent" or "failed"
 	Message    string
 	SentAt     *time.Time
 
+	// Outbox state
+	Attempts      int        `gorm:"not null;default:0"`
+	NextAttemptAt *time.Time `gorm:"index"` // Nil once the notification was sent or given up on
+	LastError     string
+	Payload       datatypes.JSON `gorm:"type:jsonb"` // Details of the event besides its incident and monitor
+
+	// Grouping, notifications with the same key that are due together are sent as one message
+	Destination   string `gorm:"not null;default:'';index"` // Hash of where the notification is sent, those with the same one share a rate limit
+	GroupKey      string `gorm:"not null;default:'';index"` // Empty for notifications that are always sent on their own
+	GroupedIntoID *uint  `gorm:"index"`                     // The notification whose message included this one
+
 	// Relationships
-	Incident Incident `gorm:"foreignKey:IncidentID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
-	User     User     `gorm:"foreignKey:UserID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
+	Project  Project           `gorm:"foreignKey:ProjectID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
+	Incident *Incident         `gorm:"foreignKey:IncidentID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
+	Monitor  *Monitor          `gorm:"foreignKey:MonitorID;constraint:OnUpdate:Cascade,OnDelete:SET NULL"`
+	Rule     *NotificationRule `gorm:"foreignKey:RuleID;constraint:OnUpdate:Cascade,OnDelete:SET NULL"`
+	User     *User             `gorm:"foreignKey:UserID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
 }
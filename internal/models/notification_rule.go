@@ -1,20 +1,65 @@
 package models
 
 import (
+	"github.com/lib/pq"
 	"gorm.io/datatypes"
 )
 
+// Events notification rules can be triggered by
+const (
+	TriggerIncidentCreated      = "incident_created"
+	TriggerIncidentResolved     = "incident_resolved"
+	TriggerIncidentAcknowledged = "incident_acknowledged"
+	TriggerIncidentEscalated    = "incident_escalated"
+	TriggerIncidentReminder     = "incident_reminder"
+	TriggerMonitorDegraded      = "monitor_degraded" // A degraded incident was opened
+	TriggerSSLExpiring          = "ssl_expiring"
+)
+
+// Digest schedules of notification rules, in UTC
+const (
+	DigestHourly = "hourly"
+	DigestDaily  = "daily"
+)
+
+// NotificationTriggers lists every trigger type
+var NotificationTriggers = []string{
+	TriggerIncidentCreated,
+	TriggerIncidentResolved,
+	TriggerIncidentAcknowledged,
+	TriggerIncidentEscalated,
+	TriggerIncidentReminder,
+	TriggerMonitorDegraded,
+	TriggerSSLExpiring,
+}
+
 type NotificationRule struct {
 	BaseModel
 
 	ProjectID   uint           `gorm:"not null;index"`
-	UserID      uint           `gorm:"not null;index"`
+	UserID      *uint          `gorm:"index"` // Who the rule notifies, nil when it notifies a schedule
+	ScheduleID  *uint          `gorm:"index"` // Notifies whoever is on call for the schedule when a notification is delivered
+	Name        string         `gorm:"not null;default:''"`
 	TriggerType string         `gorm:"not null"` // e.g., "incident_created", "incident_resolved"
-	Channel     string         `gorm:"not null"` // e.g., "email", "slack", "webhook"
+	Channel     string         `gorm:"not null"` // A channel of services.notifiers, e.g. "discord", "email" or "pagerduty"
 	IsActive    bool           `gorm:"default:true"`
-	Config      datatypes.JSON `gorm:"type:jsonb"`
+	Config      datatypes.JSON `gorm:"type:jsonb"` // Settings of the channel
+
+	// Filters, empty ones match every event
+	MonitorIDs  pq.Int64Array  `gorm:"type:bigint[]"` // Events about one of these monitors
+	Tags        pq.StringArray `gorm:"type:text[]"`   // Events about a monitor with one of these tags
+	MinSeverity string         // Incidents of at least this severity
+
+	// Go templates replacing the title and body of chat, push and email
+	// messages, the project's templates when empty
+	TitleTemplate string `gorm:"type:text"`
+	BodyTemplate  string `gorm:"type:text"`
+
+	Digest    string `gorm:"not null;default:''"` // "hourly" or "daily" to send a summary of the events instead, empty sends them right away
+	RateLimit int    `gorm:"not null;default:0"`  // Messages per minute at most, 0 uses the channel's default
 
 	// Relationships
-	Project Project `gorm:"foreignKey:ProjectID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
-	User    User    `gorm:"foreignKey:UserID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
+	Project  Project         `gorm:"foreignKey:ProjectID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
+	User     *User           `gorm:"foreignKey:UserID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
+	Schedule *OnCallSchedule `gorm:"foreignKey:ScheduleID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
 }
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// OnCallSchedule decides who is on call from rotation layers and overrides
+type OnCallSchedule struct {
+	BaseModel
+
+	ProjectID uint           `gorm:"not null;index" json:"project_id"`
+	Name      string         `gorm:"not null" json:"name"`
+	Timezone  string         `gorm:"not null;default:UTC" json:"timezone"` // IANA name, handoffs follow its wall clock
+	Layers    datatypes.JSON `gorm:"type:jsonb;not null" json:"layers"`    // []types.RotationLayer, later layers take precedence
+
+	// Relationships
+	Project   Project          `gorm:"foreignKey:ProjectID;constraint:OnUpdate:Cascade,OnDelete:CASCADE" json:"-"`
+	Overrides []OnCallOverride `gorm:"foreignKey:ScheduleID;constraint:OnUpdate:Cascade,OnDelete:CASCADE" json:"overrides,omitempty"`
+}
+
+// OnCallOverride puts a user on call in place of the rotation, e.g. for a swap
+type OnCallOverride struct {
+	BaseModel
+
+	ScheduleID uint      `gorm:"not null;index" json:"schedule_id"`
+	UserID     uint      `gorm:"not null;index" json:"user_id"`
+	StartsAt   time.Time `gorm:"not null" json:"starts_at"`
+	EndsAt     time.Time `gorm:"not null" json:"ends_at"`
+
+	// Relationships
+	Schedule OnCallSchedule `gorm:"foreignKey:ScheduleID;constraint:OnUpdate:Cascade,OnDelete:CASCADE" json:"-"`
+	User     User           `gorm:"foreignKey:UserID;constraint:OnUpdate:Cascade,OnDelete:CASCADE" json:"-"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	PostmortemStatusDraft     = "draft"
+	PostmortemStatusPublished = "published"
+)
+
+const (
+	ActionItemStatusOpen = "open"
+	ActionItemStatusDone = "done"
+)
+
+// Postmortem is the write-up of an incident
+type Postmortem struct {
+	BaseModel
+
+	IncidentID          uint           `gorm:"not null;uniqueIndex" json:"incident_id"`
+	Title               string         `gorm:"not null" json:"title"`
+	Content             string         `gorm:"type:text" json:"content"` // Markdown
+	ContributingFactors pq.StringArray `gorm:"type:text[]" json:"contributing_factors"`
+	Status              string         `gorm:"not null;default:draft" json:"status"` // "draft" or "published"
+	AuthorID            *uint          `gorm:"index" json:"author_id"`
+
+	// Relationships
+	Incident    Incident               `gorm:"foreignKey:IncidentID;constraint:OnUpdate:Cascade,OnDelete:CASCADE" json:"-"`
+	Author      *User                  `gorm:"foreignKey:AuthorID;constraint:OnUpdate:Cascade,OnDelete:SET NULL" json:"-"`
+	ActionItems []PostmortemActionItem `gorm:"foreignKey:PostmortemID;constraint:OnUpdate:Cascade,OnDelete:CASCADE" json:"action_items"`
+}
+
+// PostmortemActionItem is a follow-up task that came out of a postmortem
+type PostmortemActionItem struct {
+	BaseModel
+
+	PostmortemID uint       `gorm:"not null;index" json:"postmortem_id"`
+	Description  string     `gorm:"not null" json:"description"`
+	OwnerID      *uint      `gorm:"index" json:"owner_id"`
+	DueDate      *time.Time `json:"due_date"`
+	Status       string     `gorm:"not null;default:open;index" json:"status"` // "open" or "done"
+	CompletedAt  *time.Time `json:"completed_at"`
+
+	// Relationships
+	Postmortem Postmortem `gorm:"foreignKey:PostmortemID;constraint:OnUpdate:Cascade,OnDelete:CASCADE" json:"-"`
+	Owner      *User      `gorm:"foreignKey:OwnerID;constraint:OnUpdate:Cascade,OnDelete:SET NULL" json:"-"`
+}
@@ -9,9 +9,30 @@ type Project struct {
 	DiscordWebhook string
 	SlackWebhook   string
 
+	NotifyMinSeverity string `gorm:"not null;default:low"` // Incidents below this severity send no notifications
+
+	ReminderInterval int     `gorm:"not null;default:0"` // Minutes before the first reminder of an unacknowledged incident, 0 disables reminders
+	ReminderBackoff  float64 `gorm:"not null;default:1"` // Each following reminder waits this many times longer
+	ReminderMaxCount int     `gorm:"not null;default:0"` // Reminders sent per incident at most, 0 means no limit
+
+	PostmortemTemplate string `gorm:"type:text"` // Go template for new postmortems, the built-in template when empty
+
+	// Go templates replacing the title and body of chat, push and email
+	// messages, the built-in text when empty
+	NotificationTitleTemplate string `gorm:"type:text"`
+	NotificationBodyTemplate  string `gorm:"type:text"`
+	NotificationUsername      string // Name chat messages are posted as, "Monocle Monitor" when empty
+	NotificationAvatarURL     string // Avatar of chat messages, Monocle's logo when empty
+
+	EscalationPolicyID *uint `gorm:"index"` // Default policy for incidents of the project's monitors, cleared by hand when the policy is deleted
+
 	// Relationships
 	Owner              User                `gorm:"foreignKey:OwnerID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
 	ProjectMemberships []ProjectMembership `gorm:"foreignKey:ProjectID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
 	Monitors           []Monitor           `gorm:"foreignKey:ProjectID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
 	NotificationRules  []NotificationRule  `gorm:"foreignKey:ProjectID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
+	Agents             []Agent             `gorm:"foreignKey:ProjectID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
+	EscalationPolicies []EscalationPolicy  `gorm:"foreignKey:ProjectID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
+	OnCallSchedules    []OnCallSchedule    `gorm:"foreignKey:ProjectID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
+	AlertSources       []AlertSource       `gorm:"foreignKey:ProjectID;constraint:OnUpdate:Cascade,OnDelete:CASCADE"`
 }
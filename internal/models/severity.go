@@ -0,0 +1,27 @@
+package models
+
+// Incident severities, from least to most urgent
+const (
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+var severityRanks = map[string]int{
+	SeverityLow:      1,
+	SeverityMedium:   2,
+	SeverityHigh:     3,
+	SeverityCritical: 4,
+}
+
+// SeverityRank orders severities, unknown severities rank lowest
+func SeverityRank(severity string) int {
+	return severityRanks[severity]
+}
+
+// ValidSeverity reports whether severity is one of the known severities
+func ValidSeverity(severity string) bool {
+	_, ok := severityRanks[severity]
+	return ok
+}
@@ -0,0 +1,109 @@
+package monitors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/monocle-dev/monocle/internal/types"
+)
+
+// Metrics are measurements reported by a check, keyed by metric name
+type Metrics map[string]float64
+
+const (
+	MetricResponseTime     = "response_time_ms"
+	MetricStatusCode       = "status_code"
+	MetricSSLDaysRemaining = "ssl_days_remaining"
+)
+
+// Checker runs a single check for a parsed monitor config
+type Checker func(ctx context.Context) (Metrics, error)
+
+// NewChecker parses a monitor config and returns the check for its type
+func NewChecker(monitorType string, config []byte) (Checker, error) {
+	switch monitorType {
+	case "http":
+		var cfg types.HttpConfig
+		if err := json.Unmarshal(config, &cfg); err != nil {
+			return nil, fmt.Errorf("invalid HTTP config: %w", err)
+		}
+		return func(ctx context.Context) (Metrics, error) { return GetHTTP(ctx, &cfg) }, nil
+	case "dns":
+		var cfg types.DNSConfig
+		if err := json.Unmarshal(config, &cfg); err != nil {
+			return nil, fmt.Errorf("invalid DNS config: %w", err)
+		}
+		return func(ctx context.Context) (Metrics, error) { return nil, CheckDNS(ctx, &cfg) }, nil
+	case "database":
+		var cfg types.DatabaseConfig
+		if err := json.Unmarshal(config, &cfg); err != nil {
+			return nil, fmt.Errorf("invalid Database config: %w", err)
+		}
+		return func(ctx context.Context) (Metrics, error) { return nil, CheckDatabase(ctx, &cfg) }, nil
+	default:
+		return nil, fmt.Errorf("unsupported monitor type: %s", monitorType)
+	}
+}
+
+// ValidateConfig checks that a monitor config has the fields its type needs
+func ValidateConfig(monitorType string, config []byte) error {
+	// Group monitors have no check of their own, the scheduler evaluates their members
+	if monitorType == "group" {
+		var cfg types.GroupConfig
+		if err := json.Unmarshal(config, &cfg); err != nil {
+			return fmt.Errorf("invalid group config: %w", err)
+		}
+		return validateGroupConfig(cfg)
+	}
+
+	if _, err := NewChecker(monitorType, config); err != nil {
+		return err
+	}
+
+	switch monitorType {
+	case "http":
+		var cfg types.HttpConfig
+		_ = json.Unmarshal(config, &cfg)
+
+		parsedURL, err := url.Parse(cfg.URL)
+		if err != nil || parsedURL.Host == "" || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+			return errors.New("url must be an absolute http or https URL")
+		}
+
+		if cfg.ExpectedStatus < 100 || cfg.ExpectedStatus > 599 {
+			return errors.New("expected_status must be a valid HTTP status code")
+		}
+	case "dns":
+		var cfg types.DNSConfig
+		_ = json.Unmarshal(config, &cfg)
+
+		if cfg.Domain == "" {
+			return errors.New("domain is required")
+		}
+
+		switch strings.ToUpper(cfg.RecordType) {
+		case "A", "AAAA", "CNAME", "MX", "TXT", "NS":
+		default:
+			return errors.New("unsupported DNS record type: " + cfg.RecordType)
+		}
+	case "database":
+		var cfg types.DatabaseConfig
+		_ = json.Unmarshal(config, &cfg)
+
+		switch cfg.Type {
+		case "postgres", "postgresql", "mysql":
+		default:
+			return fmt.Errorf("unsupported database type: %s", cfg.Type)
+		}
+
+		if cfg.Host == "" {
+			return errors.New("host is required")
+		}
+	}
+
+	return nil
+}
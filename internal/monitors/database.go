@@ -13,14 +13,14 @@ import (
 	_ "github.com/lib/pq"
 )
 
-func CheckDatabase(config *types.DatabaseConfig) error {
+func CheckDatabase(parentCtx context.Context, config *types.DatabaseConfig) error {
 	timeout := config.Timeout
 
 	if timeout == 0 {
 		timeout = 10
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	ctx, cancel := context.WithTimeout(parentCtx, time.Duration(timeout)*time.Second)
 	defer cancel()
 
 	var dsn string
@@ -11,14 +11,14 @@ import (
 	"github.com/monocle-dev/monocle/internal/types"
 )
 
-func CheckDNS(config *types.DNSConfig) error {
+func CheckDNS(parentCtx context.Context, config *types.DNSConfig) error {
 	timeout := config.Timeout
 
 	if timeout == 0 {
 		timeout = 5 // 5 seconds timeout by default
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	ctx, cancel := context.WithTimeout(parentCtx, time.Duration(timeout)*time.Second)
 	defer cancel()
 
 	resolver := &net.Resolver{}
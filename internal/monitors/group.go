@@ -0,0 +1,105 @@
+package monitors
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/monocle-dev/monocle/internal/types"
+)
+
+// Group rules
+const (
+	GroupRuleAll        = "all"
+	GroupRuleAny        = "any"
+	GroupRuleAtLeast    = "at_least"
+	GroupRulePercentage = "percentage"
+)
+
+const (
+	MetricHealthyMembers = "healthy_members"
+	MetricTotalMembers   = "total_members"
+)
+
+// validateGroupConfig checks the rule of a group monitor against its distinct members
+func validateGroupConfig(cfg types.GroupConfig) error {
+	if len(cfg.Members) == 0 {
+		return errors.New("members are required")
+	}
+
+	members := make(map[uint]bool, len(cfg.Members))
+	for _, memberID := range cfg.Members {
+		members[memberID] = true
+	}
+
+	switch cfg.Rule {
+	case GroupRuleAll, GroupRuleAny:
+	case GroupRuleAtLeast:
+		if cfg.Min < 1 || cfg.Min > len(members) {
+			return errors.New("min must be between 1 and the number of members")
+		}
+	case GroupRulePercentage:
+		if cfg.Percentage <= 0 || cfg.Percentage > 100 {
+			return errors.New("percentage must be greater than 0 and at most 100")
+		}
+	default:
+		return fmt.Errorf("unsupported group rule: %s", cfg.Rule)
+	}
+
+	return nil
+}
+
+// EvaluateGroup derives the status of a group monitor from the statuses of its
+// active members. The group fails when its rule is not met and is degraded
+// while any member is failing or degraded.
+func EvaluateGroup(cfg types.GroupConfig, memberStatuses []string) (string, string, Metrics) {
+	total := len(memberStatuses)
+	healthy, degraded := 0, 0
+
+	for _, status := range memberStatuses {
+		switch status {
+		case StatusSuccess:
+			healthy++
+		case StatusDegraded:
+			healthy++
+			degraded++
+		}
+	}
+
+	metrics := Metrics{
+		MetricHealthyMembers: float64(healthy),
+		MetricTotalMembers:   float64(total),
+	}
+
+	if total == 0 {
+		return StatusSuccess, "", metrics
+	}
+
+	var met bool
+	var requirement string
+
+	switch cfg.Rule {
+	case GroupRuleAny:
+		met = healthy > 0
+		requirement = "at least one healthy member"
+	case GroupRuleAtLeast:
+		met = healthy >= cfg.Min
+		requirement = fmt.Sprintf("at least %d healthy members", cfg.Min)
+	case GroupRulePercentage:
+		met = float64(healthy)*100 >= cfg.Percentage*float64(total)
+		requirement = fmt.Sprintf("at least %.0f%% healthy members", cfg.Percentage)
+	default:
+		met = healthy == total
+		requirement = "all members healthy"
+	}
+
+	summary := fmt.Sprintf("%d of %d members healthy", healthy, total)
+
+	switch {
+	case !met:
+		return StatusFailure, summary + ", rule requires " + requirement, metrics
+	case healthy < total || degraded > 0:
+		return StatusDegraded, summary, metrics
+	default:
+		return StatusSuccess, "", metrics
+	}
+}
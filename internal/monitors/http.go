@@ -9,22 +9,30 @@ import (
 	"github.com/monocle-dev/monocle/internal/types"
 )
 
-func GetHTTP(config *types.HttpConfig) error {
+// GetHTTP requests the configured URL and reports the status code and, for
+// HTTPS, the days left until the certificate expires
+func GetHTTP(parentCtx context.Context, config *types.HttpConfig) (Metrics, error) {
+	timeout := config.Timeout
+
+	if timeout == 0 {
+		timeout = 10
+	}
+
 	client := &http.Client{
-		Timeout: time.Duration(config.Timeout) * time.Second,
+		Timeout: time.Duration(timeout) * time.Second,
 	}
 
 	req, err := http.NewRequest(config.Method, config.URL, nil)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	for key, value := range config.Headers {
 		req.Header.Add(key, value)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.Timeout)*time.Second)
+	ctx, cancel := context.WithTimeout(parentCtx, time.Duration(timeout)*time.Second)
 
 	defer cancel()
 
@@ -33,14 +41,21 @@ func GetHTTP(config *types.HttpConfig) error {
 	resp, err := client.Do(req)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	defer resp.Body.Close()
 
+	metrics := Metrics{MetricStatusCode: float64(resp.StatusCode)}
+
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		expiresIn := time.Until(resp.TLS.PeerCertificates[0].NotAfter)
+		metrics[MetricSSLDaysRemaining] = expiresIn.Hours() / 24
+	}
+
 	if resp.StatusCode != config.ExpectedStatus {
-		return errors.New("unexpected status code: " + resp.Status)
+		return metrics, errors.New("unexpected status code: " + resp.Status)
 	}
 
-	return nil
+	return metrics, nil
 }
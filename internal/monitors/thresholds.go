@@ -0,0 +1,105 @@
+package monitors
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/monocle-dev/monocle/internal/types"
+)
+
+const (
+	StatusSuccess  = "success"
+	StatusDegraded = "degraded"
+	StatusFailure  = "failure"
+)
+
+// EvaluateThresholds compares check metrics against their limits and returns
+// the resulting status with a reason when a limit is crossed. Critical limits
+// turn the check into a failure, warning limits into a degraded result.
+func EvaluateThresholds(thresholds map[string]types.MetricThreshold, metrics Metrics) (string, string) {
+	names := make([]string, 0, len(thresholds))
+	for name := range thresholds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	status, reason := StatusSuccess, ""
+
+	for _, name := range names {
+		threshold := thresholds[name]
+
+		value, ok := metrics[name]
+		if !ok {
+			continue
+		}
+
+		if threshold.Critical != nil && crosses(value, *threshold.Critical, threshold.Below) {
+			return StatusFailure, describeCrossing(name, value, *threshold.Critical, threshold.Below, "critical")
+		}
+
+		if status == StatusSuccess && threshold.Warning != nil && crosses(value, *threshold.Warning, threshold.Below) {
+			status = StatusDegraded
+			reason = describeCrossing(name, value, *threshold.Warning, threshold.Below, "warning")
+		}
+	}
+
+	return status, reason
+}
+
+// Thresholds merges the latency limits of a monitor, in milliseconds, into
+// its metric thresholds. A zero limit is not set.
+func Thresholds(warning, critical int, metric map[string]types.MetricThreshold) map[string]types.MetricThreshold {
+	thresholds := make(map[string]types.MetricThreshold, len(metric)+1)
+	for name, threshold := range metric {
+		thresholds[name] = threshold
+	}
+
+	if warning <= 0 && critical <= 0 {
+		return thresholds
+	}
+
+	latency := thresholds[MetricResponseTime]
+	if warning > 0 {
+		value := float64(warning)
+		latency.Warning = &value
+	}
+	if critical > 0 {
+		value := float64(critical)
+		latency.Critical = &value
+	}
+	latency.Below = false
+	thresholds[MetricResponseTime] = latency
+
+	return thresholds
+}
+
+// ValidateThresholds checks that every warning limit is crossed before its critical limit
+func ValidateThresholds(thresholds map[string]types.MetricThreshold) error {
+	for name, threshold := range thresholds {
+		if threshold.Warning == nil || threshold.Critical == nil {
+			continue
+		}
+		if threshold.Below && *threshold.Warning <= *threshold.Critical {
+			return fmt.Errorf("%s warning threshold must be above its critical threshold", name)
+		}
+		if !threshold.Below && *threshold.Warning >= *threshold.Critical {
+			return fmt.Errorf("%s warning threshold must be below its critical threshold", name)
+		}
+	}
+	return nil
+}
+
+func crosses(value, limit float64, below bool) bool {
+	if below {
+		return value < limit
+	}
+	return value > limit
+}
+
+func describeCrossing(name string, value, limit float64, below bool, level string) string {
+	comparison := "exceeds"
+	if below {
+		comparison = "is below"
+	}
+	return fmt.Sprintf("%s %.0f %s %s threshold %.0f", name, value, comparison, level, limit)
+}
@@ -0,0 +1,54 @@
+package oncall
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const icalTimeFormat = "20060102T150405Z"
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+// ICal renders shifts as an iCalendar document. names maps user IDs to the
+// names shown in the events, uidPrefix keeps event UIDs unique per schedule.
+func ICal(calendarName, uidPrefix string, shifts []Shift, names map[uint]string) string {
+	var b strings.Builder
+	now := time.Now().UTC().Format(icalTimeFormat)
+
+	line := func(format string, args ...interface{}) {
+		b.WriteString(fmt.Sprintf(format, args...))
+		b.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//Monocle//On-call schedule//EN")
+	line("CALSCALE:GREGORIAN")
+	line("X-WR-CALNAME:%s", icalEscaper.Replace(calendarName))
+
+	for _, shift := range shifts {
+		name := names[shift.UserID]
+		if name == "" {
+			name = fmt.Sprintf("User %d", shift.UserID)
+		}
+
+		description := "Layer: " + shift.Layer
+		if shift.Override {
+			description = "Override"
+		}
+
+		line("BEGIN:VEVENT")
+		line("UID:%s-%d@monocle", uidPrefix, shift.Start.Unix())
+		line("DTSTAMP:%s", now)
+		line("DTSTART:%s", shift.Start.UTC().Format(icalTimeFormat))
+		line("DTEND:%s", shift.End.UTC().Format(icalTimeFormat))
+		line("SUMMARY:%s", icalEscaper.Replace("On call: "+name))
+		line("DESCRIPTION:%s", icalEscaper.Replace(description))
+		line("END:VEVENT")
+	}
+
+	line("END:VCALENDAR")
+
+	return b.String()
+}
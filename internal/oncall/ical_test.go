@@ -0,0 +1,48 @@
+package oncall
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestICal(t *testing.T) {
+	cet := time.FixedZone("CET", 3600)
+
+	shifts := []Shift{
+		{UserID: 4, Start: time.Date(2026, 1, 5, 9, 0, 0, 0, cet), End: time.Date(2026, 1, 12, 9, 0, 0, 0, cet), Layer: "Weekly"},
+		{UserID: 8, Start: time.Date(2026, 1, 12, 9, 0, 0, 0, cet), End: time.Date(2026, 1, 12, 18, 0, 0, 0, cet), Override: true},
+	}
+
+	calendar := ICal("Primary, EU", "schedule-2", shifts, map[uint]string{4: "Ada; Ops"})
+
+	if !strings.HasSuffix(calendar, "END:VCALENDAR\r\n") {
+		t.Errorf("calendar does not end with END:VCALENDAR and CRLF")
+	}
+	if strings.Contains(strings.ReplaceAll(calendar, "\r\n", ""), "\n") {
+		t.Errorf("calendar has lines not ended by CRLF")
+	}
+
+	for _, line := range []string{
+		"BEGIN:VCALENDAR",
+		`X-WR-CALNAME:Primary\, EU`,
+		"UID:schedule-2-1767600000@monocle",
+		"DTSTART:20260105T080000Z",
+		"DTEND:20260112T080000Z",
+		`SUMMARY:On call: Ada\; Ops`,
+		"DESCRIPTION:Layer: Weekly",
+		"UID:schedule-2-1768204800@monocle",
+		"DTSTART:20260112T080000Z",
+		"DTEND:20260112T170000Z",
+		"SUMMARY:On call: User 8", // No name known
+		"DESCRIPTION:Override",
+	} {
+		if !strings.Contains(calendar, line+"\r\n") {
+			t.Errorf("calendar is missing line %q:\n%s", line, calendar)
+		}
+	}
+
+	if got := strings.Count(calendar, "BEGIN:VEVENT"); got != len(shifts) {
+		t.Errorf("calendar has %d events, want %d", got, len(shifts))
+	}
+}
@@ -0,0 +1,210 @@
+package oncall
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/monocle-dev/monocle/internal/types"
+)
+
+// Schedule is an on-call schedule ready to be evaluated
+type Schedule struct {
+	Location  *time.Location
+	Layers    []types.RotationLayer // Later layers take precedence over earlier ones
+	Overrides []Override            // Later overrides take precedence over earlier ones
+}
+
+// Override puts a user on call in place of the rotation layers
+type Override struct {
+	UserID uint
+	Start  time.Time
+	End    time.Time
+}
+
+// Shift is a stretch of time during which one user is on call
+type Shift struct {
+	UserID   uint      `json:"user_id"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Layer    string    `json:"layer,omitempty"` // Name of the layer, empty for overrides
+	Override bool      `json:"override"`
+}
+
+// ValidateLayers checks rotation layers and fills in the default shift length
+func ValidateLayers(layers []types.RotationLayer) error {
+	if len(layers) == 0 {
+		return errors.New("at least one rotation layer is required")
+	}
+
+	for i := range layers {
+		layer := &layers[i]
+
+		switch layer.Rotation {
+		case types.RotationDaily, types.RotationWeekly:
+		default:
+			return fmt.Errorf("layer %d: unsupported rotation: %s", i+1, layer.Rotation)
+		}
+
+		if layer.ShiftLength == 0 {
+			layer.ShiftLength = 1
+		}
+		if layer.ShiftLength < 0 {
+			return fmt.Errorf("layer %d: shift_length must be positive", i+1)
+		}
+
+		if layer.Start.IsZero() {
+			return fmt.Errorf("layer %d: start is required", i+1)
+		}
+		if layer.End != nil && !layer.End.After(layer.Start) {
+			return fmt.Errorf("layer %d: end must be after start", i+1)
+		}
+
+		if len(layer.Participants) == 0 {
+			return fmt.Errorf("layer %d: participants are required", i+1)
+		}
+	}
+
+	return nil
+}
+
+// At returns the shift covering t, or false when nobody is on call
+func (s Schedule) At(t time.Time) (Shift, bool) {
+	for i := len(s.Overrides) - 1; i >= 0; i-- {
+		override := s.Overrides[i]
+		if !t.Before(override.Start) && t.Before(override.End) {
+			return Shift{UserID: override.UserID, Start: override.Start, End: override.End, Override: true}, true
+		}
+	}
+
+	for i := len(s.Layers) - 1; i >= 0; i-- {
+		if shift, ok := s.layerShift(s.Layers[i], t); ok {
+			return shift, true
+		}
+	}
+
+	return Shift{}, false
+}
+
+// Timeline returns the shifts between from and to with overrides and layer
+// precedence applied. Shifts are cut to the requested range.
+func (s Schedule) Timeline(from, to time.Time) []Shift {
+	boundaries := []time.Time{from, to}
+
+	add := func(t time.Time) {
+		if t.After(from) && t.Before(to) {
+			boundaries = append(boundaries, t)
+		}
+	}
+
+	for _, override := range s.Overrides {
+		add(override.Start)
+		add(override.End)
+	}
+
+	for _, layer := range s.Layers {
+		add(layer.Start)
+		if layer.End != nil {
+			add(*layer.End)
+		}
+
+		k := s.handoffIndex(layer, from)
+		if k < 0 {
+			k = 0
+		}
+		for handoff := s.handoff(layer, k); handoff.Before(to); handoff = s.handoff(layer, k) {
+			add(handoff)
+			k++
+		}
+	}
+
+	sort.Slice(boundaries, func(i, j int) bool { return boundaries[i].Before(boundaries[j]) })
+
+	var shifts []Shift
+
+	for i := 0; i+1 < len(boundaries); i++ {
+		start, end := boundaries[i], boundaries[i+1]
+		if !start.Before(end) {
+			continue
+		}
+
+		shift, ok := s.At(start)
+		if !ok {
+			continue
+		}
+
+		if n := len(shifts); n > 0 {
+			last := &shifts[n-1]
+			if last.End.Equal(start) && last.UserID == shift.UserID && last.Layer == shift.Layer && last.Override == shift.Override {
+				last.End = end
+				continue
+			}
+		}
+
+		shifts = append(shifts, Shift{UserID: shift.UserID, Start: start, End: end, Layer: shift.Layer, Override: shift.Override})
+	}
+
+	return shifts
+}
+
+// layerShift returns the shift of a layer that covers t
+func (s Schedule) layerShift(layer types.RotationLayer, t time.Time) (Shift, bool) {
+	if len(layer.Participants) == 0 || t.Before(layer.Start) {
+		return Shift{}, false
+	}
+	if layer.End != nil && !t.Before(*layer.End) {
+		return Shift{}, false
+	}
+
+	k := s.handoffIndex(layer, t)
+	end := s.handoff(layer, k+1)
+	if layer.End != nil && layer.End.Before(end) {
+		end = *layer.End
+	}
+
+	return Shift{
+		UserID: layer.Participants[k%len(layer.Participants)],
+		Start:  s.handoff(layer, k),
+		End:    end,
+		Layer:  layer.Name,
+	}, true
+}
+
+// handoffIndex returns the number of the last handoff of a layer at or before t
+func (s Schedule) handoffIndex(layer types.RotationLayer, t time.Time) int {
+	k := int(t.Sub(layer.Start) / (time.Duration(shiftDays(layer)) * 24 * time.Hour))
+
+	// The estimate is off by one around daylight saving time changes
+	for !s.handoff(layer, k+1).After(t) {
+		k++
+	}
+	for s.handoff(layer, k).After(t) {
+		k--
+	}
+
+	return k
+}
+
+// handoff returns the time of the k-th handoff of a layer. Handoffs keep the
+// wall clock time of the layer start in the schedule's timezone.
+func (s Schedule) handoff(layer types.RotationLayer, k int) time.Time {
+	location := s.Location
+	if location == nil {
+		location = time.UTC
+	}
+
+	return layer.Start.In(location).AddDate(0, 0, k*shiftDays(layer))
+}
+
+func shiftDays(layer types.RotationLayer) int {
+	length := layer.ShiftLength
+	if length < 1 {
+		length = 1
+	}
+
+	if layer.Rotation == types.RotationWeekly {
+		return 7 * length
+	}
+	return length
+}
@@ -0,0 +1,237 @@
+package oncall
+
+import (
+	"testing"
+	"time"
+
+	"github.com/monocle-dev/monocle/internal/types"
+)
+
+func mustParse(t *testing.T, value string) time.Time {
+	t.Helper()
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestScheduleAt(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	layerEnd := mustParse(t, "2026-01-12T00:00:00Z")
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		at       string
+		userID   uint // 0 when nobody is on call
+		start    string
+		end      string
+	}{
+		{
+			name:     "before the first handoff",
+			schedule: Schedule{Layers: []types.RotationLayer{{Rotation: types.RotationDaily, Start: mustParse(t, "2026-01-01T09:00:00Z"), Participants: []uint{1, 2}}}},
+			at:       "2026-01-01T08:59:59Z",
+		},
+		{
+			name:     "daily handoff index wraps around the participants",
+			schedule: Schedule{Layers: []types.RotationLayer{{Rotation: types.RotationDaily, Start: mustParse(t, "2026-01-01T09:00:00Z"), Participants: []uint{1, 2, 3}}}},
+			at:       "2026-01-04T09:00:00Z",
+			userID:   1,
+			start:    "2026-01-04T09:00:00Z",
+			end:      "2026-01-05T09:00:00Z",
+		},
+		{
+			name:     "shift length of two days",
+			schedule: Schedule{Layers: []types.RotationLayer{{Rotation: types.RotationDaily, ShiftLength: 2, Start: mustParse(t, "2026-01-01T00:00:00Z"), Participants: []uint{1, 2}}}},
+			at:       "2026-01-04T23:00:00Z",
+			userID:   2,
+			start:    "2026-01-03T00:00:00Z",
+			end:      "2026-01-05T00:00:00Z",
+		},
+		{
+			name: "daily layer keeps 09:00 local time into summer time",
+			schedule: Schedule{Location: berlin, Layers: []types.RotationLayer{
+				{Rotation: types.RotationDaily, Start: mustParse(t, "2026-03-27T09:00:00+01:00"), Participants: []uint{1, 2, 3}},
+			}},
+			// 08:30 in Berlin on the day clocks went forward, still the shift that started the day before
+			at:     "2026-03-29T06:30:00Z",
+			userID: 2,
+			start:  "2026-03-28T08:00:00Z",
+			end:    "2026-03-29T07:00:00Z",
+		},
+		{
+			name: "daily layer hands off at 09:00 summer time",
+			schedule: Schedule{Location: berlin, Layers: []types.RotationLayer{
+				{Rotation: types.RotationDaily, Start: mustParse(t, "2026-03-27T09:00:00+01:00"), Participants: []uint{1, 2, 3}},
+			}},
+			at:     "2026-03-29T07:00:00Z",
+			userID: 3,
+			start:  "2026-03-29T07:00:00Z",
+			end:    "2026-03-30T07:00:00Z",
+		},
+		{
+			name: "weekly layer shift is an hour longer across the end of summer time",
+			schedule: Schedule{Location: berlin, Layers: []types.RotationLayer{
+				{Rotation: types.RotationWeekly, Start: mustParse(t, "2026-10-19T09:00:00+02:00"), Participants: []uint{4, 7}},
+			}},
+			at:     "2026-10-26T07:30:00Z",
+			userID: 4,
+			start:  "2026-10-19T07:00:00Z",
+			end:    "2026-10-26T08:00:00Z",
+		},
+		{
+			name: "later layer wins until it ends",
+			schedule: Schedule{Layers: []types.RotationLayer{
+				{Rotation: types.RotationWeekly, Start: mustParse(t, "2026-01-05T00:00:00Z"), Participants: []uint{1, 2}},
+				{Rotation: types.RotationDaily, Start: mustParse(t, "2026-01-10T00:00:00Z"), End: &layerEnd, Participants: []uint{9}},
+			}},
+			at:     "2026-01-11T12:00:00Z",
+			userID: 9,
+			start:  "2026-01-11T00:00:00Z",
+			end:    "2026-01-12T00:00:00Z",
+		},
+		{
+			name: "earlier layer takes over at the end of a layer",
+			schedule: Schedule{Layers: []types.RotationLayer{
+				{Rotation: types.RotationWeekly, Start: mustParse(t, "2026-01-05T00:00:00Z"), Participants: []uint{1, 2}},
+				{Rotation: types.RotationDaily, Start: mustParse(t, "2026-01-10T00:00:00Z"), End: &layerEnd, Participants: []uint{9}},
+			}},
+			at:     "2026-01-12T00:00:00Z",
+			userID: 2,
+			start:  "2026-01-12T00:00:00Z",
+			end:    "2026-01-19T00:00:00Z",
+		},
+		{
+			name: "shift is cut at the end of its layer",
+			schedule: Schedule{Layers: []types.RotationLayer{
+				{Rotation: types.RotationWeekly, Start: mustParse(t, "2026-01-05T00:00:00Z"), End: &layerEnd, Participants: []uint{1, 2}},
+			}},
+			at:     "2026-01-11T00:00:00Z",
+			userID: 1,
+			start:  "2026-01-05T00:00:00Z",
+			end:    "2026-01-12T00:00:00Z",
+		},
+		{
+			name: "newer override wins over older ones and layers",
+			schedule: Schedule{
+				Layers: []types.RotationLayer{{Rotation: types.RotationDaily, Start: mustParse(t, "2026-01-01T00:00:00Z"), Participants: []uint{1}}},
+				Overrides: []Override{
+					{UserID: 5, Start: mustParse(t, "2026-01-02T00:00:00Z"), End: mustParse(t, "2026-01-03T00:00:00Z")},
+					{UserID: 6, Start: mustParse(t, "2026-01-02T10:00:00Z"), End: mustParse(t, "2026-01-02T12:00:00Z")},
+				},
+			},
+			at:     "2026-01-02T11:00:00Z",
+			userID: 6,
+			start:  "2026-01-02T10:00:00Z",
+			end:    "2026-01-02T12:00:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shift, ok := tt.schedule.At(mustParse(t, tt.at))
+
+			if tt.userID == 0 {
+				if ok {
+					t.Fatalf("At(%s) = user %d, want nobody on call", tt.at, shift.UserID)
+				}
+				return
+			}
+
+			if !ok {
+				t.Fatalf("At(%s) = nobody, want user %d", tt.at, tt.userID)
+			}
+			if shift.UserID != tt.userID {
+				t.Errorf("user = %d, want %d", shift.UserID, tt.userID)
+			}
+			if want := mustParse(t, tt.start); !shift.Start.Equal(want) {
+				t.Errorf("start = %s, want %s", shift.Start.UTC(), want.UTC())
+			}
+			if want := mustParse(t, tt.end); !shift.End.Equal(want) {
+				t.Errorf("end = %s, want %s", shift.End.UTC(), want.UTC())
+			}
+		})
+	}
+}
+
+func TestScheduleTimeline(t *testing.T) {
+	schedule := Schedule{
+		Layers: []types.RotationLayer{
+			{Name: "Primary", Rotation: types.RotationDaily, Start: mustParse(t, "2026-01-01T09:00:00Z"), Participants: []uint{1, 2}},
+		},
+		Overrides: []Override{
+			{UserID: 5, Start: mustParse(t, "2026-01-01T12:00:00Z"), End: mustParse(t, "2026-01-01T14:00:00Z")},
+		},
+	}
+
+	shifts := schedule.Timeline(mustParse(t, "2026-01-01T06:00:00Z"), mustParse(t, "2026-01-02T12:00:00Z"))
+
+	want := []struct {
+		userID     uint
+		start, end string
+		override   bool
+	}{
+		{1, "2026-01-01T09:00:00Z", "2026-01-01T12:00:00Z", false},
+		{5, "2026-01-01T12:00:00Z", "2026-01-01T14:00:00Z", true}, // The override splits the shift of user 1
+		{1, "2026-01-01T14:00:00Z", "2026-01-02T09:00:00Z", false},
+		{2, "2026-01-02T09:00:00Z", "2026-01-02T12:00:00Z", false}, // Cut to the requested range
+	}
+
+	if len(shifts) != len(want) {
+		t.Fatalf("got %d shifts, want %d: %+v", len(shifts), len(want), shifts)
+	}
+
+	for i, w := range want {
+		shift := shifts[i]
+
+		if shift.UserID != w.userID || shift.Override != w.override ||
+			!shift.Start.Equal(mustParse(t, w.start)) || !shift.End.Equal(mustParse(t, w.end)) {
+			t.Errorf("shift %d = user %d %s-%s override %v, want user %d %s-%s override %v",
+				i, shift.UserID, shift.Start.UTC().Format(time.RFC3339), shift.End.UTC().Format(time.RFC3339), shift.Override,
+				w.userID, w.start, w.end, w.override)
+		}
+
+		if !shift.Override && shift.Layer != "Primary" {
+			t.Errorf("shift %d layer = %q, want Primary", i, shift.Layer)
+		}
+	}
+}
+
+func TestValidateLayers(t *testing.T) {
+	start := mustParse(t, "2026-01-01T00:00:00Z")
+	before := start.Add(-time.Hour)
+
+	tests := []struct {
+		name  string
+		layer types.RotationLayer
+		valid bool
+	}{
+		{"valid", types.RotationLayer{Rotation: types.RotationWeekly, Start: start, Participants: []uint{1}}, true},
+		{"unknown rotation", types.RotationLayer{Rotation: "monthly", Start: start, Participants: []uint{1}}, false},
+		{"negative shift length", types.RotationLayer{Rotation: types.RotationDaily, ShiftLength: -1, Start: start, Participants: []uint{1}}, false},
+		{"no start", types.RotationLayer{Rotation: types.RotationDaily, Participants: []uint{1}}, false},
+		{"end before start", types.RotationLayer{Rotation: types.RotationDaily, Start: start, End: &before, Participants: []uint{1}}, false},
+		{"no participants", types.RotationLayer{Rotation: types.RotationDaily, Start: start}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layers := []types.RotationLayer{tt.layer}
+
+			err := ValidateLayers(layers)
+			if (err == nil) != tt.valid {
+				t.Fatalf("ValidateLayers() error = %v, want valid %v", err, tt.valid)
+			}
+
+			if tt.valid && layers[0].ShiftLength != 1 {
+				t.Errorf("shift length = %d, want the default of 1", layers[0].ShiftLength)
+			}
+		})
+	}
+}
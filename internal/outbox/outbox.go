@@ -0,0 +1,321 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/monocle-dev/monocle/db"
+	"github.com/monocle-dev/monocle/internal/models"
+	"github.com/monocle-dev/monocle/internal/services"
+	"github.com/monocle-dev/monocle/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultInterval    = 5  // Seconds between scans for due notifications
+	defaultMaxAttempts = 8  // Attempts before a notification is given up on
+	defaultGroupWindow = 10 // Seconds a groupable notification waits for others to send with
+	batchSize          = 50 // Notifications delivered per scan at most, and per group except digests
+
+	baseDelay = 30 * time.Second // Delay before the first retry, doubled after every attempt
+	maxDelay  = time.Hour
+
+	// A claimed notification is tried again after this long, in case the
+	// replica sending it died. It is longer than the webhook timeout.
+	claimTimeout = 2 * time.Minute
+)
+
+// Worker delivers the notifications of the outbox. Notifications of the same
+// group that are due together are sent as one message, all others on their
+// own. A failed delivery only pushes back its own notifications, with
+// exponential backoff, so a broken webhook does not delay other channels.
+type Worker struct {
+	interval    time.Duration
+	maxAttempts int
+	groupWindow time.Duration
+	ctx         context.Context
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+}
+
+func NewWorker() *Worker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Worker{
+		interval:    time.Duration(utils.GetEnvInt("NOTIFICATION_INTERVAL", defaultInterval)) * time.Second,
+		maxAttempts: utils.GetEnvInt("NOTIFICATION_MAX_ATTEMPTS", defaultMaxAttempts),
+		groupWindow: time.Duration(utils.GetEnvCount("NOTIFICATION_GROUP_WINDOW", defaultGroupWindow)) * time.Second,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Start runs the worker in the background
+func (w *Worker) Start() {
+	log.Println("Starting notification outbox worker...")
+
+	w.wg.Add(1)
+	go w.run()
+}
+
+// Stop waits for the current delivery to finish and stops the worker
+func (w *Worker) Stop() {
+	w.cancel()
+	w.wg.Wait()
+	log.Println("Notification outbox worker stopped")
+}
+
+func (w *Worker) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+			w.deliverDue()
+		}
+	}
+}
+
+// deliverDue makes an attempt at every notification that is due
+func (w *Worker) deliverDue() {
+	for i := 0; i < batchSize; i++ {
+		if w.ctx.Err() != nil {
+			return
+		}
+
+		group, found, err := w.claimNext(time.Now())
+		if err != nil {
+			log.Printf("Failed to claim notification: %v", err)
+			return
+		}
+
+		if !found {
+			return
+		}
+
+		// The destination of the notification was rate limited
+		if len(group) == 0 {
+			continue
+		}
+
+		sendErr := services.DeliverNotifications(group)
+		finishedAt := time.Now()
+
+		for _, notification := range group {
+			if err := w.finish(notification, group[0].ID, len(group), sendErr, finishedAt); err != nil {
+				log.Printf("Failed to record delivery of notification %d: %v", notification.ID, err)
+			}
+		}
+	}
+}
+
+// claimNext counts an attempt at the next due notification and the due
+// notifications of its group, all of them for a digest, and pushes them back
+// by claimTimeout so that no other worker picks them up meanwhile.
+// Notifications another replica is claiming are left to it. Groupable
+// notifications are only claimed once they waited groupWindow for others,
+// unless they are being retried.
+//
+// When the destination of the notification is over its rate limit, its due
+// notifications are pushed back until the limit allows another message and an
+// empty group is returned, without counting an attempt.
+func (w *Worker) claimNext(now time.Time) ([]models.Notification, bool, error) {
+	var group []models.Notification
+	found := false
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var lead models.Notification
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.NotificationStatusPending, now).
+			Where("group_key = '' OR attempts > 0 OR created_at <= ?", now.Add(-w.groupWindow)).
+			Order("next_attempt_at").
+			First(&lead).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		found = true
+
+		allowedAt, err := rateLimitedUntil(tx, lead, now)
+		if err != nil {
+			return err
+		}
+
+		if allowedAt != nil {
+			return sameDestination(tx, lead).
+				Where("status = ? AND next_attempt_at <= ?", models.NotificationStatusPending, now).
+				Update("next_attempt_at", *allowedAt).Error
+		}
+
+		group = append(group, lead)
+
+		if lead.GroupKey != "" {
+			var members []models.Notification
+
+			query := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("group_key = ? AND status = ? AND next_attempt_at <= ? AND id <> ?", lead.GroupKey, models.NotificationStatusPending, now, lead.ID).
+				Order("next_attempt_at, id")
+
+			// A digest covers every event of its period, so it is never split
+			if !strings.HasSuffix(lead.GroupKey, ":digest") {
+				query = query.Limit(batchSize - 1)
+			}
+
+			if err := query.Find(&members).Error; err != nil {
+				return err
+			}
+
+			group = append(group, members...)
+		}
+
+		claimedUntil := now.Add(claimTimeout)
+		ids := make([]uint, 0, len(group))
+
+		for i := range group {
+			group[i].Attempts++
+			group[i].NextAttemptAt = &claimedUntil
+			ids = append(ids, group[i].ID)
+		}
+
+		return tx.Model(&models.Notification{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": claimedUntil,
+		}).Error
+	})
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	return group, found, nil
+}
+
+// sameDestination scopes a query to the notifications that share the rate
+// limit of a notification: those of its project and channel that go to the
+// same destination
+func sameDestination(tx *gorm.DB, notification models.Notification) *gorm.DB {
+	return tx.Model(&models.Notification{}).
+		Where("project_id = ? AND channel = ? AND destination = ?", notification.ProjectID, notification.Channel, notification.Destination)
+}
+
+// rateLimitedUntil returns when the destination of a notification may get its
+// next message, or nil when it may get one now. Messages are counted over the
+// last minute, notifications sent as part of another one's message are not.
+func rateLimitedUntil(tx *gorm.DB, notification models.Notification, now time.Time) (*time.Time, error) {
+	limit, err := services.NotificationRateLimit(tx, notification)
+	if err != nil || limit == 0 {
+		return nil, err
+	}
+
+	var sentAt []time.Time
+
+	if err := sameDestination(tx, notification).
+		Where("status = ? AND grouped_into_id IS NULL AND sent_at > ?", models.NotificationStatusSent, now.Add(-time.Minute)).
+		Order("sent_at DESC").
+		Limit(limit).
+		Pluck("sent_at", &sentAt).Error; err != nil {
+		return nil, fmt.Errorf("failed to count sent notifications: %w", err)
+	}
+
+	if len(sentAt) < limit {
+		return nil, nil
+	}
+
+	// The oldest of the last limit messages leaves the window first
+	allowedAt := sentAt[limit-1].Add(time.Minute)
+	return &allowedAt, nil
+}
+
+// finish stores the outcome of an attempt at a notification of a group of
+// size notifications led by leadID: the notification was sent, is retried later or
+// is given up on. Sent and abandoned notifications of an incident go to its
+// timeline.
+func (w *Worker) finish(notification models.Notification, leadID uint, size int, sendErr error, now time.Time) error {
+	updates := map[string]interface{}{}
+	timeline := ""
+	label := services.DeliveryLabel(notification)
+
+	switch {
+	case sendErr == nil:
+		updates["status"] = models.NotificationStatusSent
+		updates["sent_at"] = now
+		updates["next_attempt_at"] = nil
+		updates["last_error"] = ""
+		timeline = fmt.Sprintf("Sent %s notification", label)
+
+		if size > 1 {
+			timeline += fmt.Sprintf(" (one message for %d notifications)", size)
+		}
+
+		if notification.ID != leadID {
+			updates["grouped_into_id"] = leadID
+		}
+	case errors.Is(sendErr, services.ErrUndeliverable) || notification.Attempts >= w.maxAttempts:
+		updates["status"] = models.NotificationStatusFailed
+		updates["next_attempt_at"] = nil
+		updates["last_error"] = sendErr.Error()
+		timeline = fmt.Sprintf("Failed to send %s notification after %d attempts: %v", label, notification.Attempts, sendErr)
+		log.Printf("Giving up on notification %d after %d attempts: %v", notification.ID, notification.Attempts, sendErr)
+	default:
+		updates["next_attempt_at"] = now.Add(retryDelay(notification.Attempts, sendErr))
+		updates["last_error"] = sendErr.Error()
+	}
+
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		// The claim was lost if the notification was claimed again meanwhile
+		result := tx.Model(&models.Notification{}).
+			Where("id = ? AND status = ? AND attempts = ?", notification.ID, models.NotificationStatusPending, notification.Attempts).
+			Updates(updates)
+
+		if result.Error != nil || result.RowsAffected == 0 || timeline == "" || notification.IncidentID == nil {
+			return result.Error
+		}
+
+		return services.RecordIncidentEvent(tx, *notification.IncidentID, models.IncidentEventNotification, nil, timeline)
+	})
+}
+
+// retryDelay returns how long to wait after a failed attempt, as long as the
+// service asked for when it rate limited us
+func retryDelay(attempts int, err error) time.Duration {
+	var limited *services.RetryAfterError
+	if errors.As(err, &limited) && limited.Delay > 0 {
+		return min(limited.Delay, maxDelay)
+	}
+
+	delay := baseDelay
+	for i := 1; i < attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxDelay)
+}
+
+// Global outbox worker instance
+var globalWorker *Worker
+
+// Initialize creates and starts the global outbox worker
+func Initialize() {
+	globalWorker = NewWorker()
+	globalWorker.Start()
+}
+
+// Shutdown stops the global outbox worker
+func Shutdown() {
+	if globalWorker != nil {
+		globalWorker.Stop()
+	}
+}
@@ -37,6 +37,18 @@ func NewRouter() *gin.Engine {
 			auth.POST("/logout", middleware.AuthMiddleware(), handlers.LogoutUser)
 		}
 
+		agent := api.Group("/agent", middleware.AgentAuthMiddleware())
+		{
+			agent.GET("/monitors", handlers.AgentMonitors)
+			agent.POST("/results", handlers.AgentResults)
+		}
+
+		alerts := api.Group("/alerts", middleware.AlertSourceAuthMiddleware())
+		{
+			alerts.POST("", handlers.ReceiveAlert)
+			alerts.POST("/alertmanager", handlers.ReceiveAlertmanagerAlerts)
+		}
+
 		projects := api.Group("/projects", middleware.AuthMiddleware())
 		{
 			projects.POST("", handlers.CreateProject)
@@ -50,9 +62,73 @@ func NewRouter() *gin.Engine {
 			// Monitor endpoints
 			projects.POST("/:project_id/monitors", handlers.CreateMonitor)
 			projects.GET("/:project_id/monitors", handlers.GetMonitors)
+			projects.POST("/:project_id/monitors/test", handlers.TestMonitor)
+			projects.GET("/:project_id/monitors/graph", handlers.GetMonitorGraph)
 			projects.PUT("/:project_id/monitors/:monitor_id", handlers.UpdateMonitor)
 			projects.GET("/:project_id/monitors/:monitor_id/checks", handlers.GetMonitorChecks)
+			projects.POST("/:project_id/monitors/:monitor_id/run", handlers.RunMonitor)
 			projects.DELETE("/:project_id/monitors/:monitor_id", handlers.DeleteMonitor)
+
+			// Incident endpoints
+			projects.GET("/:project_id/incidents", handlers.ListIncidents)
+			projects.POST("/:project_id/incidents", handlers.CreateIncident)
+			projects.GET("/:project_id/incidents/:incident_id", handlers.GetIncident)
+			projects.PATCH("/:project_id/incidents/:incident_id", handlers.UpdateIncident)
+			projects.POST("/:project_id/incidents/:incident_id/acknowledge", handlers.AcknowledgeIncident)
+			projects.POST("/:project_id/incidents/:incident_id/resolve", handlers.ResolveIncident)
+			projects.POST("/:project_id/incidents/:incident_id/reopen", handlers.ReopenIncident)
+			projects.GET("/:project_id/incidents/:incident_id/timeline", handlers.GetIncidentTimeline)
+			projects.GET("/:project_id/incidents/:incident_id/notifications", handlers.ListIncidentNotifications)
+			projects.POST("/:project_id/incidents/:incident_id/comments", handlers.CreateIncidentComment)
+
+			// Postmortem endpoints
+			projects.POST("/:project_id/incidents/:incident_id/postmortem", handlers.CreatePostmortem)
+			projects.GET("/:project_id/incidents/:incident_id/postmortem", handlers.GetPostmortem)
+			projects.PUT("/:project_id/incidents/:incident_id/postmortem", handlers.UpdatePostmortem)
+			projects.DELETE("/:project_id/incidents/:incident_id/postmortem", handlers.DeletePostmortem)
+			projects.GET("/:project_id/incidents/:incident_id/postmortem/export", handlers.ExportPostmortem)
+			projects.POST("/:project_id/incidents/:incident_id/postmortem/action-items", handlers.CreateActionItem)
+			projects.GET("/:project_id/action-items", handlers.ListActionItems)
+			projects.PUT("/:project_id/action-items/:item_id", handlers.UpdateActionItem)
+			projects.DELETE("/:project_id/action-items/:item_id", handlers.DeleteActionItem)
+
+			// Escalation policy endpoints
+			projects.POST("/:project_id/escalation-policies", handlers.CreateEscalationPolicy)
+			projects.GET("/:project_id/escalation-policies", handlers.ListEscalationPolicies)
+			projects.GET("/:project_id/escalation-policies/:policy_id", handlers.GetEscalationPolicy)
+			projects.PUT("/:project_id/escalation-policies/:policy_id", handlers.UpdateEscalationPolicy)
+			projects.DELETE("/:project_id/escalation-policies/:policy_id", handlers.DeleteEscalationPolicy)
+
+			// On-call schedule endpoints
+			projects.POST("/:project_id/schedules", handlers.CreateSchedule)
+			projects.GET("/:project_id/schedules", handlers.ListSchedules)
+			projects.GET("/:project_id/schedules/:schedule_id", handlers.GetSchedule)
+			projects.PUT("/:project_id/schedules/:schedule_id", handlers.UpdateSchedule)
+			projects.DELETE("/:project_id/schedules/:schedule_id", handlers.DeleteSchedule)
+			projects.GET("/:project_id/schedules/:schedule_id/oncall", handlers.GetOnCall)
+			projects.GET("/:project_id/schedules/:schedule_id/shifts", handlers.GetScheduleShifts)
+			projects.GET("/:project_id/schedules/:schedule_id/ical", handlers.ExportScheduleICal)
+			projects.POST("/:project_id/schedules/:schedule_id/overrides", handlers.CreateOnCallOverride)
+			projects.DELETE("/:project_id/schedules/:schedule_id/overrides/:override_id", handlers.DeleteOnCallOverride)
+
+			// Remote probe agent endpoints
+			projects.POST("/:project_id/agents", handlers.CreateAgent)
+			projects.GET("/:project_id/agents", handlers.ListAgents)
+			projects.DELETE("/:project_id/agents/:agent_id", handlers.DeleteAgent)
+
+			// Alert source endpoints
+			projects.POST("/:project_id/alert-sources", handlers.CreateAlertSource)
+			projects.GET("/:project_id/alert-sources", handlers.ListAlertSources)
+			projects.DELETE("/:project_id/alert-sources/:source_id", handlers.DeleteAlertSource)
+
+			// Notification rule endpoints
+			projects.POST("/:project_id/notification-rules", handlers.CreateNotificationRule)
+			projects.GET("/:project_id/notification-rules", handlers.ListNotificationRules)
+			projects.GET("/:project_id/notification-rules/:rule_id", handlers.GetNotificationRule)
+			projects.PUT("/:project_id/notification-rules/:rule_id", handlers.UpdateNotificationRule)
+			projects.DELETE("/:project_id/notification-rules/:rule_id", handlers.DeleteNotificationRule)
+			projects.POST("/:project_id/notification-rules/:rule_id/test", handlers.TestNotificationRule)
+			projects.POST("/:project_id/notification-templates/preview", handlers.PreviewNotificationTemplate)
 		}
 	}
 
@@ -0,0 +1,70 @@
+package scheduler
+
+import (
+	"log"
+	"time"
+
+	"github.com/monocle-dev/monocle/db"
+	"github.com/monocle-dev/monocle/internal/models"
+)
+
+// defaultBackoffMaxInterval caps the backoff when a monitor does not set its own cap
+const defaultBackoffMaxInterval = time.Hour
+
+// effectiveInterval returns the interval a job currently runs at, stretched
+// exponentially while its incident stays open when backoff is enabled
+func effectiveInterval(job *MonitorJob) time.Duration {
+	interval := monitorInterval(job.monitor)
+
+	if !job.monitor.BackoffEnabled || job.failures <= 1 {
+		return interval
+	}
+
+	maxInterval := time.Duration(job.monitor.BackoffMaxInterval) * time.Second
+	if maxInterval <= 0 {
+		maxInterval = defaultBackoffMaxInterval
+	}
+	if maxInterval < interval {
+		return interval
+	}
+
+	effective := interval
+	for i := 1; i < job.failures && effective < maxInterval; i++ {
+		effective *= 2
+	}
+
+	if effective > maxInterval {
+		effective = maxInterval
+	}
+
+	return effective
+}
+
+// updateBackoff records whether the monitor's incident is still open after a
+// check and returns the new effective interval. Callers must hold s.mu.
+func updateBackoff(job *MonitorJob, incidentOpen bool) time.Duration {
+	if incidentOpen {
+		job.failures++
+	} else {
+		job.failures = 0
+	}
+
+	return effectiveInterval(job)
+}
+
+// storeEffectiveInterval saves the interval shown on the dashboard, replaced in tests
+var storeEffectiveInterval = saveEffectiveInterval
+
+// saveEffectiveInterval stores the interval shown on the dashboard. It does not
+// touch updated_at, so the change is not treated as a new monitor version.
+func saveEffectiveInterval(monitor models.Monitor, interval time.Duration) {
+	seconds := int(interval / time.Second)
+	if seconds == monitor.Interval {
+		seconds = 0
+	}
+
+	if err := db.DB.Model(&models.Monitor{}).Where("id = ? AND effective_interval <> ?", monitor.ID, seconds).
+		UpdateColumn("effective_interval", seconds).Error; err != nil {
+		log.Printf("Failed to save effective interval for monitor %d: %v", monitor.ID, err)
+	}
+}
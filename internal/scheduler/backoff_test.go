@@ -0,0 +1,47 @@
+package scheduler
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/monocle-dev/monocle/internal/models"
+)
+
+// A leader that takes over from one that had backed off a monitor starts its
+// job at the base interval and resets the stored interval to match
+func TestBecomeLeaderResetsStoredBackoff(t *testing.T) {
+	saved := make(map[uint]time.Duration)
+	storeEffectiveInterval = func(monitor models.Monitor, interval time.Duration) {
+		saved[monitor.ID] = interval
+	}
+	t.Cleanup(func() { storeEffectiveInterval = saveEffectiveInterval })
+
+	backedOff := models.Monitor{Type: "http", Status: "active", Interval: 60, BackoffEnabled: true, EffectiveInterval: 480}
+	backedOff.ID = 1
+	normal := models.Monitor{Type: "http", Status: "active", Interval: 30}
+	normal.ID = 2
+
+	s := NewScheduler()
+	defer s.cancel()
+
+	s.becomeLeader(&sql.Conn{}, []models.Monitor{backedOff, normal}, time.Now())
+
+	if got := saved[1]; got != time.Minute {
+		t.Errorf("stored interval of backed off monitor = %v, want %v", got, time.Minute)
+	}
+	if _, ok := saved[2]; ok {
+		t.Errorf("stored interval of monitor without backoff, want no write")
+	}
+
+	job := s.monitors[1]
+	if got := effectiveInterval(job); got != time.Minute {
+		t.Errorf("effective interval after takeover = %v, want %v", got, time.Minute)
+	}
+
+	// Backoff builds up again from the base interval while the incident stays open
+	updateBackoff(job, true)
+	if got := updateBackoff(job, true); got != 2*time.Minute {
+		t.Errorf("effective interval after two failed checks = %v, want %v", got, 2*time.Minute)
+	}
+}
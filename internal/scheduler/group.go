@@ -0,0 +1,152 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/monocle-dev/monocle/db"
+	"github.com/monocle-dev/monocle/internal/models"
+	"github.com/monocle-dev/monocle/internal/monitors"
+	"github.com/monocle-dev/monocle/internal/types"
+)
+
+// evaluateGroup derives a check result for a group monitor from its active members
+func (s *Scheduler) evaluateGroup(group models.Monitor) (checkRun, error) {
+	var cfg types.GroupConfig
+	if err := json.Unmarshal(group.Config, &cfg); err != nil {
+		return checkRun{}, fmt.Errorf("invalid group config: %w", err)
+	}
+
+	var members []models.Monitor
+	if len(cfg.Members) > 0 {
+		if err := db.DB.Where("id IN ? AND project_id = ? AND status = ?", cfg.Members, group.ProjectID, "active").
+			Find(&members).Error; err != nil {
+			return checkRun{}, fmt.Errorf("failed to load group members: %w", err)
+		}
+	}
+
+	statuses, err := memberStatuses(members)
+	if err != nil {
+		return checkRun{}, fmt.Errorf("failed to load status of members: %w", err)
+	}
+
+	status, message, metrics := monitors.EvaluateGroup(cfg, statuses)
+
+	return checkRun{
+		location: types.LocalLocation,
+		metrics:  metrics,
+		status:   status,
+		message:  message,
+	}, nil
+}
+
+// memberStatuses reports how each group member is doing. Open incidents take
+// the location quorum into account, so they decide whether a member is failing.
+func memberStatuses(members []models.Monitor) ([]string, error) {
+	statuses := make([]string, 0, len(members))
+	if len(members) == 0 {
+		return statuses, nil
+	}
+
+	ids := make([]uint, 0, len(members))
+	for _, member := range members {
+		ids = append(ids, member.ID)
+	}
+
+	var incidents []models.Incident
+	if err := db.DB.Select("monitor_id, kind").
+		Where("monitor_id IN ? AND status IN ?", ids, models.OpenIncidentStatuses).
+		Find(&incidents).Error; err != nil {
+		return nil, err
+	}
+
+	var latest []models.MonitorCheck
+	if err := db.DB.Raw(`SELECT DISTINCT ON (monitor_id) monitor_id, status
+		FROM monitor_checks
+		WHERE monitor_id IN ?
+		ORDER BY monitor_id, checked_at DESC`, ids).Scan(&latest).Error; err != nil {
+		return nil, err
+	}
+
+	open := make(map[uint]string, len(incidents))
+	for _, incident := range incidents {
+		open[*incident.MonitorID] = incident.Kind
+	}
+
+	lastStatus := make(map[uint]string, len(latest))
+	for _, check := range latest {
+		lastStatus[check.MonitorID] = check.Status
+	}
+
+	for _, member := range members {
+		kind, failing := open[member.ID]
+
+		switch {
+		case failing && kind == models.IncidentKindDegraded:
+			statuses = append(statuses, monitors.StatusDegraded)
+		case failing:
+			statuses = append(statuses, monitors.StatusFailure)
+		case lastStatus[member.ID] == monitors.StatusDegraded:
+			statuses = append(statuses, monitors.StatusDegraded)
+		default:
+			statuses = append(statuses, monitors.StatusSuccess)
+		}
+	}
+
+	return statuses, nil
+}
+
+// sameGroupResult reports whether a stored group check already shows what run
+// found: the same status, message and member counts
+func sameGroupResult(last models.MonitorCheck, run checkRun) bool {
+	if last.Status != run.status || last.Message != run.message {
+		return false
+	}
+
+	var stored monitors.Metrics
+	if err := json.Unmarshal(last.Metrics, &stored); err != nil {
+		return false
+	}
+
+	for name, value := range run.metrics {
+		if storedValue, ok := stored[name]; !ok || storedValue != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// updateGroups re-evaluates the groups a monitor belongs to after one of its
+// results was stored. A group only gets a result here when its status, message
+// or member counts change, its own interval records it otherwise.
+func (s *Scheduler) updateGroups(member models.Monitor) {
+	membership, _ := json.Marshal([]uint{member.ID})
+
+	var groups []models.Monitor
+	if err := db.DB.Where("project_id = ? AND type = ? AND status = ? AND config->'members' @> CAST(? AS jsonb)",
+		member.ProjectID, "group", "active", string(membership)).Find(&groups).Error; err != nil {
+		log.Printf("Failed to load groups of monitor %d: %v", member.ID, err)
+		return
+	}
+
+	for _, group := range groups {
+		run, err := s.evaluateGroup(group)
+		if err != nil {
+			log.Printf("Failed to evaluate group monitor %d: %v", group.ID, err)
+			continue
+		}
+
+		var last models.MonitorCheck
+		if err := db.DB.Select("status, message, metrics").Where("monitor_id = ?", group.ID).Order("checked_at DESC").
+			First(&last).Error; err == nil && sameGroupResult(last, run) {
+			continue
+		}
+
+		if _, err := s.storeCheckResult(group, run); err != nil && !errors.Is(err, errStaleMonitor) {
+			log.Printf("Failed to store result of group monitor %d: %v", group.ID, err)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/monocle-dev/monocle/db"
+	"github.com/monocle-dev/monocle/internal/models"
+)
+
+const (
+	// leaderLockKey is the Postgres advisory lock held by the active scheduler
+	leaderLockKey int64 = 0x6d6f6e6f636c65 // "monocle"
+
+	defaultElectionInterval = 5
+)
+
+// instanceID identifies this process in logs and status output
+func instanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// runElection keeps trying to become the leader and steps down when the
+// session holding the advisory lock is lost. Only the leader runs checks,
+// so several replicas can share one database without duplicate incidents.
+func (s *Scheduler) runElection() {
+	ticker := time.NewTicker(s.electionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.checkLeadership(); err != nil {
+				log.Printf("Scheduler leader election failed: %v", err)
+			}
+		}
+	}
+}
+
+// checkLeadership acquires the leader lock if it is free, or verifies that the
+// session holding it is still alive
+func (s *Scheduler) checkLeadership() error {
+	s.mu.RLock()
+	conn := s.lockConn
+	s.mu.RUnlock()
+
+	if conn != nil {
+		ctx, cancel := context.WithTimeout(s.ctx, s.electionInterval)
+		defer cancel()
+
+		if err := conn.PingContext(ctx); err != nil {
+			if s.ctx.Err() != nil {
+				return nil
+			}
+			log.Printf("Lost scheduler leadership on %s: %v", s.instance, err)
+			s.stepDown()
+			return err
+		}
+
+		return nil
+	}
+
+	return s.tryAcquireLeadership()
+}
+
+// tryAcquireLeadership takes the advisory lock on a dedicated connection and
+// loads all active monitors when it succeeds
+func (s *Scheduler) tryAcquireLeadership() error {
+	sqlDB, err := db.DB.DB()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, s.electionInterval)
+	defer cancel()
+
+	// Advisory locks belong to a session, so the lock is held on its own connection
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", leaderLockKey).Scan(&acquired); err != nil {
+		conn.Close()
+		return err
+	}
+
+	if !acquired {
+		conn.Close()
+		return nil
+	}
+
+	var monitorsList []models.Monitor
+	if err := db.DB.Where("status = ?", "active").Find(&monitorsList).Error; err != nil {
+		releaseLeaderLock(conn)
+		return err
+	}
+
+	s.becomeLeader(conn, monitorsList, time.Now())
+
+	log.Printf("Scheduler on %s became leader with %d monitors", s.instance, len(monitorsList))
+	return nil
+}
+
+// becomeLeader schedules the active monitors once conn holds the leader lock.
+// Backoff is counted in memory, so every job starts at its base interval and
+// intervals stored by a previous leader are reset to match.
+func (s *Scheduler) becomeLeader(conn *sql.Conn, monitorsList []models.Monitor, now time.Time) {
+	s.mu.Lock()
+	s.lockConn = conn
+	for _, monitor := range monitorsList {
+		// Spread the first run over the interval to avoid a thundering herd
+		s.schedule(monitor, now.Add(phaseOffset(monitor.ID, monitorInterval(monitor))))
+	}
+	s.mu.Unlock()
+
+	s.notify()
+
+	for _, monitor := range monitorsList {
+		if monitor.EffectiveInterval != 0 {
+			storeEffectiveInterval(monitor, monitorInterval(monitor))
+		}
+
+		// Manual runs that followers asked for while there was no leader
+		s.honorRunRequest(monitor)
+	}
+}
+
+// stepDown drops every job and releases the leader lock
+func (s *Scheduler) stepDown() {
+	s.mu.Lock()
+	conn := s.lockConn
+	s.lockConn = nil
+	for _, job := range s.monitors {
+		job.removed = true
+		job.cancel()
+		releaseWaiters(job, errJobRemoved)
+	}
+	s.monitors = make(map[uint]*MonitorJob)
+	s.queue = nil
+	s.mu.Unlock()
+
+	if conn != nil {
+		releaseLeaderLock(conn)
+	}
+}
+
+// isLeader reports whether this instance currently runs checks. Callers must hold s.mu.
+func (s *Scheduler) isLeader() bool {
+	return s.lockConn != nil
+}
+
+// releaseLeaderLock unlocks and closes the connection holding the leader lock.
+// When the unlock fails the session is thrown away rather than returned to the
+// pool, where it would keep holding the lock for whichever query used it next.
+func releaseLeaderLock(conn *sql.Conn) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", leaderLockKey); err != nil {
+		log.Printf("Failed to release scheduler leader lock, closing its connection: %v", err)
+
+		// Returning ErrBadConn makes database/sql discard the connection
+		conn.Raw(func(driverConn any) error {
+			if closer, ok := driverConn.(io.Closer); ok {
+				closer.Close()
+			}
+			return driver.ErrBadConn
+		})
+	}
+
+	conn.Close()
+}
@@ -0,0 +1,141 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/monocle-dev/monocle/db"
+	"github.com/monocle-dev/monocle/internal/models"
+	"gorm.io/gorm"
+)
+
+const defaultReconcileInterval = 300
+
+// monitorChange is the payload published by the monitors table trigger
+type monitorChange struct {
+	Op string `json:"op"`
+	ID uint   `json:"id"`
+}
+
+// listenForChanges reconciles the job set whenever a monitor changes in the
+// database, and runs a full reconcile periodically as a safety net
+func (s *Scheduler) listenForChanges() {
+	listener := pq.NewListener(db.DSN, 10*time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Monitor change listener error: %v", err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(db.MonitorChangesChannel); err != nil {
+		log.Printf("Failed to listen for monitor changes, relying on periodic reconcile: %v", err)
+	}
+
+	ticker := time.NewTicker(s.reconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case notification := <-listener.Notify:
+			// A nil notification means the connection was re-established and
+			// changes may have been missed in the meantime
+			if notification == nil {
+				s.reconcileAll()
+				continue
+			}
+
+			var change monitorChange
+			if err := json.Unmarshal([]byte(notification.Extra), &change); err != nil {
+				log.Printf("Invalid monitor change payload %q: %v", notification.Extra, err)
+				continue
+			}
+
+			s.reconcileMonitor(change.ID)
+		case <-ticker.C:
+			s.reconcileAll()
+		}
+	}
+}
+
+// reconcileMonitor brings the job for a single monitor in line with the database
+func (s *Scheduler) reconcileMonitor(monitorID uint) {
+	var monitor models.Monitor
+
+	if err := db.DB.First(&monitor, monitorID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.RemoveMonitor(monitorID)
+		} else {
+			log.Printf("Failed to load monitor %d for reconcile: %v", monitorID, err)
+		}
+		return
+	}
+
+	s.syncMonitor(monitor)
+}
+
+// reconcileAll compares every active monitor against the job set
+func (s *Scheduler) reconcileAll() {
+	s.mu.RLock()
+	leader := s.isLeader()
+	s.mu.RUnlock()
+
+	if !leader {
+		return
+	}
+
+	var monitorsList []models.Monitor
+	if err := db.DB.Where("status = ?", "active").Find(&monitorsList).Error; err != nil {
+		log.Printf("Failed to load monitors for reconcile: %v", err)
+		return
+	}
+
+	active := make(map[uint]bool, len(monitorsList))
+	for _, monitor := range monitorsList {
+		active[monitor.ID] = true
+		s.syncMonitor(monitor)
+	}
+
+	s.mu.RLock()
+	var stale []uint
+	for monitorID := range s.monitors {
+		if !active[monitorID] {
+			stale = append(stale, monitorID)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, monitorID := range stale {
+		s.RemoveMonitor(monitorID)
+	}
+}
+
+// syncMonitor adds, replaces or removes the job for a monitor loaded from the
+// database. Jobs that already run the same version are left untouched.
+func (s *Scheduler) syncMonitor(monitor models.Monitor) {
+	if monitor.Status != "active" {
+		s.RemoveMonitor(monitor.ID)
+		return
+	}
+
+	s.mu.RLock()
+	job, exists := s.monitors[monitor.ID]
+	unchanged := exists && sameVersion(job.monitor, monitor)
+	s.mu.RUnlock()
+
+	if !unchanged {
+		s.AddMonitor(monitor)
+	}
+
+	s.honorRunRequest(monitor)
+}
+
+// sameVersion reports whether two copies of a monitor come from the same
+// write. Postgres keeps microseconds, so in-memory timestamps are truncated.
+func sameVersion(a, b models.Monitor) bool {
+	return a.UpdatedAt.Truncate(time.Microsecond).Equal(b.UpdatedAt.Truncate(time.Microsecond))
+}
@@ -0,0 +1,105 @@
+package scheduler
+
+import (
+	"container/heap"
+	"encoding/json"
+	"hash/fnv"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/monocle-dev/monocle/internal/models"
+	"github.com/monocle-dev/monocle/internal/types"
+)
+
+// jobQueue is a min-heap of monitor jobs ordered by their next run time
+type jobQueue []*MonitorJob
+
+func (q jobQueue) Len() int { return len(q) }
+
+func (q jobQueue) Less(i, j int) bool { return q[i].nextRun.Before(q[j].nextRun) }
+
+func (q jobQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *jobQueue) Push(x interface{}) {
+	job := x.(*MonitorJob)
+	job.index = len(*q)
+	*q = append(*q, job)
+}
+
+func (q *jobQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	job := old[n-1]
+	old[n-1] = nil
+	job.index = -1
+	*q = old[:n-1]
+	return job
+}
+
+// peek returns the job that is due next without removing it
+func (q jobQueue) peek() *MonitorJob {
+	if len(q) == 0 {
+		return nil
+	}
+	return q[0]
+}
+
+// remove takes a job out of the queue if it is currently queued
+func (q *jobQueue) remove(job *MonitorJob) {
+	if job.index >= 0 && job.index < len(*q) && (*q)[job.index] == job {
+		heap.Remove(q, job.index)
+	}
+}
+
+// phaseOffset returns a stable offset within the monitor's interval so that
+// monitors loaded together do not all fire at the same moment
+func phaseOffset(monitorID uint, interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return 0
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(strconv.FormatUint(uint64(monitorID), 10)))
+
+	return time.Duration(uint64(h.Sum32()) % uint64(interval))
+}
+
+// monitorInterval returns the check interval of a monitor as a duration
+func monitorInterval(monitor models.Monitor) time.Duration {
+	interval := time.Duration(monitor.Interval) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return interval
+}
+
+// targetHost extracts the host a monitor talks to, used for per-host concurrency limits
+func targetHost(monitor models.Monitor) string {
+	switch monitor.Type {
+	case "http":
+		var cfg types.HttpConfig
+		if json.Unmarshal(monitor.Config, &cfg) == nil {
+			if parsed, err := url.Parse(cfg.URL); err == nil && parsed.Hostname() != "" {
+				return strings.ToLower(parsed.Hostname())
+			}
+		}
+	case "dns":
+		var cfg types.DNSConfig
+		if json.Unmarshal(monitor.Config, &cfg) == nil && cfg.Domain != "" {
+			return strings.ToLower(cfg.Domain)
+		}
+	case "database":
+		var cfg types.DatabaseConfig
+		if json.Unmarshal(monitor.Config, &cfg) == nil && cfg.Host != "" {
+			return strings.ToLower(cfg.Host)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,689 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/monocle-dev/monocle/db"
+	"github.com/monocle-dev/monocle/internal/models"
+	"github.com/monocle-dev/monocle/internal/monitors"
+	"github.com/monocle-dev/monocle/internal/services"
+	"github.com/monocle-dev/monocle/internal/types"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	sslExpiryWarningDays = 14             // Certificates closer to expiry than this are announced
+	sslExpiryRepeat      = 24 * time.Hour // Announcements repeat at most this often until renewal
+)
+
+// errStaleMonitor marks a check result that belongs to a deleted or paused monitor
+var errStaleMonitor = errors.New("monitor is no longer active")
+
+// Overall state of a monitor across its locations
+const (
+	stateHealthy  = "healthy"
+	stateDegraded = "degraded"
+	stateDown     = "down"
+)
+
+// checkRun is the raw result of running a check at a location
+type checkRun struct {
+	location     string
+	err          error
+	responseTime time.Duration
+	metrics      monitors.Metrics
+
+	status  string // Set when the status is not derived from err and thresholds
+	message string
+}
+
+// checkOutcome is what storing a check result produced
+type checkOutcome struct {
+	check        models.MonitorCheck
+	incidentOpen bool // a down incident is open for the monitor after this check
+}
+
+// executeCheck performs the actual monitor check. It only returns an error
+// when the check could not be run at all and no result should be stored.
+func (s *Scheduler) executeCheck(ctx context.Context, monitor models.Monitor, location string) (checkRun, error) {
+	if monitor.Type == "group" {
+		return s.evaluateGroup(monitor)
+	}
+
+	checker, err := monitors.NewChecker(monitor.Type, monitor.Config)
+	if err != nil {
+		return checkRun{}, err
+	}
+
+	start := time.Now()
+	metrics, checkErr := checker(ctx)
+	responseTime := time.Since(start)
+
+	if checkErr != nil {
+		log.Printf("Monitor %d failed: %v", monitor.ID, checkErr)
+	} else {
+		log.Printf("Monitor %d succeeded in %v", monitor.ID, responseTime)
+	}
+
+	return checkRun{
+		location:     location,
+		err:          checkErr,
+		responseTime: responseTime,
+		metrics:      metrics,
+	}, nil
+}
+
+// classifyRun turns a check run into a stored status, applying the monitor's thresholds
+func classifyRun(monitor models.Monitor, run checkRun) (string, string, monitors.Metrics) {
+	metrics := monitors.Metrics{}
+	for name, value := range run.metrics {
+		metrics[name] = value
+	}
+	metrics[monitors.MetricResponseTime] = float64(run.responseTime.Milliseconds())
+
+	if run.status != "" {
+		return run.status, run.message, metrics
+	}
+
+	if run.err != nil {
+		return monitors.StatusFailure, run.err.Error(), metrics
+	}
+
+	status, reason := monitors.EvaluateThresholds(monitorThresholds(monitor), metrics)
+	return status, reason, metrics
+}
+
+// monitorThresholds collects the latency and metric limits configured on a monitor
+func monitorThresholds(monitor models.Monitor) map[string]types.MetricThreshold {
+	var metric map[string]types.MetricThreshold
+
+	if len(monitor.MetricThresholds) > 0 {
+		if err := json.Unmarshal(monitor.MetricThresholds, &metric); err != nil {
+			log.Printf("Invalid metric thresholds for monitor %d: %v", monitor.ID, err)
+		}
+	}
+
+	return monitors.Thresholds(monitor.WarningThreshold, monitor.CriticalThreshold, metric)
+}
+
+// storeCheckResult saves the check result and any incident change in a single
+// transaction, then sends notifications once the transaction has committed
+func (s *Scheduler) storeCheckResult(monitor models.Monitor, run checkRun) (checkOutcome, error) {
+	status, message, metrics := classifyRun(monitor, run)
+
+	metricsJSON, err := json.Marshal(metrics)
+	if err != nil {
+		return checkOutcome{}, err
+	}
+
+	now := time.Now()
+
+	var createdIncident, resolvedIncident, escalatedIncident *models.Incident
+	var previousSeverity string
+	var sslExpiringDays *int
+	sslRenewed := false
+	var check models.MonitorCheck
+	incidentOpen := false
+
+	txErr := db.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the monitor row so it cannot be deleted while the result is written
+		// and results from several locations are evaluated one at a time
+		var current models.Monitor
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "status").First(&current, monitor.ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errStaleMonitor
+			}
+			return err
+		}
+
+		if current.Status != "active" {
+			return errStaleMonitor
+		}
+
+		check = models.MonitorCheck{
+			MonitorID:    monitor.ID,
+			Location:     run.location,
+			Status:       status,
+			ResponseTime: int(run.responseTime.Milliseconds()),
+			Message:      message,
+			Metrics:      datatypes.JSON(metricsJSON),
+			CheckedAt:    now,
+		}
+
+		if err := tx.Create(&check).Error; err != nil {
+			return fmt.Errorf("failed to store check result: %w", err)
+		}
+
+		if days, ok := metrics[monitors.MetricSSLDaysRemaining]; ok {
+			expiring, renewed, err := markSSLExpiry(tx, monitor.ID, days, now)
+			if err != nil {
+				return fmt.Errorf("failed to track certificate expiry: %w", err)
+			}
+
+			if expiring {
+				remaining := int(math.Floor(days))
+				sslExpiringDays = &remaining
+			}
+			sslRenewed = renewed
+		}
+
+		state, failing, err := monitorState(tx, monitor, status)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate location quorum: %w", err)
+		}
+
+		var activeIncident models.Incident
+
+		if err := tx.Where("monitor_id = ? AND status IN ?", monitor.ID, models.OpenIncidentStatuses).First(&activeIncident).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("failed to check for active incident: %w", err)
+			}
+		}
+
+		resolve := func(reason string) error {
+			activeIncident.ResolvedAt = &now
+			activeIncident.Status = models.IncidentStatusResolved
+
+			if err := tx.Save(&activeIncident).Error; err != nil {
+				return fmt.Errorf("failed to resolve incident: %w", err)
+			}
+
+			if err := services.RecordIncidentEvent(tx, activeIncident.ID, models.IncidentEventResolved, nil, reason); err != nil {
+				return err
+			}
+
+			resolved := activeIncident
+			resolvedIncident = &resolved
+			activeIncident = models.Incident{}
+			return nil
+		}
+
+		open := func(kind string) error {
+			severity, _ := incidentSeverity(monitor, kind, "", 0, failing)
+
+			monitorID := monitor.ID
+
+			newIncident := models.Incident{
+				ProjectID:   monitor.ProjectID,
+				MonitorID:   &monitorID,
+				Source:      models.IncidentSourceCheck,
+				Kind:        kind,
+				Severity:    severity,
+				Status:      models.IncidentStatusActive,
+				StartedAt:   &now,
+				Title:       s.generateIncidentTitle(monitor, kind),
+				Description: s.generateIncidentDescription(monitor, run.location, message),
+			}
+
+			// Failures explained by a parent outage are attached to it instead of paging
+			root, err := rootCauseIncident(tx, monitor.ID)
+			if err != nil {
+				return fmt.Errorf("failed to check parent incidents: %w", err)
+			}
+
+			if root != nil {
+				newIncident.Status = models.IncidentStatusImpacted
+				newIncident.ParentIncidentID = &root.ID
+			} else {
+				if err := services.StartEscalation(tx, &newIncident, now); err != nil {
+					return err
+				}
+				if err := services.ScheduleReminders(tx, &newIncident, now); err != nil {
+					return err
+				}
+			}
+
+			if err := tx.Create(&newIncident).Error; err != nil {
+				return fmt.Errorf("failed to create incident: %w", err)
+			}
+
+			eventType, eventMessage := models.IncidentEventOpened, message
+			if root != nil {
+				eventType = models.IncidentEventImpacted
+				eventMessage = fmt.Sprintf("Caused by incident #%d: %s", root.ID, message)
+			}
+
+			if err := services.RecordIncidentEvent(tx, newIncident.ID, eventType, nil, eventMessage); err != nil {
+				return err
+			}
+
+			createdIncident = &newIncident
+			return nil
+		}
+
+		// reattach keeps an impacted incident pointing at an open root cause and
+		// turns it into an active incident once no parent explains it anymore
+		reattach := func() error {
+			if activeIncident.Status != models.IncidentStatusImpacted {
+				return nil
+			}
+
+			root, err := rootCauseIncident(tx, monitor.ID)
+			if err != nil {
+				return fmt.Errorf("failed to check parent incidents: %w", err)
+			}
+
+			if root != nil && activeIncident.ParentIncidentID != nil && *activeIncident.ParentIncidentID == root.ID {
+				return nil
+			}
+
+			if root != nil {
+				activeIncident.ParentIncidentID = &root.ID
+			} else {
+				activeIncident.Status = models.IncidentStatusActive
+				activeIncident.ParentIncidentID = nil
+
+				if err := services.StartEscalation(tx, &activeIncident, now); err != nil {
+					return err
+				}
+				if err := services.ScheduleReminders(tx, &activeIncident, now); err != nil {
+					return err
+				}
+			}
+
+			if err := tx.Save(&activeIncident).Error; err != nil {
+				return fmt.Errorf("failed to update impacted incident: %w", err)
+			}
+
+			if root == nil {
+				if err := services.RecordIncidentEvent(tx, activeIncident.ID, models.IncidentEventOpened, nil, "No open parent incident explains the failure anymore"); err != nil {
+					return err
+				}
+
+				promoted := activeIncident
+				createdIncident = &promoted
+			}
+			return nil
+		}
+
+		switch state {
+		case stateDown:
+			// A degraded incident is replaced by a down incident so that it gets paged
+			if activeIncident.ID != 0 && activeIncident.Kind == models.IncidentKindDegraded {
+				if err := resolve("Monitor went down"); err != nil {
+					return err
+				}
+			}
+
+			if err := reattach(); err != nil {
+				return err
+			}
+
+			if activeIncident.ID == 0 && status == monitors.StatusFailure {
+				if err := open(models.IncidentKindDown); err != nil {
+					return err
+				}
+			}
+
+			incidentOpen = activeIncident.ID != 0 || createdIncident != nil
+		case stateDegraded:
+			// Slow but up ends a down incident
+			if activeIncident.ID != 0 && activeIncident.Kind != models.IncidentKindDegraded {
+				if err := resolve("Monitor is up but degraded"); err != nil {
+					return err
+				}
+			}
+
+			if err := reattach(); err != nil {
+				return err
+			}
+
+			if activeIncident.ID == 0 && monitor.DegradedIncidents && status == monitors.StatusDegraded {
+				if err := open(models.IncidentKindDegraded); err != nil {
+					return err
+				}
+			}
+		default:
+			if activeIncident.ID != 0 {
+				if err := resolve("Monitor recovered"); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Rules raise the severity of an incident the longer it stays open
+		if activeIncident.ID != 0 && activeIncident.Status != models.IncidentStatusImpacted &&
+			!activeIncident.SeverityOverridden && activeIncident.StartedAt != nil {
+			severity, reason := incidentSeverity(monitor, activeIncident.Kind, activeIncident.Severity, now.Sub(*activeIncident.StartedAt), failing)
+
+			if severity != activeIncident.Severity {
+				previousSeverity = activeIncident.Severity
+				activeIncident.Severity = severity
+
+				if err := tx.Save(&activeIncident).Error; err != nil {
+					return fmt.Errorf("failed to escalate incident: %w", err)
+				}
+
+				eventMessage := fmt.Sprintf("Severity raised from %s to %s: %s", previousSeverity, severity, reason)
+				if err := services.RecordIncidentEvent(tx, activeIncident.ID, models.IncidentEventSeverity, nil, eventMessage); err != nil {
+					return err
+				}
+
+				if createdIncident != nil && createdIncident.ID == activeIncident.ID {
+					createdIncident.Severity = severity
+				} else if activeIncident.Status == models.IncidentStatusActive {
+					escalated := activeIncident
+					escalatedIncident = &escalated
+				}
+			}
+		}
+
+		// Further failures while an incident is already open go to its timeline
+		if status == monitors.StatusFailure && activeIncident.ID != 0 && createdIncident == nil {
+			failure := message
+			if len(monitor.Locations) > 1 {
+				failure = fmt.Sprintf("%s: %s", run.location, message)
+			}
+
+			if err := services.RecordIncidentEvent(tx, activeIncident.ID, models.IncidentEventCheckFailed, nil, failure); err != nil {
+				return err
+			}
+		}
+
+		// Notifications are queued in the outbox together with the changes they announce
+		notifyResolved := resolvedIncident != nil && resolvedIncident.ParentIncidentID == nil
+		notifyCreated := createdIncident != nil && createdIncident.Status != models.IncidentStatusImpacted
+
+		if !notifyResolved && !notifyCreated && escalatedIncident == nil && sslExpiringDays == nil && !sslRenewed {
+			return nil
+		}
+
+		var project models.Project
+		if err := tx.First(&project, monitor.ProjectID).Error; err != nil {
+			return fmt.Errorf("failed to load project for notification: %w", err)
+		}
+
+		// Impacted incidents are covered by the notifications of their root cause
+		if notifyResolved {
+			if err := services.QueueIncidentResolvedNotification(tx, project, *resolvedIncident); err != nil {
+				return err
+			}
+		}
+
+		if notifyCreated {
+			if err := services.QueueIncidentCreatedNotification(tx, project, *createdIncident); err != nil {
+				return err
+			}
+		}
+
+		// Incidents that were too minor to notify about are announced once a rule escalates them
+		if escalatedIncident != nil {
+			if err := services.QueueIncidentSeverityRaisedNotification(tx, project, *escalatedIncident, previousSeverity); err != nil {
+				return err
+			}
+		}
+
+		if sslExpiringDays != nil {
+			if err := services.QueueSSLExpiringNotification(tx, project, monitor, *sslExpiringDays); err != nil {
+				return err
+			}
+		}
+
+		if sslRenewed {
+			if err := services.QueueSSLRenewedNotification(tx, project, monitor); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if txErr != nil {
+		if errors.Is(txErr, errStaleMonitor) {
+			log.Printf("Discarding check result for monitor %d: %v", monitor.ID, txErr)
+		} else {
+			log.Printf("Failed to store check result for monitor %d: %v", monitor.ID, txErr)
+		}
+		return checkOutcome{check: check}, txErr
+	}
+
+	if resolvedIncident != nil && resolvedIncident.ParentIncidentID != nil {
+		log.Printf("Resolved impacted incident %d for monitor %d", resolvedIncident.ID, monitor.ID)
+	} else if resolvedIncident != nil {
+		log.Printf("Saved resolved active incident for monitor %d", monitor.ID)
+	}
+
+	if createdIncident != nil && createdIncident.Status == models.IncidentStatusImpacted {
+		log.Printf("Attached %s incident for monitor %d to root cause incident %d", createdIncident.Kind, monitor.ID, *createdIncident.ParentIncidentID)
+	} else if createdIncident != nil {
+		log.Printf("Opened %s incident for monitor %d", createdIncident.Kind, monitor.ID)
+	}
+
+	// Broadcast check completion to WebSocket clients
+	if s.broadcast != nil {
+		log.Printf("Broadcasting check completion for monitor %d, project %d", monitor.ID, monitor.ProjectID)
+		s.broadcast(strconv.FormatUint(uint64(monitor.ProjectID), 10))
+	}
+
+	s.updateGroups(monitor)
+
+	return checkOutcome{check: check, incidentOpen: incidentOpen}, nil
+}
+
+// markSSLExpiry records that an expiring certificate is announced, at most
+// once per sslExpiryRepeat, and forgets it once the certificate was renewed.
+// It reports whether the expiry should be announced now, and whether an
+// announced certificate was just renewed.
+func markSSLExpiry(tx *gorm.DB, monitorID uint, days float64, now time.Time) (bool, bool, error) {
+	query := tx.Model(&models.Monitor{}).Where("id = ?", monitorID)
+
+	if days > sslExpiryWarningDays {
+		result := query.Where("ssl_expiry_notified_at IS NOT NULL").UpdateColumn("ssl_expiry_notified_at", nil)
+		return false, result.RowsAffected > 0, result.Error
+	}
+
+	result := query.Where("ssl_expiry_notified_at IS NULL OR ssl_expiry_notified_at <= ?", now.Add(-sslExpiryRepeat)).
+		UpdateColumn("ssl_expiry_notified_at", now)
+
+	return result.RowsAffected > 0, false, result.Error
+}
+
+// rootCauseIncident returns the open down incident of a parent monitor that
+// explains failures of the monitor, following impacted parents to their root
+func rootCauseIncident(tx *gorm.DB, monitorID uint) (*models.Incident, error) {
+	var parent models.Incident
+
+	err := tx.Joins("JOIN monitor_dependencies ON monitor_dependencies.parent_id = incidents.monitor_id").
+		Where("monitor_dependencies.monitor_id = ? AND incidents.status IN ? AND incidents.kind = ?",
+			monitorID, models.OpenIncidentStatuses, models.IncidentKindDown).
+		Order("incidents.started_at").
+		First(&parent).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if parent.ParentIncidentID == nil {
+		return &parent, nil
+	}
+
+	var root models.Incident
+	if err := tx.First(&root, *parent.ParentIncidentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &parent, nil
+		}
+		return nil, err
+	}
+
+	return &root, nil
+}
+
+// checkLocations returns the locations a monitor is checked from
+func checkLocations(monitor models.Monitor) []string {
+	if len(monitor.Locations) == 0 {
+		return []string{types.LocalLocation}
+	}
+	return []string(monitor.Locations)
+}
+
+// runsLocally reports whether this server's scheduler should run the monitor
+func runsLocally(monitor models.Monitor) bool {
+	for _, location := range checkLocations(monitor) {
+		if location == types.LocalLocation {
+			return true
+		}
+	}
+	return false
+}
+
+// monitorState combines the latest result of every location into the overall
+// state of the monitor and the number of failing locations. The monitor is
+// down when at least quorum locations fail, and degraded when that many
+// locations are failing or slow. Only recent results count, so a location
+// that stopped reporting does not keep an incident open.
+func monitorState(tx *gorm.DB, monitor models.Monitor, status string) (string, int, error) {
+	// A group is evaluated as a whole, its latest result is its state
+	if monitor.Type == "group" {
+		switch status {
+		case monitors.StatusFailure:
+			return stateDown, 1, nil
+		case monitors.StatusDegraded:
+			return stateDegraded, 0, nil
+		default:
+			return stateHealthy, 0, nil
+		}
+	}
+
+	locations := checkLocations(monitor)
+
+	quorum := monitor.Quorum
+	if quorum < 1 {
+		quorum = 1
+	}
+	if quorum > len(locations) {
+		quorum = len(locations)
+	}
+
+	since := time.Now().Add(-2*monitorInterval(monitor) - time.Minute)
+
+	var latest []models.MonitorCheck
+	if err := tx.Raw(`SELECT DISTINCT ON (location) location, status
+		FROM monitor_checks
+		WHERE monitor_id = ? AND location IN ? AND checked_at > ?
+		ORDER BY location, checked_at DESC`, monitor.ID, locations, since).Scan(&latest).Error; err != nil {
+		return "", 0, err
+	}
+
+	failing, degraded := 0, 0
+	for _, check := range latest {
+		switch check.Status {
+		case monitors.StatusFailure:
+			failing++
+		case monitors.StatusDegraded:
+			degraded++
+		}
+	}
+
+	switch {
+	case failing >= quorum:
+		return stateDown, failing, nil
+	case failing+degraded >= quorum:
+		return stateDegraded, failing, nil
+	default:
+		return stateHealthy, failing, nil
+	}
+}
+
+// generateIncidentTitle creates a descriptive title for an incident
+func (s *Scheduler) generateIncidentTitle(monitor models.Monitor, kind string) string {
+	var title string
+
+	if kind == models.IncidentKindDegraded {
+		return fmt.Sprintf("Monitor '%s' is degraded", monitor.Name)
+	}
+
+	switch monitor.Type {
+	case "http":
+		title = fmt.Sprintf("HTTP monitor '%s' is down", monitor.Name)
+	case "dns":
+		title = fmt.Sprintf("DNS monitor '%s' is failing", monitor.Name)
+	case "database":
+		title = fmt.Sprintf("Database monitor '%s' is unreachable", monitor.Name)
+	case "group":
+		title = fmt.Sprintf("Group monitor '%s' is failing", monitor.Name)
+	default:
+		title = fmt.Sprintf("Monitor '%s' (%s) is failing", monitor.Name, monitor.Type)
+	}
+
+	return title
+}
+
+// generateIncidentDescription creates a detailed description for an incident
+func (s *Scheduler) generateIncidentDescription(monitor models.Monitor, location string, message string) string {
+	var description strings.Builder
+
+	description.WriteString(fmt.Sprintf("Monitor '%s' has failed.\n\n", monitor.Name))
+
+	// Add error details
+	if message != "" {
+		if len(monitor.Locations) > 1 {
+			description.WriteString(fmt.Sprintf("Error (%s): %s\n\n", location, message))
+		} else {
+			description.WriteString(fmt.Sprintf("Error: %s\n\n", message))
+		}
+	}
+
+	// Add monitor configuration details
+	description.WriteString("Monitor Configuration:\n")
+	description.WriteString(fmt.Sprintf("  Type: %s\n", monitor.Type))
+	description.WriteString(fmt.Sprintf("  Check Interval: %d seconds\n", monitor.Interval))
+
+	switch monitor.Type {
+	case "http":
+		var cfg types.HttpConfig
+		if json.Unmarshal(monitor.Config, &cfg) == nil {
+			description.WriteString(fmt.Sprintf("  URL: %s\n", cfg.URL))
+			description.WriteString(fmt.Sprintf("  Method: %s\n", cfg.Method))
+			description.WriteString(fmt.Sprintf("  Expected Status: %d\n", cfg.ExpectedStatus))
+			if cfg.Timeout > 0 {
+				description.WriteString(fmt.Sprintf("  Timeout: %d seconds\n", cfg.Timeout))
+			}
+		}
+	case "dns":
+		var cfg types.DNSConfig
+		if json.Unmarshal(monitor.Config, &cfg) == nil {
+			description.WriteString(fmt.Sprintf("  Domain: %s\n", cfg.Domain))
+			if cfg.RecordType != "" {
+				description.WriteString(fmt.Sprintf("  Record Type: %s\n", strings.ToUpper(cfg.RecordType)))
+			}
+			if cfg.Expected != "" {
+				description.WriteString(fmt.Sprintf("  Expected Value: %s\n", cfg.Expected))
+			}
+		}
+	case "database":
+		var cfg types.DatabaseConfig
+		if json.Unmarshal(monitor.Config, &cfg) == nil {
+			description.WriteString(fmt.Sprintf("  Database Type: %s\n", strings.ToUpper(cfg.Type)))
+			description.WriteString(fmt.Sprintf("  Host: %s:%d\n", cfg.Host, cfg.Port))
+			description.WriteString(fmt.Sprintf("  Database: %s\n", cfg.Database))
+			description.WriteString(fmt.Sprintf("  Username: %s\n", cfg.Username))
+		}
+	case "group":
+		var cfg types.GroupConfig
+		if json.Unmarshal(monitor.Config, &cfg) == nil {
+			description.WriteString(fmt.Sprintf("  Members: %v\n", cfg.Members))
+			description.WriteString(fmt.Sprintf("  Rule: %s\n", cfg.Rule))
+		}
+	}
+
+	if monitor.WarningThreshold > 0 {
+		description.WriteString(fmt.Sprintf("  Warning Threshold: %d ms\n", monitor.WarningThreshold))
+	}
+	if monitor.CriticalThreshold > 0 {
+		description.WriteString(fmt.Sprintf("  Critical Threshold: %d ms\n", monitor.CriticalThreshold))
+	}
+
+	return description.String()
+}
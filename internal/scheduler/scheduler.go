@@ -1,46 +1,116 @@
 package scheduler
 
 import (
+	"container/heap"
 	"context"
+	"database/sql"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"log"
-	"strconv"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/monocle-dev/monocle/db"
 	"github.com/monocle-dev/monocle/internal/models"
 	"github.com/monocle-dev/monocle/internal/monitors"
-	"github.com/monocle-dev/monocle/internal/services"
 	"github.com/monocle-dev/monocle/internal/types"
+	"github.com/monocle-dev/monocle/internal/utils"
 	"gorm.io/gorm"
 )
 
 type BroadcastFunc func(projectID string)
 
+const (
+	defaultWorkers         = 10
+	defaultHostConcurrency = 2
+	defaultShutdownTimeout = 10
+	hostRetryDelay         = time.Second
+	cancelWait             = 5 * time.Second        // how long cancelled checks get to return on shutdown
+	runPollInterval        = 500 * time.Millisecond // how often a follower looks for the result of a manual run
+)
+
+var (
+	// ErrRunsRemotely is returned for manual runs of monitors that only agents check
+	ErrRunsRemotely = errors.New("monitor is only checked by agents")
+
+	errJobRemoved = errors.New("monitor check was cancelled")
+)
+
 type Scheduler struct {
 	monitors  map[uint]*MonitorJob // monitor ID -> job
+	queue     jobQueue             // jobs ordered by next run time
 	mu        sync.RWMutex
-	ctx       context.Context
+	ctx       context.Context // stops dispatching new checks
 	cancel    context.CancelFunc
-	broadcast BroadcastFunc // callback for broadcasting updates
+	checkCtx  context.Context    // parent of every in-flight check
+	checkStop context.CancelFunc // aborts in-flight checks
+	broadcast BroadcastFunc      // callback for broadcasting updates
+
+	work      chan *MonitorJob // due jobs handed to the worker pool
+	wake      chan struct{}    // signals the dispatcher that the queue changed
+	wg        sync.WaitGroup
+	workers   int
+	drainWait time.Duration  // how long Stop waits for in-flight checks
+	hostLimit int            // max concurrent checks per target host
+	hostBusy  map[string]int // target host -> checks in flight
+	inFlight  int
+	metrics   schedulerMetrics
+
+	instance          string        // identifies this replica
+	lockConn          *sql.Conn     // session holding the leader lock, nil when not leader
+	electionInterval  time.Duration // how often leadership is checked
+	reconcileInterval time.Duration // how often the job set is fully reconciled
 }
 
 type MonitorJob struct {
-	monitor models.Monitor
-	ticker  *time.Ticker
-	cancel  context.CancelFunc
+	monitor     models.Monitor
+	ctx         context.Context // cancelled when the job is replaced or removed
+	cancel      context.CancelFunc
+	host        string    // target host used for concurrency limits
+	scheduledAt time.Time // slot the job is due in, keeps the monitor's phase
+	nextRun     time.Time // when the dispatcher may pick the job up
+	index       int       // position in the queue, -1 when not queued
+	running     bool
+	rerun       bool // run again as soon as the current check finishes
+	removed     bool
+	failures    int              // consecutive checks with an open incident, drives backoff
+	manual      bool             // the next check was asked for by hand and resets backoff
+	waiters     []chan runResult // manual runs waiting for the result of the next check
+}
+
+// runResult is the stored result of a check a manual run waited for
+type runResult struct {
+	check models.MonitorCheck
+	err   error
+}
+
+type schedulerMetrics struct {
+	lastLag    time.Duration
+	maxLag     time.Duration
+	avgLag     time.Duration
+	dispatched int64
 }
 
 // NewScheduler initializes a new Scheduler instance
 func NewScheduler() *Scheduler {
 	ctx, cancel := context.WithCancel(context.Background())
+	checkCtx, checkStop := context.WithCancel(context.Background())
 	return &Scheduler{
-		monitors: make(map[uint]*MonitorJob),
-		ctx:      ctx,
-		cancel:   cancel,
+		monitors:  make(map[uint]*MonitorJob),
+		ctx:       ctx,
+		cancel:    cancel,
+		checkCtx:  checkCtx,
+		checkStop: checkStop,
+		work:      make(chan *MonitorJob),
+		wake:      make(chan struct{}, 1),
+		workers:   utils.GetEnvInt("SCHEDULER_WORKERS", defaultWorkers),
+		drainWait: time.Duration(utils.GetEnvInt("SCHEDULER_SHUTDOWN_TIMEOUT", defaultShutdownTimeout)) * time.Second,
+		hostLimit: utils.GetEnvInt("SCHEDULER_HOST_CONCURRENCY", defaultHostConcurrency),
+		hostBusy:  make(map[string]int),
+
+		instance:          instanceID(),
+		electionInterval:  time.Duration(utils.GetEnvInt("SCHEDULER_ELECTION_INTERVAL", defaultElectionInterval)) * time.Second,
+		reconcileInterval: time.Duration(utils.GetEnvInt("SCHEDULER_RECONCILE_INTERVAL", defaultReconcileInterval)) * time.Second,
 	}
 }
 
@@ -49,71 +119,83 @@ func (s *Scheduler) SetBroadcastCallback(broadcast BroadcastFunc) {
 	s.broadcast = broadcast
 }
 
-// Start loads all active monitors and begins scheduling
+// Start begins scheduling and takes part in leader election. Monitors are
+// loaded once this instance becomes the leader.
 func (s *Scheduler) Start() error {
 	log.Println("Starting scheduler...")
 
-	var monitorsList []models.Monitor
-	if err := db.DB.Where("status = ?", "active").Find(&monitorsList).Error; err != nil {
+	if err := s.tryAcquireLeadership(); err != nil {
 		return err
 	}
 
-	for _, monitor := range monitorsList {
-		s.AddMonitor(monitor)
+	for i := 0; i < s.workers; i++ {
+		s.wg.Add(1)
+		go s.worker()
 	}
 
-	log.Printf("Scheduler started with %d monitors", len(monitorsList))
+	go s.dispatch()
+	go s.runElection()
+	go s.listenForChanges()
+
+	s.mu.RLock()
+	leader := s.isLeader()
+	s.mu.RUnlock()
+
+	if !leader {
+		log.Printf("Scheduler on %s started as follower, waiting for leadership", s.instance)
+	}
+
+	log.Printf("Scheduler started with %d workers", s.workers)
 	return nil
 }
 
-// Stop gracefully shuts down all monitor jobs
+// Stop stops dispatching new checks and waits up to the drain timeout for
+// in-flight checks to finish before cancelling them. Checks that ignore the
+// cancellation are left behind after cancelWait.
 func (s *Scheduler) Stop() {
 	log.Println("Stopping scheduler...")
-	s.cancel() // Cancel main context
+	s.cancel() // Stop handing out new checks
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	drained := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(drained)
+	}()
 
-	for _, job := range s.monitors {
-		job.ticker.Stop()
-		job.cancel()
+	select {
+	case <-drained:
+	case <-time.After(s.drainWait):
+		log.Printf("In-flight checks did not finish within %v, cancelling them", s.drainWait)
+		s.checkStop()
+
+		select {
+		case <-drained:
+		case <-time.After(cancelWait):
+			log.Printf("Cancelled checks did not return within %v, stopping without them", cancelWait)
+		}
 	}
 
-	s.monitors = make(map[uint]*MonitorJob)
+	s.checkStop()
+	s.stepDown()
+
 	log.Println("Scheduler stopped")
 }
 
-// AddMonitor starts monitoring for a specific monitor
+// AddMonitor starts monitoring for a specific monitor on the leader.
+// Followers do not run checks and ignore it.
 func (s *Scheduler) AddMonitor(monitor models.Monitor) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	// Stop existing job if it exists
-	if existingJob, exists := s.monitors[monitor.ID]; exists {
-		existingJob.ticker.Stop()
-		existingJob.cancel()
-	}
-
-	// Create new job
-	jobCtx, jobCancel := context.WithCancel(s.ctx)
-	ticker := time.NewTicker(time.Duration(monitor.Interval) * time.Second)
-
-	job := &MonitorJob{
-		monitor: monitor,
-		ticker:  ticker,
-		cancel:  jobCancel,
+	if !s.isLeader() {
+		s.mu.Unlock()
+		return
 	}
+	s.schedule(monitor, time.Now())
+	s.mu.Unlock()
 
-	s.monitors[monitor.ID] = job
+	s.notify()
 
-	// Start the monitoring goroutine with immediate check
-	go func() {
-		// Execute immediate check with a copy of monitor data
-		monitorCopy := monitor
-		s.executeCheck(monitorCopy)
-		// Then start regular monitoring
-		s.runMonitor(jobCtx, job)
-	}()
+	// The new job starts without backoff
+	storeEffectiveInterval(monitor, monitorInterval(monitor))
 
 	log.Printf("Added monitor %d (%s) with immediate check", monitor.ID, monitor.Name)
 }
@@ -124,263 +206,441 @@ func (s *Scheduler) RemoveMonitor(monitorID uint) {
 	defer s.mu.Unlock()
 
 	if job, exists := s.monitors[monitorID]; exists {
-		job.ticker.Stop()
+		job.removed = true
 		job.cancel()
+		releaseWaiters(job, errJobRemoved)
+		s.queue.remove(job)
 		delete(s.monitors, monitorID)
 		log.Printf("Removed monitor %d", monitorID)
 	}
 }
 
-// UpdateMonitor updates an existing monitor (stops old, starts new)
+// UpdateMonitor updates an existing monitor and runs it again immediately
 func (s *Scheduler) UpdateMonitor(monitor models.Monitor) {
-	s.AddMonitor(monitor) // AddMonitor handles stopping existing job
+	s.AddMonitor(monitor)
 }
 
-// runMonitor executes the actual monitoring logic
-func (s *Scheduler) runMonitor(ctx context.Context, job *MonitorJob) {
-	defer job.ticker.Stop()
+// schedule queues a monitor to run at the given time, replacing the settings
+// of an existing job. Callers must hold s.mu.
+func (s *Scheduler) schedule(monitor models.Monitor, runAt time.Time) {
+	job, exists := s.monitors[monitor.ID]
+
+	if !runsLocally(monitor) {
+		// Remote agents run this monitor, drop any local job
+		if exists {
+			job.removed = true
+			job.cancel()
+			releaseWaiters(job, ErrRunsRemotely)
+			s.queue.remove(job)
+			delete(s.monitors, monitor.ID)
+		}
+		return
+	}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-job.ticker.C:
-			// Get a safe copy of the monitor data under read lock
-			s.mu.RLock()
-			monitorCopy := job.monitor
-			s.mu.RUnlock()
+	if !exists {
+		job = &MonitorJob{index: -1}
+		s.monitors[monitor.ID] = job
+	}
 
-			s.executeCheck(monitorCopy)
-		}
+	if job.cancel != nil {
+		// Abort any in-flight check running against the previous version
+		job.cancel()
+	}
+
+	job.monitor = monitor
+	job.host = targetHost(monitor)
+	job.failures = 0
+	job.ctx, job.cancel = context.WithCancel(s.checkCtx)
+
+	if job.running {
+		// The in-flight check reschedules the job when it finishes
+		job.rerun = true
+		return
+	}
+
+	job.scheduledAt = runAt
+	job.nextRun = runAt
+
+	if job.index >= 0 {
+		heap.Fix(&s.queue, job.index)
+	} else {
+		heap.Push(&s.queue, job)
 	}
 }
 
-// executeCheck performs the actual monitor check
-func (s *Scheduler) executeCheck(monitor models.Monitor) {
-	start := time.Now()
-	var err error
+// notify wakes the dispatcher without blocking
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
 
-	switch monitor.Type {
-	case "http":
-		var cfg types.HttpConfig
-		if unmarshalErr := json.Unmarshal(monitor.Config, &cfg); unmarshalErr != nil {
-			log.Printf("Invalid HTTP config for monitor %d: %v", monitor.ID, unmarshalErr)
-			return
+// dispatch hands due jobs to the worker pool in next-run order
+func (s *Scheduler) dispatch() {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		s.mu.Lock()
+		wait := time.Hour
+
+		if job := s.queue.peek(); job != nil {
+			wait = time.Until(job.nextRun)
+
+			if wait <= 0 {
+				heap.Pop(&s.queue)
+
+				if job.host != "" && s.hostBusy[job.host] >= s.hostLimit {
+					// Target host is saturated, try again shortly
+					job.nextRun = time.Now().Add(hostRetryDelay)
+					heap.Push(&s.queue, job)
+					s.mu.Unlock()
+					continue
+				}
+
+				if job.host != "" {
+					s.hostBusy[job.host]++
+				}
+				job.running = true
+				s.mu.Unlock()
+
+				select {
+				case s.work <- job:
+				case <-s.ctx.Done():
+					return
+				}
+				continue
+			}
 		}
-		err = monitors.GetHTTP(&cfg)
-	case "dns":
-		var cfg types.DNSConfig
+		s.mu.Unlock()
 
-		if unmarshalErr := json.Unmarshal(monitor.Config, &cfg); unmarshalErr != nil {
-			log.Printf("Invalid DNS config for monitor %d: %v", monitor.ID, unmarshalErr)
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+
+		select {
+		case <-s.ctx.Done():
 			return
+		case <-s.wake:
+		case <-timer.C:
 		}
+	}
+}
 
-		err = monitors.CheckDNS(&cfg)
-	case "database":
-		var cfg types.DatabaseConfig
+// worker executes jobs handed out by the dispatcher
+func (s *Scheduler) worker() {
+	defer s.wg.Done()
 
-		if unmarshalErr := json.Unmarshal(monitor.Config, &cfg); unmarshalErr != nil {
-			log.Printf("Invalid Database config for monitor %d: %v", monitor.ID, unmarshalErr)
+	for {
+		select {
+		case <-s.ctx.Done():
 			return
+		case job := <-s.work:
+			s.runJob(job)
 		}
-
-		err = monitors.CheckDatabase(&cfg)
-	default:
-		log.Printf("Unsupported monitor type: %s", monitor.Type)
-		return
 	}
+}
 
-	responseTime := time.Since(start)
-	s.storeCheckResult(monitor, err, responseTime)
+// runJob executes a single check and puts the job back on the queue
+func (s *Scheduler) runJob(job *MonitorJob) {
+	s.mu.Lock()
+	monitorCopy := job.monitor
+	host := job.host
+	ctx := job.ctx
+	waiters, manual := job.waiters, job.manual
+	job.waiters, job.manual = nil, false
+	s.inFlight++
+	s.recordLag(time.Since(job.scheduledAt))
+	s.mu.Unlock()
+
+	run, checkErr := s.executeCheck(ctx, monitorCopy, types.LocalLocation)
+	ok := checkErr == nil
+	if !ok {
+		log.Printf("Cannot run monitor %d: %v", monitorCopy.ID, checkErr)
+	}
 
-	if err != nil {
-		log.Printf("Monitor %d failed: %v", monitor.ID, err)
-	} else {
-		log.Printf("Monitor %d succeeded in %v", monitor.ID, responseTime)
+	s.mu.RLock()
+	current := !job.removed && job.ctx == ctx
+	s.mu.RUnlock()
+
+	var outcome checkOutcome
+	stored := false
+	runErr := checkErr
+
+	// Throw away results of cancelled checks and of replaced or removed monitors
+	if ok && ctx.Err() == nil && current {
+		outcome, runErr = s.storeCheckResult(monitorCopy, run)
+		stored = runErr == nil
+	} else if ok {
+		log.Printf("Discarding result of cancelled or stale check for monitor %d", monitorCopy.ID)
+		runErr = errJobRemoved
 	}
-}
 
-// storeCheckResult saves the check result to database
-func (s *Scheduler) storeCheckResult(monitor models.Monitor, err error, responseTime time.Duration) {
-	status := "success"
-	message := ""
+	s.mu.Lock()
+	previousInterval := effectiveInterval(job)
+	interval := previousInterval
+	if stored && job.ctx == ctx {
+		if manual {
+			// A manual check snaps any backoff back to the normal interval
+			job.failures = 0
+			interval = effectiveInterval(job)
+		} else {
+			interval = updateBackoff(job, outcome.incidentOpen)
+		}
+	}
 
-	now := time.Now()
+	if !stored && ok && !job.removed && job.ctx != ctx {
+		// The monitor was replaced mid-check and runs again right away, the
+		// manual runs wait for that check instead
+		job.waiters = append(job.waiters, waiters...)
+		job.manual = job.manual || manual
+		waiters = nil
+	}
 
-	var activeIncident models.Incident
+	for _, waiter := range waiters {
+		waiter <- runResult{check: outcome.check, err: runErr}
+	}
 
-	if err := db.DB.Where("monitor_id = ? AND status = ?", monitor.ID, "active").First(&activeIncident).Error; err != nil {
-		if err != gorm.ErrRecordNotFound {
-			log.Printf("Failed to check for active incident for monitor %d: %v", monitor.ID, err)
+	s.inFlight--
+	if host != "" {
+		if s.hostBusy[host]--; s.hostBusy[host] <= 0 {
+			delete(s.hostBusy, host)
 		}
 	}
 
-	if err != nil {
-		status = "failure"
-		message = err.Error()
+	job.running = false
 
-		if activeIncident.ID == 0 {
-			// Create a new incident if none exists
-			title := s.generateIncidentTitle(monitor)
-			description := s.generateIncidentDescription(monitor, err)
+	if !job.removed {
+		now := time.Now()
 
-			newIncident := models.Incident{
-				MonitorID:   monitor.ID,
-				Status:      "active",
-				StartedAt:   &now,
-				Title:       title,
-				Description: description,
-			}
-
-			if projectErr := db.DB.Create(&newIncident).Error; projectErr != nil {
-				log.Printf("Failed to create incident for monitor %d: %v", monitor.ID, err)
-			} else {
-				log.Printf("Created new incident for monitor %d", monitor.ID)
-
-				var project models.Project
-				if err := db.DB.First(&project, monitor.ProjectID).Error; err == nil {
-					newIncident.Monitor = monitor
-					if notifyErr := services.SendIncidentCreatedNotification(project, newIncident); notifyErr != nil {
-						log.Printf("Failed to send incident created notification: %v", notifyErr)
-					} else {
-						log.Printf("Successfully sent incident created notification")
-					}
-				} else {
-					log.Printf("Failed to load project for notification: %v", projectErr)
-				}
+		if job.rerun {
+			job.rerun = false
+			job.scheduledAt = now
+		} else {
+			job.scheduledAt = job.scheduledAt.Add(interval)
 
-				// Broadcast incident creation to WebSocket clients
-				if s.broadcast != nil {
-					log.Printf("Broadcasting incident creation for monitor %d, project %d", monitor.ID, monitor.ProjectID)
-					s.broadcast(strconv.FormatUint(uint64(monitor.ProjectID), 10))
-				}
+			// Skip missed slots while keeping the monitor's phase
+			if job.scheduledAt.Before(now) {
+				missed := now.Sub(job.scheduledAt)/interval + 1
+				job.scheduledAt = job.scheduledAt.Add(missed * interval)
 			}
 		}
 
-	} else if activeIncident.ID != 0 {
-		activeIncident.ResolvedAt = &now
-		activeIncident.Status = "resolved"
+		job.nextRun = job.scheduledAt
+		heap.Push(&s.queue, job)
+	}
+	s.mu.Unlock()
 
-		if projectErr := db.DB.Save(&activeIncident).Error; projectErr != nil {
-			log.Printf("Failed to save active incident for monitor %d", monitor.ID)
-		} else {
-			log.Printf("Saved resolved active incident for monitor %d", monitor.ID)
+	if interval != previousInterval {
+		log.Printf("Monitor %d now checked every %v", monitorCopy.ID, interval)
+		storeEffectiveInterval(monitorCopy, interval)
+	}
 
-			var project models.Project
-			if err := db.DB.First(&project, monitor.ProjectID).Error; err == nil {
-				activeIncident.Monitor = monitor
-				if notifyErr := services.SendIncidentResolvedNotification(project, activeIncident); notifyErr != nil {
-					log.Printf("Failed to send incident resolved notification: %v", notifyErr)
-				}
-			} else {
-				log.Printf("Failed to load project for notification: %v", projectErr)
-			}
+	s.notify()
+}
 
-			// Broadcast incident resolution to WebSocket clients
-			if s.broadcast != nil {
-				log.Printf("Broadcasting incident resolution for monitor %d, project %d", monitor.ID, monitor.ProjectID)
-				s.broadcast(strconv.FormatUint(uint64(monitor.ProjectID), 10))
-			}
-		}
+// recordLag tracks how late jobs start compared to their slot. Callers must hold s.mu.
+func (s *Scheduler) recordLag(lag time.Duration) {
+	if lag < 0 {
+		lag = 0
 	}
 
-	check := models.MonitorCheck{
-		MonitorID:    monitor.ID,
-		Status:       status,
-		ResponseTime: int(responseTime.Milliseconds()),
-		Message:      message,
-		CheckedAt:    time.Now(),
+	s.metrics.dispatched++
+	s.metrics.lastLag = lag
+
+	if lag > s.metrics.maxLag {
+		s.metrics.maxLag = lag
 	}
 
-	if err := db.DB.Create(&check).Error; err != nil {
-		log.Printf("Failed to store check result for monitor %d: %v", monitor.ID, err)
+	// Exponentially weighted moving average
+	if s.metrics.dispatched == 1 {
+		s.metrics.avgLag = lag
 	} else {
-		// Broadcast check completion to WebSocket clients
-		if s.broadcast != nil {
-			log.Printf("Broadcasting check completion for monitor %d, project %d", monitor.ID, monitor.ProjectID)
-			s.broadcast(strconv.FormatUint(uint64(monitor.ProjectID), 10))
-		}
+		s.metrics.avgLag += (lag - s.metrics.avgLag) / 10
 	}
 }
 
-// generateIncidentTitle creates a descriptive title for an incident
-func (s *Scheduler) generateIncidentTitle(monitor models.Monitor) string {
-	var title string
+// RunNow runs a check for a monitor as soon as the scheduler can and returns
+// the stored result. On the leader the monitor's job is made due, so the check
+// keeps to the per-host limit and never overlaps a scheduled one. Followers
+// ask the leader through the monitors table. Monitors that only run on agents
+// cannot be checked on demand.
+func (s *Scheduler) RunNow(ctx context.Context, monitor models.Monitor) (types.CheckResult, error) {
+	if !runsLocally(monitor) {
+		return types.CheckResult{}, ErrRunsRemotely
+	}
 
-	switch monitor.Type {
-	case "http":
-		title = fmt.Sprintf("HTTP monitor '%s' is down", monitor.Name)
-	case "dns":
-		title = fmt.Sprintf("DNS monitor '%s' is failing", monitor.Name)
-	case "database":
-		title = fmt.Sprintf("Database monitor '%s' is unreachable", monitor.Name)
-	default:
-		title = fmt.Sprintf("Monitor '%s' (%s) is failing", monitor.Name, monitor.Type)
+	s.mu.Lock()
+	if !s.isLeader() {
+		s.mu.Unlock()
+		return requestRun(ctx, monitor)
 	}
 
-	return title
+	job, exists := s.monitors[monitor.ID]
+	if !exists {
+		s.schedule(monitor, time.Now())
+		job = s.monitors[monitor.ID]
+	}
+
+	done := make(chan runResult, 1)
+	job.waiters = append(job.waiters, done)
+	s.runSoon(job)
+	s.mu.Unlock()
+
+	s.notify()
+
+	select {
+	case result := <-done:
+		if result.err != nil {
+			return types.CheckResult{}, result.err
+		}
+		return checkResult(result.check), nil
+	case <-ctx.Done():
+		return types.CheckResult{}, ctx.Err()
+	}
 }
 
-// generateIncidentDescription creates a detailed description for an incident
-func (s *Scheduler) generateIncidentDescription(monitor models.Monitor, err error) string {
-	var description strings.Builder
+// runSoon makes a job due now for a manual check, or right after the check it
+// is running. Callers must hold s.mu.
+func (s *Scheduler) runSoon(job *MonitorJob) {
+	job.manual = true
+
+	if job.running {
+		job.rerun = true
+		return
+	}
+
+	job.scheduledAt = time.Now()
+	job.nextRun = job.scheduledAt
+
+	if job.index >= 0 {
+		heap.Fix(&s.queue, job.index)
+	} else {
+		heap.Push(&s.queue, job)
+	}
+}
 
-	description.WriteString(fmt.Sprintf("Monitor '%s' has failed.\n\n", monitor.Name))
+// requestRun asks the leader for a manual run by marking the monitor in the
+// database, then waits for the first check stored after the request
+func requestRun(ctx context.Context, monitor models.Monitor) (types.CheckResult, error) {
+	requestedAt := time.Now()
 
-	// Add error details
-	if err != nil {
-		description.WriteString(fmt.Sprintf("Error: %s\n\n", err.Error()))
+	if err := db.DB.Model(&models.Monitor{}).Where("id = ?", monitor.ID).
+		UpdateColumn("run_requested_at", requestedAt).Error; err != nil {
+		return types.CheckResult{}, err
 	}
 
-	// Add monitor configuration details
-	description.WriteString("Monitor Configuration:\n")
-	description.WriteString(fmt.Sprintf("  Type: %s\n", monitor.Type))
-	description.WriteString(fmt.Sprintf("  Check Interval: %d seconds\n", monitor.Interval))
+	ticker := time.NewTicker(runPollInterval)
+	defer ticker.Stop()
 
-	switch monitor.Type {
-	case "http":
-		var cfg types.HttpConfig
-		if json.Unmarshal(monitor.Config, &cfg) == nil {
-			description.WriteString(fmt.Sprintf("  URL: %s\n", cfg.URL))
-			description.WriteString(fmt.Sprintf("  Method: %s\n", cfg.Method))
-			description.WriteString(fmt.Sprintf("  Expected Status: %d\n", cfg.ExpectedStatus))
-			if cfg.Timeout > 0 {
-				description.WriteString(fmt.Sprintf("  Timeout: %d seconds\n", cfg.Timeout))
-			}
+	for {
+		var check models.MonitorCheck
+
+		err := db.DB.Where("monitor_id = ? AND location = ? AND checked_at >= ?", monitor.ID, types.LocalLocation, requestedAt).
+			Order("checked_at").
+			First(&check).Error
+		if err == nil {
+			return checkResult(check), nil
 		}
-	case "dns":
-		var cfg types.DNSConfig
-		if json.Unmarshal(monitor.Config, &cfg) == nil {
-			description.WriteString(fmt.Sprintf("  Domain: %s\n", cfg.Domain))
-			if cfg.RecordType != "" {
-				description.WriteString(fmt.Sprintf("  Record Type: %s\n", strings.ToUpper(cfg.RecordType)))
-			}
-			if cfg.Expected != "" {
-				description.WriteString(fmt.Sprintf("  Expected Value: %s\n", cfg.Expected))
-			}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return types.CheckResult{}, err
 		}
-	case "database":
-		var cfg types.DatabaseConfig
-		if json.Unmarshal(monitor.Config, &cfg) == nil {
-			description.WriteString(fmt.Sprintf("  Database Type: %s\n", strings.ToUpper(cfg.Type)))
-			description.WriteString(fmt.Sprintf("  Host: %s:%d\n", cfg.Host, cfg.Port))
-			description.WriteString(fmt.Sprintf("  Database: %s\n", cfg.Database))
-			description.WriteString(fmt.Sprintf("  Username: %s\n", cfg.Username))
+
+		select {
+		case <-ctx.Done():
+			return types.CheckResult{}, ctx.Err()
+		case <-ticker.C:
 		}
 	}
+}
 
-	return description.String()
+// honorRunRequest runs a monitor that a follower asked a manual check for and
+// clears the request. Only the leader takes requests, others keep them queued.
+func (s *Scheduler) honorRunRequest(monitor models.Monitor) {
+	if monitor.RunRequestedAt == nil {
+		return
+	}
+
+	s.mu.Lock()
+	job, exists := s.monitors[monitor.ID]
+	if !s.isLeader() || !exists {
+		s.mu.Unlock()
+		return
+	}
+	s.runSoon(job)
+	s.mu.Unlock()
+
+	s.notify()
+
+	// A newer request stays for the next reconcile
+	if err := db.DB.Model(&models.Monitor{}).Where("id = ? AND run_requested_at <= ?", monitor.ID, *monitor.RunRequestedAt).
+		UpdateColumn("run_requested_at", nil).Error; err != nil {
+		log.Printf("Failed to clear run request of monitor %d: %v", monitor.ID, err)
+	}
+}
+
+// checkResult is the API view of a stored check
+func checkResult(check models.MonitorCheck) types.CheckResult {
+	var metrics map[string]float64
+	if len(check.Metrics) > 0 {
+		_ = json.Unmarshal(check.Metrics, &metrics)
+	}
+
+	return types.CheckResult{
+		Status:       check.Status,
+		ResponseTime: check.ResponseTime,
+		Message:      check.Message,
+		Metrics:      metrics,
+		CheckedAt:    check.CheckedAt,
+	}
 }
 
-// GetStatus returns current scheduler status
+// releaseWaiters fails the manual runs waiting for a job that will not run
+func releaseWaiters(job *MonitorJob, err error) {
+	for _, waiter := range job.waiters {
+		waiter <- runResult{err: err}
+	}
+	job.waiters = nil
+}
+
+// GetStatus returns current scheduler status and queue metrics
 func (s *Scheduler) GetStatus() map[string]interface{} {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	now := time.Now()
+	queueDepth := 0
+	for _, job := range s.queue {
+		if !job.nextRun.After(now) {
+			queueDepth++
+		}
+	}
+
+	// Lag of the most overdue job still waiting for a worker
+	var pendingLag time.Duration
+	if job := s.queue.peek(); job != nil && job.scheduledAt.Before(now) {
+		pendingLag = now.Sub(job.scheduledAt)
+	}
+
 	return map[string]interface{}{
 		"active_monitors": len(s.monitors),
 		"running":         s.ctx.Err() == nil,
+		"instance":        s.instance,
+		"leader":          s.isLeader(),
+		"workers":         s.workers,
+		"in_flight":       s.inFlight,
+		"queue_depth":     queueDepth,
+		"scheduled":       len(s.queue),
+		"pending_lag_ms":  pendingLag.Milliseconds(),
+		"last_lag_ms":     s.metrics.lastLag.Milliseconds(),
+		"avg_lag_ms":      s.metrics.avgLag.Milliseconds(),
+		"max_lag_ms":      s.metrics.maxLag.Milliseconds(),
+		"checks_run":      s.metrics.dispatched,
 	}
 }
 
@@ -421,6 +681,40 @@ func UpdateMonitor(monitor models.Monitor) {
 	}
 }
 
+// RecordResult stores a check result reported from a location other than this
+// server, such as a remote probe agent
+func RecordResult(monitor models.Monitor, location string, checkErr error, responseTime time.Duration, metrics monitors.Metrics) (models.MonitorCheck, error) {
+	if globalScheduler == nil {
+		return models.MonitorCheck{}, errors.New("scheduler is not running")
+	}
+
+	run := checkRun{
+		location:     location,
+		err:          checkErr,
+		responseTime: responseTime,
+		metrics:      metrics,
+	}
+
+	outcome, err := globalScheduler.storeCheckResult(monitor, run)
+	return outcome.check, err
+}
+
+// RunNow runs an immediate check through the global scheduler
+func RunNow(ctx context.Context, monitor models.Monitor) (types.CheckResult, error) {
+	if globalScheduler == nil {
+		return types.CheckResult{}, errors.New("scheduler is not running")
+	}
+	return globalScheduler.RunNow(ctx, monitor)
+}
+
+// GetStatus returns the status of the global scheduler
+func GetStatus() map[string]interface{} {
+	if globalScheduler != nil {
+		return globalScheduler.GetStatus()
+	}
+	return map[string]interface{}{"running": false}
+}
+
 // SetBroadcastCallback sets the broadcast function for the global scheduler
 func SetBroadcastCallback(broadcast BroadcastFunc) {
 	if globalScheduler != nil {
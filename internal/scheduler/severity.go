@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/monocle-dev/monocle/internal/models"
+	"github.com/monocle-dev/monocle/internal/types"
+)
+
+// incidentSeverity returns the severity an incident should have after being
+// open for the given time with failing locations failing, together with the
+// reason when a rule raised it. Severities are only ever raised.
+func incidentSeverity(monitor models.Monitor, kind, current string, open time.Duration, failing int) (string, string) {
+	severity := current
+	if severity == "" {
+		severity = monitor.DefaultSeverity
+		if kind == models.IncidentKindDegraded {
+			severity = models.SeverityLow
+		} else if !models.ValidSeverity(severity) {
+			severity = models.SeverityMedium
+		}
+	}
+
+	reason := ""
+
+	for _, rule := range monitorSeverityRules(monitor) {
+		if models.SeverityRank(rule.Severity) <= models.SeverityRank(severity) {
+			continue
+		}
+		if rule.AfterMinutes > 0 && open < time.Duration(rule.AfterMinutes)*time.Minute {
+			continue
+		}
+		if rule.FailingLocations > 0 && failing < rule.FailingLocations {
+			continue
+		}
+
+		severity = rule.Severity
+		reason = describeSeverityRule(rule)
+	}
+
+	return severity, reason
+}
+
+// monitorSeverityRules parses the severity rules configured on a monitor
+func monitorSeverityRules(monitor models.Monitor) []types.SeverityRule {
+	var rules []types.SeverityRule
+
+	if len(monitor.SeverityRules) > 0 {
+		if err := json.Unmarshal(monitor.SeverityRules, &rules); err != nil {
+			log.Printf("Invalid severity rules for monitor %d: %v", monitor.ID, err)
+		}
+	}
+
+	return rules
+}
+
+func describeSeverityRule(rule types.SeverityRule) string {
+	var conditions []string
+
+	if rule.AfterMinutes > 0 {
+		conditions = append(conditions, fmt.Sprintf("open for %d minutes", rule.AfterMinutes))
+	}
+	if rule.FailingLocations > 0 {
+		conditions = append(conditions, fmt.Sprintf("%d locations failing", rule.FailingLocations))
+	}
+
+	return strings.Join(conditions, " and ")
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/monocle-dev/monocle/internal/models"
+	"github.com/monocle-dev/monocle/internal/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Outcomes of an ingested alert
+const (
+	AlertOpened       = "opened"
+	AlertDeduplicated = "deduplicated"
+	AlertResolved     = "resolved"
+	AlertIgnored      = "ignored" // A resolve event without an open incident
+)
+
+// InboundAlert is an alert another system sent to an alert source
+type InboundAlert struct {
+	DedupKey    string
+	Resolved    bool // Resolves the open incident of DedupKey instead of opening one
+	Title       string
+	Description string
+	Severity    string // The source's severity when empty
+	StartedAt   *time.Time
+}
+
+// alertSeverities maps the severity labels of common alerting tools to incident severities
+var alertSeverities = map[string]string{
+	"critical":  models.SeverityCritical,
+	"fatal":     models.SeverityCritical,
+	"emergency": models.SeverityCritical,
+	"page":      models.SeverityCritical,
+	"high":      models.SeverityHigh,
+	"error":     models.SeverityHigh,
+	"major":     models.SeverityHigh,
+	"medium":    models.SeverityMedium,
+	"warning":   models.SeverityMedium,
+	"warn":      models.SeverityMedium,
+	"low":       models.SeverityLow,
+	"minor":     models.SeverityLow,
+	"info":      models.SeverityLow,
+}
+
+// AlertSeverity converts a severity sent by another system, returning "" for unknown ones
+func AlertSeverity(value string) string {
+	return alertSeverities[strings.ToLower(strings.TrimSpace(value))]
+}
+
+// OpenIncident creates an active incident that is not tied to a failed check
+// and starts its escalation and reminders. Linked monitors are only recorded,
+// they keep opening their own incidents.
+func OpenIncident(tx *gorm.DB, incident *models.Incident, userID *uint, message string, now time.Time) error {
+	incident.Status = models.IncidentStatusActive
+
+	if incident.Kind == "" {
+		incident.Kind = models.IncidentKindDown
+	}
+
+	if incident.StartedAt == nil {
+		incident.StartedAt = &now
+	}
+
+	if err := StartEscalation(tx, incident, now); err != nil {
+		return err
+	}
+
+	if err := ScheduleReminders(tx, incident, now); err != nil {
+		return err
+	}
+
+	if err := tx.Omit("LinkedMonitors.*").Create(incident).Error; err != nil {
+		return fmt.Errorf("failed to create incident: %w", err)
+	}
+
+	return RecordIncidentEvent(tx, incident.ID, models.IncidentEventOpened, userID, message)
+}
+
+// IngestAlert opens, deduplicates or resolves the incident of an alert and
+// returns the outcome with the incident it applied to
+func IngestAlert(tx *gorm.DB, source models.AlertSource, alert InboundAlert, now time.Time) (string, models.Incident, error) {
+	var incident models.Incident
+
+	// Updating the source locks its row, so concurrent alerts with the same key share one incident
+	if err := tx.Model(&models.AlertSource{}).Where("id = ?", source.ID).Update("last_alert_at", now).Error; err != nil {
+		return "", incident, fmt.Errorf("failed to update alert source: %w", err)
+	}
+
+	err := tx.Where("alert_source_id = ? AND dedup_key = ? AND status IN ?", source.ID, alert.DedupKey, models.OpenIncidentStatuses).
+		Order("started_at DESC").
+		First(&incident).Error
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", incident, fmt.Errorf("failed to look up open incident: %w", err)
+	}
+
+	open := err == nil
+
+	if alert.Resolved {
+		if !open {
+			return AlertIgnored, incident, nil
+		}
+
+		incident.Status = models.IncidentStatusResolved
+		incident.ResolvedAt = &now
+
+		if err := tx.Omit(clause.Associations).Save(&incident).Error; err != nil {
+			return "", incident, fmt.Errorf("failed to resolve incident: %w", err)
+		}
+
+		if err := RecordIncidentEvent(tx, incident.ID, models.IncidentEventResolved, nil, "Resolved by alert source "+source.Name); err != nil {
+			return "", incident, err
+		}
+
+		return AlertResolved, incident, nil
+	}
+
+	if open {
+		if err := RecordIncidentEvent(tx, incident.ID, models.IncidentEventDeduplicated, nil, "Repeated alert from "+source.Name); err != nil {
+			return "", incident, err
+		}
+
+		return AlertDeduplicated, incident, nil
+	}
+
+	severity := alert.Severity
+	if severity == "" {
+		severity = source.Severity
+	}
+
+	sourceID := source.ID
+
+	incident = models.Incident{
+		ProjectID:     source.ProjectID,
+		Source:        models.IncidentSourceAlert,
+		AlertSourceID: &sourceID,
+		DedupKey:      alert.DedupKey,
+		Severity:      severity,
+		Title:         alert.Title,
+		Description:   alert.Description,
+		StartedAt:     alert.StartedAt,
+	}
+
+	if err := OpenIncident(tx, &incident, nil, "Opened by alert source "+source.Name, now); err != nil {
+		return "", incident, err
+	}
+
+	return AlertOpened, incident, nil
+}
+
+// AlertmanagerAlerts converts a Prometheus Alertmanager webhook into alerts,
+// keyed by the fingerprint Alertmanager gives every alert
+func AlertmanagerAlerts(payload types.AlertmanagerWebhook) []InboundAlert {
+	alerts := make([]InboundAlert, 0, len(payload.Alerts))
+
+	for _, am := range payload.Alerts {
+		alert := InboundAlert{
+			DedupKey:    am.Fingerprint,
+			Resolved:    am.Status == "resolved",
+			Title:       am.Annotations["summary"],
+			Description: am.Annotations["description"],
+			Severity:    AlertSeverity(am.Labels["severity"]),
+		}
+
+		if alert.DedupKey == "" {
+			alert.DedupKey = alertmanagerLabelKey(am.Labels)
+		}
+
+		if alert.Title == "" {
+			alert.Title = am.Labels["alertname"]
+		}
+		if alert.Title == "" {
+			alert.Title = "Alertmanager alert"
+		}
+
+		if alert.Description == "" {
+			alert.Description = am.Annotations["message"]
+		}
+
+		if am.GeneratorURL != "" {
+			alert.Description = strings.TrimSpace(alert.Description + "\n\nSource: " + am.GeneratorURL)
+		}
+
+		if !am.StartsAt.IsZero() {
+			startsAt := am.StartsAt
+			alert.StartedAt = &startsAt
+		}
+
+		alerts = append(alerts, alert)
+	}
+
+	return alerts
+}
+
+// alertmanagerLabelKey identifies an alert by its sorted labels, for senders
+// that predate fingerprints
+func alertmanagerLabelKey(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for name, value := range labels {
+		pairs = append(pairs, name+"="+value)
+	}
+
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,277 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/monocle-dev/monocle/internal/models"
+)
+
+// TelegramAPIURL is the Telegram Bot API, replaced by a stand-in server in tests
+var TelegramAPIURL = "https://api.telegram.org"
+
+// telegramMessageLimit is the longest message Telegram accepts
+const telegramMessageLimit = 4096
+
+// requiredWebhookURL validates the settings of channels that have no
+// project-level webhook to fall back to
+func requiredWebhookURL(config json.RawMessage) (string, error) {
+	settings, err := parseWebhookConfig(config)
+	if err != nil {
+		return "", err
+	}
+
+	if settings.WebhookURL == "" {
+		return "", errors.New("webhook_url is required")
+	}
+
+	if err := validateHTTPURL(settings.WebhookURL, "webhook_url"); err != nil {
+		return "", err
+	}
+
+	return settings.WebhookURL, nil
+}
+
+// teamsNotifier posts Adaptive Cards to a Microsoft Teams Workflows webhook
+type teamsNotifier struct{}
+
+type teamsFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// teamsElement is an Adaptive Card TextBlock or FactSet
+type teamsElement struct {
+	Type     string      `json:"type"`
+	Text     string      `json:"text,omitempty"`
+	Weight   string      `json:"weight,omitempty"`
+	Size     string      `json:"size,omitempty"`
+	Color    string      `json:"color,omitempty"`
+	IsSubtle bool        `json:"isSubtle,omitempty"`
+	Wrap     bool        `json:"wrap,omitempty"`
+	Facts    []teamsFact `json:"facts,omitempty"`
+}
+
+type teamsCard struct {
+	Schema  string         `json:"$schema"`
+	Type    string         `json:"type"`
+	Version string         `json:"version"`
+	Body    []teamsElement `json:"body"`
+	MSTeams map[string]any `json:"msteams,omitempty"`
+}
+
+type teamsAttachment struct {
+	ContentType string    `json:"contentType"`
+	Content     teamsCard `json:"content"`
+}
+
+type teamsRequest struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+// teamsColors maps message levels to Adaptive Card text colors
+var teamsColors = map[string]string{
+	LevelCritical: "Attention",
+	LevelWarning:  "Warning",
+	LevelResolved: "Good",
+	LevelInfo:     "Accent",
+}
+
+func (teamsNotifier) Validate(config json.RawMessage) error {
+	_, err := requiredWebhookURL(config)
+	return err
+}
+
+func (n teamsNotifier) Send(project models.Project, config json.RawMessage, event NotificationEvent) error {
+	return n.sendMessage(project, config, renderMessage(project, event, time.Now()))
+}
+
+func (teamsNotifier) sendMessage(project models.Project, config json.RawMessage, message Message) error {
+	target, err := requiredWebhookURL(config)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUndeliverable, err)
+	}
+
+	return postWebhook("Teams", target, teamsPayload(message))
+}
+
+func teamsPayload(message Message) teamsRequest {
+	facts := make([]teamsFact, 0, len(message.Fields))
+	for _, field := range message.Fields {
+		facts = append(facts, teamsFact{Title: field.Name, Value: field.Value})
+	}
+
+	return teamsRequest{
+		Type: "message",
+		Attachments: []teamsAttachment{
+			{
+				ContentType: "application/vnd.microsoft.card.adaptive",
+				Content: teamsCard{
+					Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+					Type:    "AdaptiveCard",
+					Version: "1.4",
+					Body: []teamsElement{
+						{Type: "TextBlock", Text: message.Heading(), Weight: "Bolder", Size: "Medium", Color: teamsColors[message.Level], Wrap: true},
+						{Type: "TextBlock", Text: message.Marked(func(s string) string { return "**" + s + "**" }), Wrap: true},
+						{Type: "FactSet", Facts: facts},
+						{Type: "TextBlock", Text: message.Footer(), Size: "Small", IsSubtle: true, Wrap: true},
+					},
+					MSTeams: map[string]any{"width": "Full"},
+				},
+			},
+		},
+	}
+}
+
+// mattermostConfig holds the settings of the Mattermost channel
+type mattermostConfig struct {
+	WebhookURL string `json:"webhook_url"`
+	Channel    string `json:"channel"` // Overrides the webhook's channel when set
+}
+
+type mattermostRequest struct {
+	Channel     string            `json:"channel,omitempty"`
+	Username    string            `json:"username"`
+	IconURL     string            `json:"icon_url,omitempty"`
+	Text        string            `json:"text"`
+	Attachments []SlackAttachment `json:"attachments"`
+}
+
+// mattermostNotifier posts Slack style attachments to a Mattermost incoming webhook
+type mattermostNotifier struct{}
+
+func parseMattermostConfig(config json.RawMessage) (mattermostConfig, error) {
+	var settings mattermostConfig
+
+	if err := json.Unmarshal(config, &settings); err != nil {
+		return settings, errors.New("Invalid channel settings")
+	}
+
+	if settings.WebhookURL == "" {
+		return settings, errors.New("webhook_url is required")
+	}
+
+	return settings, validateHTTPURL(settings.WebhookURL, "webhook_url")
+}
+
+func (mattermostNotifier) Validate(config json.RawMessage) error {
+	_, err := parseMattermostConfig(config)
+	return err
+}
+
+func (n mattermostNotifier) Send(project models.Project, config json.RawMessage, event NotificationEvent) error {
+	return n.sendMessage(project, config, renderMessage(project, event, time.Now()))
+}
+
+func (mattermostNotifier) sendMessage(project models.Project, config json.RawMessage, message Message) error {
+	settings, err := parseMattermostConfig(config)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUndeliverable, err)
+	}
+
+	return postWebhook("Mattermost", settings.WebhookURL, mattermostRequest{
+		Channel:     settings.Channel,
+		Username:    message.Username,
+		IconURL:     message.AvatarURL,
+		Text:        message.HeadingWith(func(s string) string { return "**" + s + "**" }),
+		Attachments: []SlackAttachment{slackAttachment(message, "**")},
+	})
+}
+
+// telegramConfig holds the settings of the Telegram channel
+type telegramConfig struct {
+	BotToken string `json:"bot_token"`
+	ChatID   string `json:"chat_id"` // A numeric chat ID or "@channelname"
+}
+
+type telegramRequest struct {
+	ChatID                string `json:"chat_id"`
+	Text                  string `json:"text"`
+	ParseMode             string `json:"parse_mode"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview"`
+}
+
+// telegramNotifier sends messages through the Telegram Bot API
+type telegramNotifier struct{}
+
+func parseTelegramConfig(config json.RawMessage) (telegramConfig, error) {
+	var settings telegramConfig
+
+	if err := json.Unmarshal(config, &settings); err != nil {
+		return settings, errors.New("Invalid channel settings")
+	}
+
+	if settings.BotToken == "" {
+		return settings, errors.New("bot_token is required")
+	}
+
+	if strings.ContainsAny(settings.BotToken, "/?#") {
+		return settings, errors.New("Invalid bot_token")
+	}
+
+	if settings.ChatID == "" {
+		return settings, errors.New("chat_id is required")
+	}
+
+	return settings, nil
+}
+
+func (telegramNotifier) Validate(config json.RawMessage) error {
+	_, err := parseTelegramConfig(config)
+	return err
+}
+
+func (n telegramNotifier) Send(project models.Project, config json.RawMessage, event NotificationEvent) error {
+	return n.sendMessage(project, config, renderMessage(project, event, time.Now()))
+}
+
+func (telegramNotifier) sendMessage(project models.Project, config json.RawMessage, message Message) error {
+	settings, err := parseTelegramConfig(config)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUndeliverable, err)
+	}
+
+	return postWebhook("Telegram", TelegramAPIURL+"/bot"+settings.BotToken+"/sendMessage", telegramRequest{
+		ChatID:                settings.ChatID,
+		Text:                  telegramText(message),
+		ParseMode:             "HTML",
+		DisableWebPagePreview: true,
+	})
+}
+
+// telegramText renders a message in Telegram's HTML subset
+func telegramText(message Message) string {
+	var text strings.Builder
+
+	fmt.Fprintf(&text, "<b>%s</b>\n%s\n\n", html.EscapeString(message.Heading()), telegramSummary(message))
+
+	for _, field := range message.Fields {
+		fmt.Fprintf(&text, "<b>%s:</b> %s\n", html.EscapeString(field.Name), html.EscapeString(truncate(field.Value, 512)))
+	}
+
+	fmt.Fprintf(&text, "\n<i>%s</i>", html.EscapeString(message.Footer()))
+
+	if len([]rune(text.String())) > telegramMessageLimit {
+		// Cutting the HTML could leave a tag open, fall back to the summary
+		return fmt.Sprintf("<b>%s</b>\n%s", html.EscapeString(message.Heading()), html.EscapeString(truncate(message.Summary, telegramMessageLimit-200)))
+	}
+
+	return text.String()
+}
+
+// telegramSummary escapes the summary of a message with its subject in bold
+func telegramSummary(message Message) string {
+	at := strings.Index(message.Summary, message.Subject)
+	if message.Subject == "" || at < 0 {
+		return html.EscapeString(message.Summary)
+	}
+
+	end := at + len(message.Subject)
+
+	return html.EscapeString(message.Summary[:at]) + "<b>" + html.EscapeString(message.Subject) + "</b>" + html.EscapeString(message.Summary[end:])
+}
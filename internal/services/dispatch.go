@@ -0,0 +1,372 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/monocle-dev/monocle/db"
+	"github.com/monocle-dev/monocle/internal/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// NotificationEvent is something that happened in a project that can be notified about
+type NotificationEvent struct {
+	Type     string          // One of the models.Trigger* constants
+	Incident models.Incident // Zero for monitor events
+	Monitor  models.Monitor  // The monitor of monitor events
+
+	PreviousSeverity string           // Set when an open incident is announced because its severity rose
+	Page             EscalationPage   // For incident_escalated
+	Reminder         IncidentReminder // For incident_reminder
+	User             *models.User     // Who acknowledged, for incident_acknowledged
+	SSLDaysRemaining int              // For ssl_expiring
+	SSLRenewed       bool             // For ssl_expiring, the certificate was renewed and its alert is resolved
+
+	Recipient      *models.User          // The user the notification rule notifies, set on delivery
+	NotificationID uint                  // The queued notification, set on delivery
+	RuleID         uint                  // The rule of the queued notification, set on delivery
+	Templates      NotificationTemplates // Of the notification rule, set on delivery
+	Test           bool                  // Made up by the test endpoint
+}
+
+// projectTriggers are the events sent to the project's own Discord and Slack webhooks
+var projectTriggers = map[string]bool{
+	models.TriggerIncidentCreated:  true,
+	models.TriggerMonitorDegraded:  true,
+	models.TriggerIncidentResolved: true,
+	models.TriggerIncidentReminder: true,
+}
+
+// QueueNotifications queues an event for the project's own webhooks and for
+// every active notification rule of the project that matches it. The
+// notifications are delivered by the outbox worker once tx commits.
+func QueueNotifications(tx *gorm.DB, project models.Project, event NotificationEvent) error {
+	if projectTriggers[event.Type] && severityMatches(project.NotifyMinSeverity, event) {
+		if project.DiscordWebhook != "" {
+			if err := queueDelivery(tx, project, nil, "discord", event); err != nil {
+				return err
+			}
+		}
+		if project.SlackWebhook != "" {
+			if err := queueDelivery(tx, project, nil, "slack", event); err != nil {
+				return err
+			}
+		}
+	}
+
+	rules, err := matchingRules(tx, project, event)
+	if err != nil {
+		return err
+	}
+
+	for i := range rules {
+		// A renewed certificate only closes the alerts paging services keep open
+		if event.SSLRenewed && !pagingChannels[rules[i].Channel] {
+			continue
+		}
+
+		if err := queueDelivery(tx, project, &rules[i], rules[i].Channel, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// matchingRules returns the active rules of a project that an event passes
+func matchingRules(tx *gorm.DB, project models.Project, event NotificationEvent) ([]models.NotificationRule, error) {
+	var rules []models.NotificationRule
+
+	if err := tx.Where("project_id = ? AND trigger_type = ? AND is_active", project.ID, event.Type).
+		Order("id").
+		Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to load notification rules: %w", err)
+	}
+
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	monitors, err := eventMonitors(tx, event)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := rules[:0]
+	for _, rule := range rules {
+		if ruleMatches(rule, event, monitors) {
+			matched = append(matched, rule)
+		}
+	}
+
+	return matched, nil
+}
+
+// eventMonitors loads the monitors an event is about with their current tags:
+// the monitor of a monitor event, or the monitor and linked monitors of an incident
+func eventMonitors(tx *gorm.DB, event NotificationEvent) ([]models.Monitor, error) {
+	ids := []uint{}
+
+	if event.Monitor.ID != 0 {
+		ids = append(ids, event.Monitor.ID)
+	}
+
+	if event.Incident.MonitorID != nil {
+		ids = append(ids, *event.Incident.MonitorID)
+	}
+
+	var monitors []models.Monitor
+
+	if err := tx.Where("id IN ? OR id IN (?)", ids,
+		tx.Table("incident_monitors").Select("monitor_id").Where("incident_id = ?", event.Incident.ID)).
+		Find(&monitors).Error; err != nil {
+		return nil, fmt.Errorf("failed to load monitors of event: %w", err)
+	}
+
+	return monitors, nil
+}
+
+// ruleMatches applies the monitor, tag and severity filters of a rule to an event
+func ruleMatches(rule models.NotificationRule, event NotificationEvent, monitors []models.Monitor) bool {
+	if !severityMatches(rule.MinSeverity, event) {
+		return false
+	}
+
+	if len(rule.MonitorIDs) > 0 {
+		wanted := make(map[int64]bool, len(rule.MonitorIDs))
+		for _, id := range rule.MonitorIDs {
+			wanted[id] = true
+		}
+
+		found := false
+		for _, monitor := range monitors {
+			found = found || wanted[int64(monitor.ID)]
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	if len(rule.Tags) > 0 {
+		wanted := make(map[string]bool, len(rule.Tags))
+		for _, tag := range rule.Tags {
+			wanted[tag] = true
+		}
+
+		found := false
+		for _, monitor := range monitors {
+			for _, tag := range monitor.Tags {
+				found = found || wanted[tag]
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// severityMatches reports whether the incident of an event is at least
+// minSeverity. An incident announced because its severity rose only matches
+// when it crossed minSeverity, so it is not announced twice. Monitor events
+// have no severity and always match.
+func severityMatches(minSeverity string, event NotificationEvent) bool {
+	if event.Incident.ID == 0 {
+		return true
+	}
+
+	limit := models.SeverityRank(minSeverity)
+
+	if event.PreviousSeverity != "" && models.SeverityRank(event.PreviousSeverity) >= limit {
+		return false
+	}
+
+	return models.SeverityRank(event.Incident.Severity) >= limit
+}
+
+// eventPayload holds what a queued notification needs to rebuild its event
+// besides the incident and monitor it references
+type eventPayload struct {
+	Page             *pagePayload      `json:"page,omitempty"`
+	Reminder         *IncidentReminder `json:"reminder,omitempty"`
+	SSLDaysRemaining int               `json:"ssl_days_remaining,omitempty"`
+	SSLRenewed       bool              `json:"ssl_renewed,omitempty"`
+}
+
+// pagePayload is an EscalationPage with its users stored by ID
+type pagePayload struct {
+	Policy   string   `json:"policy"`
+	Level    int      `json:"level"`
+	Levels   int      `json:"levels"`
+	UserIDs  []uint   `json:"user_ids"`
+	Channels []string `json:"channels"`
+}
+
+// queueDelivery stores a pending notification of an event over a channel, for
+// a rule or for the project's own webhooks when rule is nil
+func queueDelivery(tx *gorm.DB, project models.Project, rule *models.NotificationRule, channel string, event NotificationEvent) error {
+	payload := eventPayload{SSLDaysRemaining: event.SSLDaysRemaining, SSLRenewed: event.SSLRenewed}
+
+	switch event.Type {
+	case models.TriggerIncidentEscalated:
+		page := pagePayload{
+			Policy:   event.Page.Policy,
+			Level:    event.Page.Level,
+			Levels:   event.Page.Levels,
+			Channels: event.Page.Channels,
+		}
+		for _, user := range event.Page.Users {
+			page.UserIDs = append(page.UserIDs, user.ID)
+		}
+		payload.Page = &page
+	case models.TriggerIncidentReminder:
+		reminder := event.Reminder
+		payload.Reminder = &reminder
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification payload: %w", err)
+	}
+
+	now := time.Now()
+	due := now
+
+	// Notifications of digest rules wait for the next digest
+	if rule != nil && rule.Digest != "" {
+		due = nextDigestAt(rule.Digest, now)
+	}
+
+	destination := notificationDestination(project, rule, channel)
+
+	notification := models.Notification{
+		ProjectID:     project.ID,
+		EventType:     event.Type,
+		Channel:       channel,
+		Status:        models.NotificationStatusPending,
+		Message:       describeEvent(event),
+		NextAttemptAt: &due,
+		Payload:       datatypes.JSON(data),
+		Destination:   destination,
+		GroupKey:      notificationGroupKey(project, rule, channel, destination, event),
+	}
+
+	if event.Incident.ID != 0 {
+		incidentID := event.Incident.ID
+		notification.IncidentID = &incidentID
+		notification.MonitorID = event.Incident.MonitorID
+	}
+
+	if event.Monitor.ID != 0 {
+		monitorID := event.Monitor.ID
+		notification.MonitorID = &monitorID
+	}
+
+	// The recipient of schedule rules is only known when the notification is delivered
+	if rule != nil {
+		ruleID := rule.ID
+		notification.RuleID = &ruleID
+		notification.UserID = rule.UserID
+	}
+
+	if err := tx.Create(&notification).Error; err != nil {
+		return fmt.Errorf("failed to queue %s notification: %w", channel, err)
+	}
+
+	return nil
+}
+
+// recipientID returns the user a notification goes to when it is delivered at
+// a time. Notifications of schedule rules go to whoever is on call then, or to
+// nobody when no one is.
+func recipientID(tx *gorm.DB, notification models.Notification, rule *models.NotificationRule, at time.Time) (*uint, error) {
+	if rule == nil || rule.ScheduleID == nil {
+		return notification.UserID, nil
+	}
+
+	userID, onCall, err := OnCallUser(tx, *rule.ScheduleID, at)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: schedule %d was deleted", ErrUndeliverable, *rule.ScheduleID)
+		}
+		return nil, fmt.Errorf("failed to resolve on-call user of schedule %d: %w", *rule.ScheduleID, err)
+	}
+
+	if !onCall {
+		return nil, nil
+	}
+
+	return &userID, nil
+}
+
+// loadRecipient loads the user a notification goes to when it is delivered at a time
+func loadRecipient(notification models.Notification, rule *models.NotificationRule, at time.Time) (*models.User, error) {
+	userID, err := recipientID(db.DB, notification, rule, at)
+	if err != nil || userID == nil {
+		return nil, err
+	}
+
+	var user models.User
+	if err := db.DB.First(&user, *userID).Error; err == nil {
+		return &user, nil
+	}
+
+	// A deleted user is no longer addressed
+	return nil, nil
+}
+
+// DeliveryLabel names a notification on the incident timeline
+func DeliveryLabel(notification models.Notification) string {
+	label := notification.Channel
+
+	switch notification.EventType {
+	case models.TriggerIncidentReminder:
+		label += " reminder"
+	case models.TriggerIncidentEscalated:
+		label += " escalation"
+	case models.TriggerIncidentAcknowledged:
+		label += " acknowledgement"
+	}
+
+	if strings.HasSuffix(notification.GroupKey, ":digest") {
+		label += " digest"
+	}
+
+	if notification.RuleID != nil {
+		label += fmt.Sprintf(" (rule #%d)", *notification.RuleID)
+	}
+
+	return label
+}
+
+// describeEvent summarizes an event for the notification log
+func describeEvent(event NotificationEvent) string {
+	switch event.Type {
+	case models.TriggerIncidentCreated:
+		return fmt.Sprintf("Incident #%d opened: %s", event.Incident.ID, event.Incident.Title)
+	case models.TriggerMonitorDegraded:
+		return fmt.Sprintf("Degraded incident #%d opened: %s", event.Incident.ID, event.Incident.Title)
+	case models.TriggerIncidentResolved:
+		return fmt.Sprintf("Incident #%d resolved", event.Incident.ID)
+	case models.TriggerIncidentAcknowledged:
+		return fmt.Sprintf("Incident #%d acknowledged", event.Incident.ID)
+	case models.TriggerIncidentEscalated:
+		return fmt.Sprintf("Escalation level %d of %s for incident #%d", event.Page.Level, event.Page.Policy, event.Incident.ID)
+	case models.TriggerIncidentReminder:
+		return fmt.Sprintf("Reminder %d for incident #%d", event.Reminder.Count, event.Incident.ID)
+	case models.TriggerSSLExpiring:
+		if event.SSLRenewed {
+			return fmt.Sprintf("SSL certificate of %s was renewed", event.Monitor.Name)
+		}
+		return fmt.Sprintf("SSL certificate of %s: %s", event.Monitor.Name, sslExpiryText(event.SSLDaysRemaining))
+	}
+
+	return event.Type
+}
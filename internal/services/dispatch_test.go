@@ -0,0 +1,65 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/monocle-dev/monocle/internal/models"
+	"github.com/monocle-dev/monocle/internal/oncall"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// A notification of a schedule rule goes to whoever is on call when it is
+// delivered, not to whoever was on call when it was queued
+func TestRecipientFollowsRotationHandoff(t *testing.T) {
+	schedule := models.OnCallSchedule{
+		Timezone: "UTC",
+		Layers:   datatypes.JSON(`[{"name":"Daily","rotation":"daily","start":"2026-01-05T09:00:00Z","participants":[4,7]}]`),
+	}
+	schedule.ID = 2
+
+	loadOnCallSchedule = func(tx *gorm.DB, scheduleID uint) (models.OnCallSchedule, oncall.Schedule, error) {
+		rotation, err := BuildOnCallSchedule(schedule, nil)
+		return schedule, rotation, err
+	}
+	t.Cleanup(func() { loadOnCallSchedule = LoadOnCallSchedule })
+
+	scheduleID := schedule.ID
+	rule := models.NotificationRule{ScheduleID: &scheduleID}
+	notification := models.Notification{}
+
+	queuedAt := time.Date(2026, 1, 6, 8, 55, 0, 0, time.UTC)
+	deliveredAt := time.Date(2026, 1, 6, 9, 5, 0, 0, time.UTC) // After the handoff at 09:00
+
+	before, err := recipientID(nil, notification, &rule, queuedAt)
+	if err != nil {
+		t.Fatalf("recipient before handoff: %v", err)
+	}
+	if before == nil || *before != 4 {
+		t.Fatalf("recipient before handoff = %v, want user 4", before)
+	}
+
+	after, err := recipientID(nil, notification, &rule, deliveredAt)
+	if err != nil {
+		t.Fatalf("recipient after handoff: %v", err)
+	}
+	if after == nil || *after != 7 {
+		t.Errorf("recipient after handoff = %v, want user 7", after)
+	}
+}
+
+// Notifications of user rules keep the user they were queued for
+func TestRecipientOfUserRule(t *testing.T) {
+	userID := uint(9)
+	rule := models.NotificationRule{UserID: &userID}
+	notification := models.Notification{UserID: &userID}
+
+	got, err := recipientID(nil, notification, &rule, time.Now())
+	if err != nil {
+		t.Fatalf("recipient: %v", err)
+	}
+	if got == nil || *got != userID {
+		t.Errorf("recipient = %v, want user %d", got, userID)
+	}
+}
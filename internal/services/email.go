@@ -0,0 +1,323 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/monocle-dev/monocle/db"
+	"github.com/monocle-dev/monocle/internal/models"
+)
+
+// emailConfig holds the settings of the email channel. Without any recipients
+// the user the rule notifies is emailed.
+type emailConfig struct {
+	Addresses      []string `json:"addresses"`       // Explicit recipients
+	ProjectMembers bool     `json:"project_members"` // Email the owner and every member of the project
+}
+
+type emailNotifier struct{}
+
+func parseEmailConfig(config json.RawMessage) (emailConfig, error) {
+	var settings emailConfig
+
+	if len(config) == 0 || string(config) == "null" {
+		return settings, nil
+	}
+
+	if err := json.Unmarshal(config, &settings); err != nil {
+		return settings, errors.New("Invalid channel settings")
+	}
+
+	return settings, nil
+}
+
+func (emailNotifier) Validate(config json.RawMessage) error {
+	if mailTransport == nil {
+		return errors.New("Email is not configured on this server")
+	}
+
+	settings, err := parseEmailConfig(config)
+	if err != nil {
+		return err
+	}
+
+	for _, address := range settings.Addresses {
+		if _, err := mail.ParseAddress(address); err != nil {
+			return fmt.Errorf("Invalid email address '%s'", address)
+		}
+	}
+
+	return nil
+}
+
+func (n emailNotifier) Send(project models.Project, config json.RawMessage, event NotificationEvent) error {
+	now := time.Now()
+	return n.send(project, config, event.Recipient, emailContentFor(project, event, now), threadOf(event), now)
+}
+
+// sendMessage emails a message that is not about a single event, such as a
+// digest or a group of events. It starts its own thread.
+func (n emailNotifier) sendMessage(project models.Project, config json.RawMessage, message Message) error {
+	content := messageEmailContent(message)
+	content.Subject = fmt.Sprintf("[%s] %s", project.Name, message.Title)
+
+	return n.send(project, config, message.Recipient, content, emailThread{Kind: "summary"}, message.Timestamp)
+}
+
+func (emailNotifier) send(project models.Project, config json.RawMessage, recipient *models.User, content emailContent, thread emailThread, now time.Time) error {
+	if mailTransport == nil {
+		return fmt.Errorf("%w: email is not configured", ErrUndeliverable)
+	}
+
+	settings, err := parseEmailConfig(config)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUndeliverable, err)
+	}
+
+	recipients, err := emailRecipients(project, settings, recipient)
+	if err != nil {
+		return err
+	}
+
+	message, err := buildEmail(content, thread, recipients, now)
+	if err != nil {
+		return err
+	}
+
+	return mailTransport.Send(mailFrom.Address, recipients, message)
+}
+
+// emailRecipients returns the addresses an email goes to, without duplicates.
+// recipient is the user of the notification rule, emailed when the settings
+// name nobody.
+func emailRecipients(project models.Project, settings emailConfig, recipient *models.User) ([]string, error) {
+	recipients := []string{}
+	seen := make(map[string]bool)
+
+	add := func(address string) {
+		key := strings.ToLower(address)
+		if address != "" && !seen[key] {
+			seen[key] = true
+			recipients = append(recipients, address)
+		}
+	}
+
+	for _, address := range settings.Addresses {
+		if parsed, err := mail.ParseAddress(address); err == nil {
+			add(parsed.Address)
+		}
+	}
+
+	if settings.ProjectMembers {
+		var emails []string
+
+		if err := db.DB.Model(&models.User{}).
+			Where("id = ? OR id IN (?)", project.OwnerID,
+				db.DB.Model(&models.ProjectMembership{}).Select("user_id").Where("project_id = ?", project.ID)).
+			Order("id").
+			Pluck("email", &emails).Error; err != nil {
+			return nil, fmt.Errorf("failed to load project members: %w", err)
+		}
+
+		for _, email := range emails {
+			add(email)
+		}
+	}
+
+	if len(recipients) == 0 && recipient != nil {
+		add(recipient.Email)
+	}
+
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("%w: no email recipients", ErrUndeliverable)
+	}
+
+	return recipients, nil
+}
+
+// emailContent is what an email says, rendered as HTML and plain text
+type emailContent struct {
+	Subject string
+	Heading string
+	Summary string
+	Color   string // Accent of the HTML version
+	Fields  []MessageField
+	Footer  string
+}
+
+var emailHTMLTemplate = htmltemplate.Must(htmltemplate.New("email").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: -apple-system, 'Segoe UI', Helvetica, Arial, sans-serif; color: #222;">
+<div style="border-left: 4px solid {{.Color}}; padding: 12px 16px;">
+<h2 style="margin: 0 0 8px;">{{.Heading}}</h2>
+<p style="margin: 0 0 12px; white-space: pre-line;">{{.Summary}}</p>
+<table cellpadding="4" cellspacing="0">
+{{- range .Fields}}
+<tr><td style="color: #666; vertical-align: top;"><strong>{{.Name}}</strong></td><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+</div>
+<p style="color: #999; font-size: 12px;">{{.Footer}}</p>
+</body>
+</html>
+`))
+
+var emailTextTemplate = template.Must(template.New("email").Parse(`{{.Heading}}
+
+{{.Summary}}
+
+{{range .Fields}}{{.Name}}: {{.Value}}
+{{end}}
+{{.Footer}}
+`))
+
+// emailThread places an email in a thread of the mail client. The first email
+// of a rule about an incident opens the thread, and the later ones reply to it.
+type emailThread struct {
+	IncidentID     uint   // Zero for emails that stand alone
+	RuleID         uint   // The rule sending the email, each rule has a thread of its own
+	NotificationID uint   // The queued notification, part of the Message-ID
+	Kind           string // What the email is about, part of the Message-ID
+	Opens          bool   // The first email about the incident
+}
+
+// threadOf returns the thread of the email about an event
+func threadOf(event NotificationEvent) emailThread {
+	return emailThread{
+		IncidentID:     event.Incident.ID,
+		RuleID:         event.RuleID,
+		NotificationID: event.NotificationID,
+		Kind:           event.Type,
+		Opens:          event.Type == models.TriggerIncidentCreated || event.Type == models.TriggerMonitorDegraded,
+	}
+}
+
+// messageEmailContent lays out a message for an email, without a subject
+func messageEmailContent(message Message) emailContent {
+	return emailContent{
+		Heading: message.Heading(),
+		Summary: message.Summary,
+		Color:   message.HexColor(),
+		Fields:  message.Fields,
+		Footer:  message.Footer(),
+	}
+}
+
+// emailContentFor describes an event for an email
+func emailContentFor(project models.Project, event NotificationEvent, now time.Time) emailContent {
+	content := messageEmailContent(renderMessage(project, event, now))
+
+	switch {
+	case event.Type == models.TriggerSSLExpiring:
+		content.Subject = fmt.Sprintf("[%s] %s", project.Name, describeEvent(event))
+	case event.Test:
+		content.Subject = fmt.Sprintf("[%s] Test: %s", project.Name, event.Incident.Title)
+	default:
+		content.Subject = fmt.Sprintf("[%s] Incident #%d: %s", project.Name, event.Incident.ID, event.Incident.Title)
+		if event.Type != models.TriggerIncidentCreated && event.Type != models.TriggerMonitorDegraded {
+			content.Subject = "Re: " + content.Subject
+		}
+	}
+
+	return content
+}
+
+// emailThreadID is the Message-ID of the email that opens the thread of a rule
+// about an incident. It is scoped to the rule, since mail servers drop a
+// Message-ID they have seen and several rules email the same incident. A
+// retry after a send that did get through is dropped the same way.
+func emailThreadID(incidentID, ruleID uint, domain string) string {
+	return fmt.Sprintf("<incident-%d.rule-%d@%s>", incidentID, ruleID, domain)
+}
+
+// buildEmail renders content as a multipart HTML and plain text message
+func buildEmail(content emailContent, thread emailThread, recipients []string, now time.Time) ([]byte, error) {
+	var html, text bytes.Buffer
+
+	if err := emailHTMLTemplate.Execute(&html, content); err != nil {
+		return nil, fmt.Errorf("failed to render email: %w", err)
+	}
+
+	if err := emailTextTemplate.Execute(&text, content); err != nil {
+		return nil, fmt.Errorf("failed to render email: %w", err)
+	}
+
+	domain := "monocle"
+	if at := strings.LastIndex(mailFrom.Address, "@"); at >= 0 {
+		domain = mailFrom.Address[at+1:]
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain", text.String()},
+		{"text/html", html.String()},
+	} {
+		writer, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType + "; charset=UTF-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		encoder := quotedprintable.NewWriter(writer)
+		if _, err := encoder.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	var message bytes.Buffer
+
+	header := func(name, value string) {
+		fmt.Fprintf(&message, "%s: %s\r\n", name, value)
+	}
+
+	header("From", mailFrom.String())
+	header("To", strings.Join(recipients, ", "))
+	// Titles come from users and alerts, line breaks would start new headers
+	subject := strings.NewReplacer("\r", " ", "\n", " ").Replace(content.Subject)
+	header("Subject", mime.QEncoding.Encode("UTF-8", subject))
+	header("Date", now.Format(time.RFC1123Z))
+
+	switch {
+	case thread.IncidentID != 0 && thread.Opens:
+		header("Message-ID", emailThreadID(thread.IncidentID, thread.RuleID, domain))
+	case thread.NotificationID != 0:
+		header("Message-ID", fmt.Sprintf("<notification-%d.%d@%s>", thread.NotificationID, now.UnixNano(), domain))
+	default:
+		header("Message-ID", fmt.Sprintf("<%s.%d@%s>", thread.Kind, now.UnixNano(), domain))
+	}
+
+	if thread.IncidentID != 0 && !thread.Opens {
+		anchor := emailThreadID(thread.IncidentID, thread.RuleID, domain)
+		header("In-Reply-To", anchor)
+		header("References", anchor)
+	}
+
+	header("MIME-Version", "1.0")
+	header("Content-Type", "multipart/alternative; boundary="+parts.Boundary())
+	message.WriteString("\r\n")
+	message.Write(body.Bytes())
+
+	return message.Bytes(), nil
+}
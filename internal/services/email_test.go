@@ -0,0 +1,192 @@
+package services
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/monocle-dev/monocle/internal/models"
+)
+
+// sentEmail is a message handed to recordingTransport
+type sentEmail struct {
+	from    string
+	to      []string
+	raw     string
+	message *mail.Message
+	parts   map[string]string // Decoded body by content type
+}
+
+// recordingTransport keeps the messages it is asked to send
+type recordingTransport struct {
+	t    *testing.T
+	sent []sentEmail
+}
+
+func (r *recordingTransport) Send(from string, to []string, message []byte) error {
+	parsed, err := mail.ReadMessage(strings.NewReader(string(message)))
+	if err != nil {
+		r.t.Fatalf("invalid message: %v", err)
+	}
+
+	_, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil {
+		r.t.Fatalf("invalid Content-Type: %v", err)
+	}
+
+	parts := make(map[string]string)
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			r.t.Fatalf("invalid part: %v", err)
+		}
+
+		// NextPart decodes quoted-printable parts
+		body, err := io.ReadAll(part)
+		if err != nil {
+			r.t.Fatalf("invalid part body: %v", err)
+		}
+
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[contentType] = string(body)
+	}
+
+	r.sent = append(r.sent, sentEmail{from: from, to: to, raw: string(message), message: parsed, parts: parts})
+	return nil
+}
+
+func useRecordingTransport(t *testing.T) *recordingTransport {
+	transport := &recordingTransport{t: t}
+	SetMailTransport(mail.Address{Name: "Monocle", Address: "alerts@example.com"}, transport)
+	t.Cleanup(func() { SetMailTransport(mail.Address{}, nil) })
+	return transport
+}
+
+func TestEmailThreadsIncident(t *testing.T) {
+	transport := useRecordingTransport(t)
+
+	project := models.Project{Name: "Shop"}
+	config := json.RawMessage(`{"addresses": ["oncall@example.com", "OnCall@example.com", "Ops <ops@example.com>"]}`)
+
+	startedAt := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	resolvedAt := startedAt.Add(42 * time.Minute)
+
+	incident := models.Incident{
+		Title:     "Checkout is failing with 500 — ümlauts=ok\r\nBcc: intruder@example.com",
+		Status:    models.IncidentStatusActive,
+		Kind:      models.IncidentKindDown,
+		Severity:  models.SeverityHigh,
+		StartedAt: &startedAt,
+	}
+	incident.ID = 12
+
+	created := NotificationEvent{Type: models.TriggerIncidentCreated, Incident: incident, NotificationID: 40, RuleID: 3}
+
+	incident.Status = models.IncidentStatusResolved
+	incident.ResolvedAt = &resolvedAt
+	resolved := NotificationEvent{Type: models.TriggerIncidentResolved, Incident: incident, NotificationID: 41, RuleID: 3}
+
+	for _, event := range []NotificationEvent{created, resolved} {
+		if err := (emailNotifier{}).Send(project, config, event); err != nil {
+			t.Fatalf("sending %s email: %v", event.Type, err)
+		}
+	}
+
+	if len(transport.sent) != 2 {
+		t.Fatalf("sent %d emails, want 2", len(transport.sent))
+	}
+
+	anchor := "<incident-12.rule-3@example.com>"
+
+	for i, email := range transport.sent {
+		if email.from != "alerts@example.com" {
+			t.Errorf("email %d: envelope sender = %q", i, email.from)
+		}
+
+		if got := strings.Join(email.to, ","); got != "oncall@example.com,ops@example.com" {
+			t.Errorf("email %d: recipients = %q, want each address once", i, got)
+		}
+
+		if got := email.message.Header.Get("Bcc"); got != "" {
+			t.Errorf("email %d: the title started a Bcc header: %q", i, got)
+		}
+
+		subject, err := new(mime.WordDecoder).DecodeHeader(email.message.Header.Get("Subject"))
+		if err != nil {
+			t.Fatalf("email %d: invalid subject: %v", i, err)
+		}
+		if strings.ContainsAny(subject, "\r\n") || !strings.Contains(subject, "ümlauts=ok  Bcc: intruder@example.com") {
+			t.Errorf("email %d: subject = %q, want the title on one line", i, subject)
+		}
+		if replies := strings.HasPrefix(subject, "Re: "); replies != (i == 1) {
+			t.Errorf("email %d: subject = %q, only the resolved email should be a reply", i, subject)
+		}
+
+		if !strings.Contains(email.raw, "Content-Transfer-Encoding: quoted-printable") || !strings.Contains(email.raw, "=C3=BCmlauts=3Dok") {
+			t.Errorf("email %d: body is not quoted-printable", i)
+		}
+
+		text, html := email.parts["text/plain"], email.parts["text/html"]
+		if !strings.Contains(text, "Checkout is failing with 500 — ümlauts=ok") {
+			t.Errorf("email %d: text part does not contain the decoded title:\n%s", i, text)
+		}
+		if !strings.Contains(html, "<html") || !strings.Contains(html, "ümlauts=ok") {
+			t.Errorf("email %d: html part is not the decoded HTML email:\n%s", i, html)
+		}
+	}
+
+	opening := transport.sent[0].message.Header
+	if got := opening.Get("Message-ID"); got != anchor {
+		t.Errorf("created email Message-ID = %q, want %q", got, anchor)
+	}
+	if got := opening.Get("In-Reply-To") + opening.Get("References"); got != "" {
+		t.Errorf("created email replies to %q, want no reply headers", got)
+	}
+
+	reply := transport.sent[1].message.Header
+	if got := reply.Get("Message-ID"); !strings.HasPrefix(got, "<notification-41.") || !strings.HasSuffix(got, "@example.com>") {
+		t.Errorf("resolved email Message-ID = %q, want a unique ID of notification 41", got)
+	}
+	if got := reply.Get("In-Reply-To"); got != anchor {
+		t.Errorf("resolved email In-Reply-To = %q, want %q", got, anchor)
+	}
+	if got := reply.Get("References"); got != anchor {
+		t.Errorf("resolved email References = %q, want %q", got, anchor)
+	}
+}
+
+// Every rule emailing an incident opens a thread of its own, so mail servers
+// do not drop the second rule's email as a duplicate
+func TestEmailThreadPerRule(t *testing.T) {
+	transport := useRecordingTransport(t)
+
+	startedAt := time.Now()
+	incident := models.Incident{Title: "Down", Status: models.IncidentStatusActive, Severity: models.SeverityLow, StartedAt: &startedAt}
+	incident.ID = 7
+
+	config := json.RawMessage(`{"addresses": ["oncall@example.com"]}`)
+
+	for _, ruleID := range []uint{1, 2} {
+		event := NotificationEvent{Type: models.TriggerIncidentCreated, Incident: incident, RuleID: ruleID}
+		if err := (emailNotifier{}).Send(models.Project{Name: "Shop"}, config, event); err != nil {
+			t.Fatalf("sending email of rule %d: %v", ruleID, err)
+		}
+	}
+
+	first := transport.sent[0].message.Header.Get("Message-ID")
+	second := transport.sent[1].message.Header.Get("Message-ID")
+
+	if first == second {
+		t.Errorf("both rules used Message-ID %q", first)
+	}
+}
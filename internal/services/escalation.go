@@ -0,0 +1,96 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/monocle-dev/monocle/internal/models"
+	"gorm.io/gorm"
+)
+
+// EscalationPage is a level of an escalation policy being paged for an incident
+type EscalationPage struct {
+	Policy   string
+	Level    int // 1-based
+	Levels   int
+	Users    []models.User
+	Channels []string // "discord" and "slack", every configured channel when empty
+}
+
+// StartEscalation attaches the escalation policy of the incident's monitor, or
+// else of its project, and makes the first level due right away
+func StartEscalation(tx *gorm.DB, incident *models.Incident, now time.Time) error {
+	var policyID sql.NullInt64
+
+	// Incidents without a monitor match no monitor row and use the project's policy
+	err := tx.Table("projects").
+		Select("COALESCE(monitors.escalation_policy_id, projects.escalation_policy_id)").
+		Joins("LEFT JOIN monitors ON monitors.project_id = projects.id AND monitors.id = ?", incident.MonitorID).
+		Where("projects.id = ?", incident.ProjectID).
+		Row().Scan(&policyID)
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to look up escalation policy: %w", err)
+	}
+
+	incident.EscalationPolicyID = nil
+	incident.EscalationLevel = 0
+	incident.NextEscalationAt = nil
+
+	if policyID.Valid {
+		id := uint(policyID.Int64)
+		incident.EscalationPolicyID = &id
+		incident.NextEscalationAt = &now
+	}
+
+	return nil
+}
+
+// QueueEscalationPage queues the page of an escalation level for the
+// project's webhooks and passes it on to the matching notification rules
+func QueueEscalationPage(tx *gorm.DB, project models.Project, incident models.Incident, page EscalationPage) error {
+	channels := page.Channels
+	if len(channels) == 0 {
+		channels = []string{"discord", "slack"}
+	}
+
+	event := NotificationEvent{Type: models.TriggerIncidentEscalated, Incident: incident, Page: page}
+
+	for _, channel := range channels {
+		switch channel {
+		case "discord":
+			if project.DiscordWebhook == "" {
+				continue
+			}
+		case "slack":
+			if project.SlackWebhook == "" {
+				continue
+			}
+		default:
+			continue
+		}
+
+		if err := queueDelivery(tx, project, nil, channel, event); err != nil {
+			return err
+		}
+	}
+
+	return QueueNotifications(tx, project, event)
+}
+
+// pagedUsers lists the paged users for a message, or a fallback when the level only targets channels
+func pagedUsers(page EscalationPage) string {
+	if len(page.Users) == 0 {
+		return "Everyone on this channel"
+	}
+
+	names := make([]string, 0, len(page.Users))
+	for _, user := range page.Users {
+		names = append(names, fmt.Sprintf("%s (%s)", user.Name, user.Email))
+	}
+
+	return strings.Join(names, ", ")
+}
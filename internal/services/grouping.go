@@ -0,0 +1,293 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/monocle-dev/monocle/db"
+	"github.com/monocle-dev/monocle/internal/models"
+	"gorm.io/gorm"
+)
+
+// maxGroupFields is how many events a grouped message lists, the rest are counted
+const maxGroupFields = 20
+
+// channelRateLimits are the messages per minute a channel sends at most to one
+// destination, below the limits of the services. Channels without one are not
+// limited.
+var channelRateLimits = map[string]int{
+	"discord":    25,
+	"slack":      50,
+	"teams":      30,
+	"mattermost": 60,
+	"telegram":   20,
+	"ntfy":       30,
+	"gotify":     60,
+	"email":      30,
+}
+
+// NotificationRateLimit returns how many messages per minute may be sent for a
+// notification, 0 for no limit. Notifications of the same project, channel and
+// destination share the limit, the rule of the next one decides it.
+func NotificationRateLimit(tx *gorm.DB, notification models.Notification) (int, error) {
+	if notification.RuleID != nil {
+		var rule models.NotificationRule
+
+		err := tx.Select("rate_limit").First(&rule, *notification.RuleID).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, fmt.Errorf("failed to load notification rule %d: %w", *notification.RuleID, err)
+		}
+
+		if rule.RateLimit > 0 {
+			return rule.RateLimit, nil
+		}
+	}
+
+	return channelRateLimits[notification.Channel], nil
+}
+
+// ValidateDigest checks that the events of a rule can be sent as a digest
+func ValidateDigest(channel, trigger, digest string) error {
+	if digest == "" {
+		return nil
+	}
+
+	if _, ok := notifiers[channel].(messageSender); !ok {
+		return fmt.Errorf("The %s channel cannot send digests", channel)
+	}
+
+	if trigger == models.TriggerIncidentEscalated {
+		return errors.New("Escalations cannot be sent as a digest")
+	}
+
+	return nil
+}
+
+// digestKey is the group key of the notifications of a digest rule
+func digestKey(ruleID uint) string {
+	return fmt.Sprintf("rule:%d:digest", ruleID)
+}
+
+// notificationDestination identifies where a notification of a rule, or of the
+// project's own webhooks when rule is nil, is sent: the webhook Discord and
+// Slack post to, the settings of other channels, and for email also the user
+// it falls back to addressing. It is a hash, so no secrets are stored twice.
+func notificationDestination(project models.Project, rule *models.NotificationRule, channel string) string {
+	var config json.RawMessage
+	if rule != nil {
+		config = json.RawMessage(rule.Config)
+	}
+
+	destination := ""
+
+	switch channel {
+	case "discord":
+		destination, _ = webhookURL(config, project.DiscordWebhook, "Discord")
+	case "slack":
+		destination, _ = webhookURL(config, project.SlackWebhook, "Slack")
+	default:
+		// Settings that only differ in key order or spacing go to the same place
+		var settings any
+		if err := json.Unmarshal(config, &settings); err == nil {
+			if canonical, err := json.Marshal(settings); err == nil {
+				config = canonical
+			}
+		}
+		destination = string(config)
+	}
+
+	if channel == "email" && rule != nil {
+		switch {
+		case rule.ScheduleID != nil:
+			destination += fmt.Sprintf(" schedule:%d", *rule.ScheduleID)
+		case rule.UserID != nil:
+			destination += fmt.Sprintf(" user:%d", *rule.UserID)
+		}
+	}
+
+	sum := sha256.Sum256([]byte(destination))
+	return hex.EncodeToString(sum[:8])
+}
+
+// notificationGroupKey returns the key of the notifications an event can be
+// sent together with: those of the same project and channel that go to the
+// same destination, whichever rule or trigger queued them. Escalations page
+// people one by one and channels that do not show rendered messages get one
+// notification per event, so they are never grouped.
+func notificationGroupKey(project models.Project, rule *models.NotificationRule, channel, destination string, event NotificationEvent) string {
+	if rule != nil && rule.Digest != "" {
+		return digestKey(rule.ID)
+	}
+
+	if _, ok := notifiers[channel].(messageSender); !ok || event.Type == models.TriggerIncidentEscalated {
+		return ""
+	}
+
+	return fmt.Sprintf("%d:%s:%s", project.ID, channel, destination)
+}
+
+// nextDigestAt is when the digest covering now is sent: at the start of the
+// next hour or day, in UTC
+func nextDigestAt(digest string, now time.Time) time.Time {
+	now = now.UTC()
+
+	if digest == models.DigestDaily {
+		return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	}
+
+	return now.Truncate(time.Hour).Add(time.Hour)
+}
+
+// DeliverNotifications sends claimed notifications of the same group as one
+// message. Notifications whose event can no longer be loaded are left out.
+func DeliverNotifications(notifications []models.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	lead := notifications[0]
+	digest := ""
+
+	if len(notifications) == 1 && !strings.HasSuffix(lead.GroupKey, ":digest") {
+		return DeliverNotification(lead)
+	}
+
+	var project models.Project
+
+	if err := db.DB.First(&project, lead.ProjectID).Error; err != nil {
+		return fmt.Errorf("failed to load project %d: %w", lead.ProjectID, err)
+	}
+
+	var config json.RawMessage
+	var rule *models.NotificationRule
+
+	if lead.RuleID != nil {
+		rule = &models.NotificationRule{}
+
+		if err := db.DB.First(rule, *lead.RuleID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: notification rule %d was deleted", ErrUndeliverable, *lead.RuleID)
+			}
+			return fmt.Errorf("failed to load notification rule %d: %w", *lead.RuleID, err)
+		}
+
+		config = json.RawMessage(rule.Config)
+
+		if strings.HasSuffix(lead.GroupKey, ":digest") {
+			digest = rule.Digest
+			if digest == "" {
+				digest = models.DigestHourly // The rule stopped sending digests after these were queued
+			}
+		}
+	}
+
+	sender, ok := notifiers[lead.Channel].(messageSender)
+	if !ok {
+		return fmt.Errorf("%w: the %s channel cannot send grouped notifications", ErrUndeliverable, lead.Channel)
+	}
+
+	recipient, err := loadRecipient(lead, rule, time.Now())
+	if err != nil {
+		return err
+	}
+
+	events := make([]NotificationEvent, 0, len(notifications))
+
+	for _, notification := range notifications {
+		event, err := loadEvent(notification)
+		if err != nil {
+			if errors.Is(err, ErrUndeliverable) {
+				log.Printf("Leaving notification %d out of its group: %v", notification.ID, err)
+				continue
+			}
+			return err
+		}
+
+		event.NotificationID = notification.ID
+		event.Recipient = recipient
+		events = append(events, event)
+	}
+
+	if len(events) == 0 {
+		return fmt.Errorf("%w: none of the grouped events could be loaded", ErrUndeliverable)
+	}
+
+	return sender.sendMessage(project, config, renderGroupMessage(project, events, digest, time.Now()))
+}
+
+// groupTitles are the titles and summaries of grouped events of a trigger type
+var groupTitles = map[string]struct{ title, summary string }{
+	models.TriggerIncidentCreated:      {"%d INCIDENTS DETECTED", "%d monitors have encountered an issue and require attention."},
+	models.TriggerMonitorDegraded:      {"%d MONITORS DEGRADED", "%d monitors are responding slower than their thresholds allow."},
+	models.TriggerIncidentResolved:     {"%d INCIDENTS RESOLVED", "%d incidents are back to normal operation."},
+	models.TriggerIncidentAcknowledged: {"%d INCIDENTS ACKNOWLEDGED", "%d incidents are being looked into."},
+	models.TriggerIncidentReminder:     {"%d INCIDENTS STILL OPEN", "%d incidents are still waiting to be acknowledged."},
+	models.TriggerSSLExpiring:          {"%d SSL CERTIFICATES EXPIRING", "%d certificates are about to expire."},
+}
+
+// mixedGroupTitle is the title and summary of grouped events of several trigger types
+var mixedGroupTitle = struct{ title, summary string }{"%d NOTIFICATIONS", "%d events happened in this project."}
+
+// levelRanks orders levels by how much attention a message needs
+var levelRanks = map[string]int{LevelInfo: 1, LevelResolved: 2, LevelWarning: 3, LevelCritical: 4}
+
+// renderGroupMessage describes several events in one message that lists each
+// of them, as a digest when digest is set. Notification templates describe
+// single events and are not applied.
+func renderGroupMessage(project models.Project, events []NotificationEvent, digest string, now time.Time) Message {
+	message := defaultMessage(project, events[0], now)
+	message.Subject = ""
+	message.Fields = nil
+
+	for i, event := range events {
+		item := defaultMessage(project, event, now)
+
+		if levelRanks[item.Level] > levelRanks[message.Level] {
+			message.Level = item.Level
+		}
+		if models.SeverityRank(item.Severity) > models.SeverityRank(message.Severity) {
+			message.Severity = item.Severity
+		}
+
+		if i < maxGroupFields {
+			message.field(item.Emoji, item.Subject, describeEvent(event), false)
+		}
+	}
+
+	if len(events) > maxGroupFields {
+		message.field("➕", "More", fmt.Sprintf("And %d more", len(events)-maxGroupFields), false)
+	}
+
+	switch digest {
+	case models.DigestHourly:
+		message.Emoji = "📰"
+		message.Title = "HOURLY DIGEST"
+		message.Summary = fmt.Sprintf("%d notifications in the last hour.", len(events))
+	case models.DigestDaily:
+		message.Emoji = "📰"
+		message.Title = "DAILY DIGEST"
+		message.Summary = fmt.Sprintf("%d notifications in the last day.", len(events))
+	default:
+		texts, ok := groupTitles[events[0].Type]
+
+		for _, event := range events[1:] {
+			if event.Type != events[0].Type {
+				texts, ok = mixedGroupTitle, true
+				break
+			}
+		}
+
+		if ok {
+			message.Title = fmt.Sprintf(texts.title, len(events))
+			message.Summary = fmt.Sprintf(texts.summary, len(events))
+		}
+	}
+
+	return message
+}
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/monocle-dev/monocle/internal/models"
+	"gorm.io/gorm"
+)
+
+// RecordIncidentEvent adds an entry to the timeline of an incident
+func RecordIncidentEvent(tx *gorm.DB, incidentID uint, eventType string, userID *uint, message string) error {
+	event := models.IncidentEvent{
+		IncidentID: incidentID,
+		Type:       eventType,
+		UserID:     userID,
+		Message:    message,
+	}
+
+	if err := tx.Create(&event).Error; err != nil {
+		return fmt.Errorf("failed to record incident event: %w", err)
+	}
+
+	return nil
+}
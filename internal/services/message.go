@@ -0,0 +1,280 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/monocle-dev/monocle/internal/models"
+)
+
+// Levels of a message, which channels show as colors or priorities
+const (
+	LevelCritical = "critical" // An incident needs attention
+	LevelWarning  = "warning"  // Degraded performance and expiring certificates
+	LevelResolved = "resolved"
+	LevelInfo     = "info" // Updates such as acknowledgements
+)
+
+// MessageField is a labelled value of a message
+type MessageField struct {
+	Emoji  string `json:"emoji"` // Shown before the name by channels that use emoji
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"` // May share a row with other inline fields
+}
+
+// Message is a notification event rendered once for every chat and push
+// channel. Channels only translate it into their own format.
+type Message struct {
+	Emoji     string // Empty for templated titles
+	Title     string // Upper case, e.g. "INCIDENT DETECTED"
+	Subject   string // What the message is about, emphasized where it appears in Summary
+	Summary   string // One plain text sentence, or the rendered body template
+	Level     string
+	Severity  string // Of the incident, empty for monitor events
+	Fields    []MessageField
+	Project   string
+	Test      bool
+	Timestamp time.Time
+	Username  string // Who chat messages are posted as
+	AvatarURL string
+	Recipient *models.User // The user the notification rule notifies, for channels that address people
+}
+
+// Heading is the title with its emoji
+func (m Message) Heading() string {
+	return m.HeadingWith(func(s string) string { return s })
+}
+
+// HeadingWith is Heading with the title passed through mark
+func (m Message) HeadingWith(mark func(string) string) string {
+	if m.Emoji == "" {
+		return mark(m.Title)
+	}
+	return m.Emoji + " " + mark(m.Title)
+}
+
+// Marked returns the summary with its subject passed through mark, e.g. to
+// make it bold in Markdown
+func (m Message) Marked(mark func(string) string) string {
+	if m.Subject == "" {
+		return m.Summary
+	}
+	return strings.Replace(m.Summary, m.Subject, mark(m.Subject), 1)
+}
+
+// Footer names the project a message is about
+func (m Message) Footer() string {
+	if m.Test {
+		return fmt.Sprintf("Project: %s | Monocle Monitoring | Test notification", m.Project)
+	}
+	return fmt.Sprintf("Project: %s | Monocle Monitoring", m.Project)
+}
+
+// Color is the level of a message as an RGB value
+func (m Message) Color() int {
+	switch m.Level {
+	case LevelCritical:
+		return ColorRed
+	case LevelWarning:
+		return ColorOrange
+	case LevelResolved:
+		return ColorGreen
+	}
+	return ColorBlue
+}
+
+// HexColor is Color in CSS notation
+func (m Message) HexColor() string {
+	return fmt.Sprintf("#%06x", m.Color())
+}
+
+func (m *Message) field(emoji, name, value string, inline bool) {
+	if value != "" {
+		m.Fields = append(m.Fields, MessageField{Emoji: emoji, Name: name, Value: value, Inline: inline})
+	}
+}
+
+// renderMessage describes an event for people, with the notification
+// templates of the event's rule or else of its project. Messages whose
+// templates fail to render fall back to the built-in text, so that the
+// notification still goes out.
+func renderMessage(project models.Project, event NotificationEvent, now time.Time) Message {
+	message := defaultMessage(project, event, now)
+
+	templated, err := applyNotificationTemplates(message, project, event, event.Templates.Or(ProjectNotificationTemplates(project)), now)
+	if err != nil {
+		log.Printf("Failed to render notification template of project %d, sending the built-in message: %v", project.ID, err)
+		return message
+	}
+
+	return templated
+}
+
+// defaultMessage is the built-in message of an event
+func defaultMessage(project models.Project, event NotificationEvent, now time.Time) Message {
+	message := Message{
+		Project:   project.Name,
+		Test:      event.Test,
+		Timestamp: now,
+		Username:  project.NotificationUsername,
+		AvatarURL: project.NotificationAvatarURL,
+		Recipient: event.Recipient,
+	}
+
+	if message.Username == "" {
+		message.Username = Username
+	}
+	if message.AvatarURL == "" {
+		message.AvatarURL = AvatarURL
+	}
+
+	if event.Type == models.TriggerSSLExpiring {
+		monitor := event.Monitor
+
+		message.Emoji = "🔒"
+		message.Title = "SSL CERTIFICATE EXPIRING"
+		message.Subject = monitor.Name
+		message.Summary = fmt.Sprintf("The certificate of %s %s.", monitor.Name, sslExpiryText(event.SSLDaysRemaining))
+		message.Level = LevelWarning
+		message.field("📊", "Monitor", monitor.Name, true)
+		message.field("🏷️", "Monitor Type", monitor.Type, true)
+		message.field("📅", "Days Remaining", fmt.Sprintf("%d", event.SSLDaysRemaining), true)
+		return message
+	}
+
+	incident := event.Incident
+	severity := strings.ToUpper(incident.Severity)
+
+	message.Subject = incidentSubject(incident)
+	message.Severity = incident.Severity
+	message.field("📊", "Monitor", incidentMonitorName(incident), true)
+
+	switch event.Type {
+	case models.TriggerIncidentResolved:
+		message.Emoji = "✅"
+		message.Title = "INCIDENT RESOLVED"
+		message.Summary = fmt.Sprintf("%s is back to normal operation.", message.Subject)
+		message.Level = LevelResolved
+
+		message.field("🏷️", "Monitor Type", incidentMonitorType(incident), true)
+		message.field("✅", "Status", incident.Status, true)
+		message.field("🔥", "Severity", severity, true)
+		message.field("📝", "Incident Title", incident.Title, false)
+		message.field("⏰", "Started At", messageTime(incident.StartedAt), true)
+		message.field("🏁", "Resolved At", messageTime(incident.ResolvedAt), true)
+		if incident.StartedAt != nil && incident.ResolvedAt != nil {
+			message.field("⏱️", "Duration", incident.ResolvedAt.Sub(*incident.StartedAt).Round(time.Second).String(), true)
+		}
+	case models.TriggerIncidentAcknowledged:
+		message.Emoji = "👀"
+		message.Title = "INCIDENT ACKNOWLEDGED"
+		message.Summary = fmt.Sprintf("%s is being looked into by %s.", message.Subject, acknowledgedBy(event.User))
+		message.Level = LevelInfo
+
+		message.field("🔥", "Severity", severity, true)
+		message.field("👤", "Acknowledged By", acknowledgedBy(event.User), true)
+		message.field("📝", "Incident Title", incident.Title, false)
+		message.field("⏱️", "Open For", openFor(incident, now), true)
+	case models.TriggerIncidentEscalated:
+		page := event.Page
+
+		message.Emoji = "📟"
+		message.Title = fmt.Sprintf("ESCALATION — LEVEL %d", page.Level)
+		message.Summary = fmt.Sprintf("The incident on %s is waiting to be acknowledged.", message.Subject)
+		message.Level = LevelCritical
+
+		message.field("🔥", "Severity", severity, true)
+		message.field("📶", "Level", fmt.Sprintf("%d of %d (%s)", page.Level, page.Levels, page.Policy), true)
+		message.field("📟", "Paging", pagedUsers(page), false)
+		message.field("📝", "Incident Title", incident.Title, false)
+		message.field("⏱️", "Open For", openFor(incident, now), true)
+	case models.TriggerIncidentReminder:
+		reminder := event.Reminder
+
+		count := fmt.Sprintf("%d", reminder.Count)
+		if reminder.MaxCount > 0 {
+			count = fmt.Sprintf("%d of %d", reminder.Count, reminder.MaxCount)
+		}
+
+		latestError := reminder.LatestError
+		if latestError == "" {
+			latestError = "No failed check recorded"
+		}
+
+		message.Emoji = "⏰"
+		message.Title = "INCIDENT STILL OPEN"
+		message.Summary = fmt.Sprintf("%s has had an unacknowledged incident for %s.", message.Subject, openFor(incident, now))
+		message.Level = incidentLevel(incident)
+
+		message.field("🔥", "Severity", severity, true)
+		message.field("⏱️", "Duration", openFor(incident, now), true)
+		message.field("📝", "Incident Title", incident.Title, false)
+		message.field("❌", "Latest Error", latestError, false)
+		message.field("🔁", "Reminder", count, true)
+	default:
+		message.Emoji = "🚨"
+		message.Title = "INCIDENT DETECTED"
+		message.Summary = fmt.Sprintf("%s has encountered an issue and requires attention.", message.Subject)
+		message.Level = LevelCritical
+
+		if incident.Kind == models.IncidentKindDegraded {
+			message.Emoji = "⚠️"
+			message.Title = "PERFORMANCE DEGRADED"
+			message.Summary = fmt.Sprintf("%s is responding but slower than its thresholds allow.", message.Subject)
+			message.Level = LevelWarning
+		}
+
+		message.field("🏷️", "Monitor Type", incidentMonitorType(incident), true)
+		message.field("⚠️", "Status", incident.Status, true)
+		message.field("🔥", "Severity", severity, true)
+		message.field("📝", "Incident Title", incident.Title, false)
+		message.field("📋", "Description", incident.Description, false)
+		message.field("⏰", "Started At", messageTime(incident.StartedAt), true)
+		message.field("🔄", "Check Interval", incidentCheckInterval(incident), true)
+	}
+
+	return message
+}
+
+// incidentLevel is the level of messages about an open incident
+func incidentLevel(incident models.Incident) string {
+	if incident.Kind == models.IncidentKindDegraded {
+		return LevelWarning
+	}
+	return LevelCritical
+}
+
+func messageTime(at *time.Time) string {
+	if at == nil {
+		return "Unknown"
+	}
+	return at.UTC().Format("2006-01-02 15:04:05 UTC")
+}
+
+// openFor is how long an incident has been open
+func openFor(incident models.Incident, now time.Time) string {
+	if incident.StartedAt == nil {
+		return "Unknown"
+	}
+	return now.Sub(*incident.StartedAt).Round(time.Second).String()
+}
+
+// messageMarkdown renders a message as Markdown for channels without rich
+// layouts, leaving the heading to the channel's own title
+func messageMarkdown(message Message) string {
+	var text strings.Builder
+
+	text.WriteString(message.Marked(func(s string) string { return "**" + s + "**" }))
+	text.WriteString("\n\n")
+
+	for _, field := range message.Fields {
+		fmt.Fprintf(&text, "**%s:** %s\n", field.Name, field.Value)
+	}
+
+	text.WriteString("\n_" + message.Footer() + "_")
+
+	return text.String()
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/monocle-dev/monocle/db"
+	"github.com/monocle-dev/monocle/internal/models"
+	"github.com/monocle-dev/monocle/internal/types"
+)
+
+// Longest rendered title and body, longer ones are cut
+const (
+	maxTemplateTitleLength = 256
+	maxTemplateBodyLength  = 4000
+)
+
+// NotificationTemplates replace the built-in title and body of messages. Empty
+// ones keep the built-in text.
+type NotificationTemplates struct {
+	Title string
+	Body  string
+}
+
+// Or fills the empty templates of t from fallback
+func (t NotificationTemplates) Or(fallback NotificationTemplates) NotificationTemplates {
+	if t.Title == "" {
+		t.Title = fallback.Title
+	}
+	if t.Body == "" {
+		t.Body = fallback.Body
+	}
+	return t
+}
+
+// ProjectNotificationTemplates returns the templates of a project
+func ProjectNotificationTemplates(project models.Project) NotificationTemplates {
+	return NotificationTemplates{Title: project.NotificationTitleTemplate, Body: project.NotificationBodyTemplate}
+}
+
+// NotificationTemplateData is what notification templates are rendered with.
+// It embeds the payload of outgoing webhooks, so templates use its fields
+// under their Go names, e.g. {{.Incident.Title}} or {{.LastCheck.Message}}.
+type NotificationTemplateData struct {
+	types.WebhookEvent
+
+	// The built-in message
+	Emoji   string
+	Title   string
+	Summary string
+	Level   string // "critical", "warning", "resolved" or "info"
+	Fields  []MessageField
+}
+
+// notificationTemplateFuncs are the functions of templates rendered at now
+func notificationTemplateFuncs(now time.Time) template.FuncMap {
+	return template.FuncMap{
+		"formatTime": formatTemplateTime,
+		"duration":   templateDuration(now),
+		"truncate":   templateTruncate,
+		"upper":      strings.ToUpper,
+		"lower":      strings.ToLower,
+	}
+}
+
+// parseNotificationTemplates parses the non-empty templates, to be rendered
+// at now
+func parseNotificationTemplates(templates NotificationTemplates, now time.Time) (*template.Template, *template.Template, error) {
+	var title, body *template.Template
+	var err error
+
+	funcs := notificationTemplateFuncs(now)
+
+	if strings.TrimSpace(templates.Title) != "" {
+		if title, err = template.New("title").Funcs(funcs).Parse(templates.Title); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if strings.TrimSpace(templates.Body) != "" {
+		if body, err = template.New("body").Funcs(funcs).Parse(templates.Body); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return title, body, nil
+}
+
+// ValidateProjectNotifications checks the notification templates and avatar of a project
+func ValidateProjectNotifications(project models.Project) error {
+	if err := ValidateNotificationTemplates(ProjectNotificationTemplates(project), models.TriggerIncidentCreated); err != nil {
+		return fmt.Errorf("Invalid notification template: %v", err)
+	}
+
+	if project.NotificationAvatarURL != "" {
+		return validateHTTPURL(project.NotificationAvatarURL, "notification_avatar_url")
+	}
+
+	return nil
+}
+
+// ValidateNotificationTemplates parses templates and renders them for a made
+// up event of a trigger type, to catch misspelled fields
+func ValidateNotificationTemplates(templates NotificationTemplates, trigger string) error {
+	now := time.Now()
+	event := testEvent(trigger, now)
+
+	_, err := applyNotificationTemplates(defaultMessage(models.Project{Name: "Preview"}, event, now), models.Project{Name: "Preview"}, event, templates, now)
+	return err
+}
+
+// PreviewNotification renders the message of an event with templates,
+// reporting template errors instead of falling back to the built-in text
+func PreviewNotification(project models.Project, templates NotificationTemplates, event NotificationEvent, now time.Time) (Message, error) {
+	return applyNotificationTemplates(defaultMessage(project, event, now), project, event, templates, now)
+}
+
+// PreviewEvent is a made up event of a trigger type, about a real incident
+// when one is given
+func PreviewEvent(trigger string, incident *models.Incident, now time.Time) NotificationEvent {
+	event := testEvent(trigger, now)
+
+	if incident == nil {
+		return event
+	}
+
+	event.Test = false
+
+	if trigger == models.TriggerSSLExpiring {
+		if incident.MonitorID != nil {
+			event.Monitor = incident.Monitor
+		}
+		return event
+	}
+
+	event.Incident = *incident
+
+	if trigger == models.TriggerIncidentAcknowledged && incident.AcknowledgedByID != nil {
+		var user models.User
+		if err := db.DB.First(&user, *incident.AcknowledgedByID).Error; err == nil {
+			event.User = &user
+		}
+	}
+
+	return event
+}
+
+// applyNotificationTemplates replaces the title and body of a message with
+// the rendered templates. A custom body replaces the summary and the fields.
+func applyNotificationTemplates(message Message, project models.Project, event NotificationEvent, templates NotificationTemplates, now time.Time) (Message, error) {
+	title, body, err := parseNotificationTemplates(templates, now)
+	if err != nil || (title == nil && body == nil) {
+		return message, err
+	}
+
+	payload, err := webhookEventPayload(project, event, now)
+	if err != nil {
+		return message, err
+	}
+
+	data := NotificationTemplateData{
+		WebhookEvent: payload,
+		Emoji:        message.Emoji,
+		Title:        message.Title,
+		Summary:      message.Summary,
+		Level:        message.Level,
+		Fields:       message.Fields,
+	}
+
+	if title != nil {
+		var b bytes.Buffer
+		if err := title.Execute(&b, data); err != nil {
+			return message, err
+		}
+
+		message.Emoji = ""
+		message.Title = truncate(strings.Join(strings.Fields(b.String()), " "), maxTemplateTitleLength)
+	}
+
+	if body != nil {
+		var b bytes.Buffer
+		if err := body.Execute(&b, data); err != nil {
+			return message, err
+		}
+
+		message.Subject = ""
+		message.Summary = truncate(strings.TrimSpace(b.String()), maxTemplateBodyLength)
+		message.Fields = nil
+	}
+
+	return message, nil
+}
+
+// formatTemplateTime formats a time, or an optional one, in UTC or the named
+// IANA time zone
+func formatTemplateTime(value interface{}, timezone ...string) (string, error) {
+	var at time.Time
+
+	switch t := value.(type) {
+	case time.Time:
+		at = t
+	case *time.Time:
+		if t == nil {
+			return "-", nil
+		}
+		at = *t
+	default:
+		return "-", nil
+	}
+
+	location := time.UTC
+	if len(timezone) > 0 && timezone[0] != "" {
+		var err error
+		if location, err = time.LoadLocation(timezone[0]); err != nil {
+			return "", fmt.Errorf("unknown time zone %s", timezone[0])
+		}
+	}
+
+	return at.In(location).Format("2006-01-02 15:04:05 MST"), nil
+}
+
+// templateDuration is the time between two times, or from the first one
+// until now
+func templateDuration(now time.Time) func(start interface{}, end ...interface{}) string {
+	return func(start interface{}, end ...interface{}) string {
+		from, ok := templateTime(start)
+		if !ok {
+			return "-"
+		}
+
+		to := now
+		if len(end) > 0 {
+			if to, ok = templateTime(end[0]); !ok {
+				to = now
+			}
+		}
+
+		return to.Sub(from).Round(time.Second).String()
+	}
+}
+
+func templateTime(value interface{}) (time.Time, bool) {
+	switch t := value.(type) {
+	case time.Time:
+		return t, true
+	case *time.Time:
+		if t != nil {
+			return *t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// templateTruncate cuts text to a length, for pipelines like
+// {{.Incident.Description | truncate 200}}
+func templateTruncate(length int, text string) string {
+	if length < 1 {
+		return ""
+	}
+	return truncate(text, length)
+}
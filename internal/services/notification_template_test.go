@@ -0,0 +1,62 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/monocle-dev/monocle/internal/models"
+)
+
+func TestNotificationTemplateFuncs(t *testing.T) {
+	if _, err := time.LoadLocation("Europe/Berlin"); err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	now := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+	startedAt := time.Date(2026, 7, 1, 10, 29, 30, 400_000_000, time.UTC)
+	resolvedAt := time.Date(2026, 7, 1, 11, 0, 0, 0, time.UTC)
+
+	incident := models.Incident{
+		Title:      "Überweisungen schlagen fehl",
+		Status:     models.IncidentStatusResolved,
+		Kind:       models.IncidentKindDown,
+		Severity:   models.SeverityHigh,
+		StartedAt:  &startedAt,
+		ResolvedAt: &resolvedAt,
+	}
+	event := NotificationEvent{Type: models.TriggerIncidentResolved, Incident: incident}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"duration until now", `{{duration .Incident.StartedAt}}`, "1h30m30s"}, // The render time, not the wall clock
+		{"duration between times", `{{duration .Incident.StartedAt .Incident.ResolvedAt}}`, "30m30s"},
+		{"duration of an unset time", `{{duration .Incident.AcknowledgedAt}}`, "-"},
+		{"duration until an unset time", `{{duration .Incident.StartedAt .Incident.AcknowledgedAt}}`, "1h30m30s"},
+		{"formatTime in UTC", `{{formatTime .Incident.StartedAt}}`, "2026-07-01 10:29:30 UTC"},
+		{"formatTime in a time zone", `{{formatTime .Incident.StartedAt "Europe/Berlin"}}`, "2026-07-01 12:29:30 CEST"},
+		{"formatTime west of UTC", `{{formatTime .Incident.ResolvedAt "America/New_York"}}`, "2026-07-01 07:00:00 EDT"},
+		{"formatTime of an unset time", `{{formatTime .Incident.AcknowledgedAt "Europe/Berlin"}}`, "-"},
+		{"truncate counts characters", `{{.Incident.Title | truncate 5}}`, "Über…"},
+		{"truncate keeps short text", `{{truncate 100 .Incident.Title}}`, "Überweisungen schlagen fehl"},
+		{"truncate to nothing", `{{truncate 0 .Incident.Title}}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := PreviewNotification(models.Project{Name: "Bank"}, NotificationTemplates{Body: tt.template}, event, now)
+			if err != nil {
+				t.Fatalf("render: %v", err)
+			}
+			if message.Summary != tt.want {
+				t.Errorf("%s = %q, want %q", tt.template, message.Summary, tt.want)
+			}
+		})
+	}
+
+	if _, err := PreviewNotification(models.Project{}, NotificationTemplates{Body: `{{formatTime .Incident.StartedAt "Mars/Olympus"}}`}, event, now); err == nil {
+		t.Errorf("formatTime with an unknown time zone rendered without an error")
+	}
+}
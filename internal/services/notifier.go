@@ -0,0 +1,216 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/monocle-dev/monocle/internal/models"
+)
+
+// ErrUndeliverable marks delivery failures that retrying cannot fix
+var ErrUndeliverable = errors.New("undeliverable")
+
+// Notifier delivers notification events over one channel
+type Notifier interface {
+	// Validate checks the channel settings of a notification rule
+	Validate(config json.RawMessage) error
+	// Send delivers an event using the settings of a rule, or nil for the project's own settings
+	Send(project models.Project, config json.RawMessage, event NotificationEvent) error
+}
+
+// messageSender is a Notifier of a channel that shows rendered messages. Its
+// messages can describe several events, for grouped notifications and digests.
+type messageSender interface {
+	Notifier
+	sendMessage(project models.Project, config json.RawMessage, message Message) error
+}
+
+// notifiers holds the notifier of every channel rules can use
+var notifiers = map[string]Notifier{
+	"discord":    discordNotifier{},
+	"slack":      slackNotifier{},
+	"email":      emailNotifier{},
+	"webhook":    webhookNotifier{},
+	"pagerduty":  pagerDutyNotifier{},
+	"opsgenie":   opsgenieNotifier{},
+	"teams":      teamsNotifier{},
+	"mattermost": mattermostNotifier{},
+	"telegram":   telegramNotifier{},
+	"ntfy":       ntfyNotifier{},
+	"gotify":     gotifyNotifier{},
+}
+
+// ValidateNotificationChannel checks that a channel exists and that its settings are valid
+func ValidateNotificationChannel(channel string, config json.RawMessage) error {
+	notifier, ok := notifiers[channel]
+	if !ok {
+		return fmt.Errorf("Unknown channel '%s'", channel)
+	}
+
+	return notifier.Validate(config)
+}
+
+// SendTestNotification sends made up data over the channel of a rule right
+// away, bypassing the outbox
+func SendTestNotification(project models.Project, rule models.NotificationRule) error {
+	notifier, ok := notifiers[rule.Channel]
+	if !ok {
+		return fmt.Errorf("unknown channel %s", rule.Channel)
+	}
+
+	event := testEvent(rule.TriggerType, time.Now())
+	event.Templates = NotificationTemplates{Title: rule.TitleTemplate, Body: rule.BodyTemplate}
+
+	recipient, err := loadRecipient(models.Notification{UserID: rule.UserID}, &rule, time.Now())
+	if err != nil {
+		return err
+	}
+
+	event.Recipient = recipient
+	if event.Type == models.TriggerIncidentAcknowledged {
+		event.User = recipient
+	}
+
+	return notifier.Send(project, json.RawMessage(rule.Config), event)
+}
+
+// testEvent makes up an event of a trigger type
+func testEvent(trigger string, now time.Time) NotificationEvent {
+	event := NotificationEvent{Type: trigger, Test: true}
+
+	if trigger == models.TriggerSSLExpiring {
+		event.Monitor = models.Monitor{Name: "Test monitor", Type: "http", Interval: 60}
+		event.SSLDaysRemaining = 7
+		return event
+	}
+
+	startedAt := now.Add(-5 * time.Minute)
+
+	event.Incident = models.Incident{
+		Title:       "Test notification from Monocle",
+		Description: "This is a test of a notification rule, no incident was opened.",
+		Status:      models.IncidentStatusActive,
+		Kind:        models.IncidentKindDown,
+		Severity:    models.SeverityHigh,
+		Source:      models.IncidentSourceManual,
+		StartedAt:   &startedAt,
+	}
+
+	switch trigger {
+	case models.TriggerMonitorDegraded:
+		event.Incident.Kind = models.IncidentKindDegraded
+	case models.TriggerIncidentResolved:
+		event.Incident.Status = models.IncidentStatusResolved
+		event.Incident.ResolvedAt = &now
+	case models.TriggerIncidentAcknowledged:
+		event.Incident.Status = models.IncidentStatusAcknowledged
+		event.Incident.AcknowledgedAt = &now
+	case models.TriggerIncidentEscalated:
+		event.Page = EscalationPage{Policy: "Test policy", Level: 1, Levels: 1}
+	case models.TriggerIncidentReminder:
+		event.Reminder = IncidentReminder{Count: 1}
+	}
+
+	return event
+}
+
+// webhookConfig holds the settings of channels that post to a webhook
+type webhookConfig struct {
+	WebhookURL string `json:"webhook_url"` // The project's webhook when empty
+}
+
+func parseWebhookConfig(config json.RawMessage) (webhookConfig, error) {
+	var settings webhookConfig
+
+	if len(config) == 0 || string(config) == "null" {
+		return settings, nil
+	}
+
+	if err := json.Unmarshal(config, &settings); err != nil {
+		return settings, errors.New("Invalid channel settings")
+	}
+
+	return settings, nil
+}
+
+func validateWebhookConfig(config json.RawMessage) error {
+	settings, err := parseWebhookConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if settings.WebhookURL == "" {
+		return nil
+	}
+
+	return validateHTTPURL(settings.WebhookURL, "webhook_url")
+}
+
+// validateHTTPURL checks that a setting is an absolute http or https URL
+func validateHTTPURL(value, name string) error {
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return fmt.Errorf("%s must be an http or https URL", name)
+	}
+
+	return nil
+}
+
+// webhookURL returns the webhook of a rule, or fallback for the project's own
+func webhookURL(config json.RawMessage, fallback, channel string) (string, error) {
+	settings, err := parseWebhookConfig(config)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrUndeliverable, err)
+	}
+
+	if settings.WebhookURL != "" {
+		return settings.WebhookURL, nil
+	}
+
+	if fallback == "" {
+		return "", fmt.Errorf("%w: no %s webhook configured", ErrUndeliverable, channel)
+	}
+
+	return fallback, nil
+}
+
+type discordNotifier struct{}
+
+func (discordNotifier) Validate(config json.RawMessage) error {
+	return validateWebhookConfig(config)
+}
+
+func (n discordNotifier) Send(project models.Project, config json.RawMessage, event NotificationEvent) error {
+	return n.sendMessage(project, config, renderMessage(project, event, time.Now()))
+}
+
+func (discordNotifier) sendMessage(project models.Project, config json.RawMessage, message Message) error {
+	target, err := webhookURL(config, project.DiscordWebhook, "Discord")
+	if err != nil {
+		return err
+	}
+
+	return postWebhook("Discord", target, discordPayload(message))
+}
+
+type slackNotifier struct{}
+
+func (slackNotifier) Validate(config json.RawMessage) error {
+	return validateWebhookConfig(config)
+}
+
+func (n slackNotifier) Send(project models.Project, config json.RawMessage, event NotificationEvent) error {
+	return n.sendMessage(project, config, renderMessage(project, event, time.Now()))
+}
+
+func (slackNotifier) sendMessage(project models.Project, config json.RawMessage, message Message) error {
+	target, err := webhookURL(config, project.SlackWebhook, "Slack")
+	if err != nil {
+		return err
+	}
+
+	return postWebhook("Slack", target, slackPayload(message))
+}
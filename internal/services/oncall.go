@@ -0,0 +1,71 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/monocle-dev/monocle/internal/models"
+	"github.com/monocle-dev/monocle/internal/oncall"
+	"github.com/monocle-dev/monocle/internal/types"
+	"gorm.io/gorm"
+)
+
+// BuildOnCallSchedule turns a stored schedule and its overrides into a schedule that can be evaluated
+func BuildOnCallSchedule(schedule models.OnCallSchedule, overrides []models.OnCallOverride) (oncall.Schedule, error) {
+	location, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return oncall.Schedule{}, fmt.Errorf("invalid timezone %q: %w", schedule.Timezone, err)
+	}
+
+	var layers []types.RotationLayer
+	if len(schedule.Layers) > 0 {
+		if err := json.Unmarshal(schedule.Layers, &layers); err != nil {
+			return oncall.Schedule{}, fmt.Errorf("invalid rotation layers: %w", err)
+		}
+	}
+
+	result := oncall.Schedule{Location: location, Layers: layers}
+
+	for _, override := range overrides {
+		result.Overrides = append(result.Overrides, oncall.Override{
+			UserID: override.UserID,
+			Start:  override.StartsAt,
+			End:    override.EndsAt,
+		})
+	}
+
+	return result, nil
+}
+
+// LoadOnCallSchedule loads a schedule with its overrides, ordered so that newer overrides win
+func LoadOnCallSchedule(tx *gorm.DB, scheduleID uint) (models.OnCallSchedule, oncall.Schedule, error) {
+	var schedule models.OnCallSchedule
+
+	if err := tx.First(&schedule, scheduleID).Error; err != nil {
+		return schedule, oncall.Schedule{}, err
+	}
+
+	var overrides []models.OnCallOverride
+
+	if err := tx.Where("schedule_id = ?", scheduleID).Order("id").Find(&overrides).Error; err != nil {
+		return schedule, oncall.Schedule{}, err
+	}
+
+	rotation, err := BuildOnCallSchedule(schedule, overrides)
+	return schedule, rotation, err
+}
+
+// loadOnCallSchedule is LoadOnCallSchedule, replaced in tests
+var loadOnCallSchedule = LoadOnCallSchedule
+
+// OnCallUser returns the user on call for a schedule at a time, or false when nobody is
+func OnCallUser(tx *gorm.DB, scheduleID uint, at time.Time) (uint, bool, error) {
+	_, rotation, err := loadOnCallSchedule(tx, scheduleID)
+	if err != nil {
+		return 0, false, err
+	}
+
+	shift, ok := rotation.At(at)
+	return shift.UserID, ok, nil
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/monocle-dev/monocle/db"
+	"github.com/monocle-dev/monocle/internal/models"
+	"gorm.io/gorm"
+)
+
+// DeliverNotification sends a queued notification over its channel, using the
+// current state of its project, incident, monitor and rule
+func DeliverNotification(notification models.Notification) error {
+	var project models.Project
+
+	if err := db.DB.First(&project, notification.ProjectID).Error; err != nil {
+		return fmt.Errorf("failed to load project %d: %w", notification.ProjectID, err)
+	}
+
+	var config json.RawMessage
+	var templates NotificationTemplates
+	var rule *models.NotificationRule
+
+	if notification.RuleID != nil {
+		rule = &models.NotificationRule{}
+
+		if err := db.DB.First(rule, *notification.RuleID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: notification rule %d was deleted", ErrUndeliverable, *notification.RuleID)
+			}
+			return fmt.Errorf("failed to load notification rule %d: %w", *notification.RuleID, err)
+		}
+
+		config = json.RawMessage(rule.Config)
+		templates = NotificationTemplates{Title: rule.TitleTemplate, Body: rule.BodyTemplate}
+	}
+
+	notifier, ok := notifiers[notification.Channel]
+	if !ok {
+		return fmt.Errorf("%w: unknown channel %s", ErrUndeliverable, notification.Channel)
+	}
+
+	event, err := loadEvent(notification)
+	if err != nil {
+		return err
+	}
+
+	event.NotificationID = notification.ID
+	if rule != nil {
+		event.RuleID = rule.ID
+	}
+	event.Templates = templates
+
+	event.Recipient, err = loadRecipient(notification, rule, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return notifier.Send(project, config, event)
+}
+
+// loadEvent rebuilds the event of a queued notification
+func loadEvent(notification models.Notification) (NotificationEvent, error) {
+	event := NotificationEvent{Type: notification.EventType}
+
+	var payload eventPayload
+
+	if len(notification.Payload) > 0 {
+		if err := json.Unmarshal(notification.Payload, &payload); err != nil {
+			return event, fmt.Errorf("%w: invalid payload: %v", ErrUndeliverable, err)
+		}
+	}
+
+	event.SSLDaysRemaining = payload.SSLDaysRemaining
+	event.SSLRenewed = payload.SSLRenewed
+
+	if payload.Reminder != nil {
+		event.Reminder = *payload.Reminder
+	}
+
+	if payload.Page != nil {
+		event.Page = EscalationPage{
+			Policy:   payload.Page.Policy,
+			Level:    payload.Page.Level,
+			Levels:   payload.Page.Levels,
+			Channels: payload.Page.Channels,
+		}
+
+		if len(payload.Page.UserIDs) > 0 {
+			if err := db.DB.Where("id IN ?", payload.Page.UserIDs).Order("id").Find(&event.Page.Users).Error; err != nil {
+				return event, fmt.Errorf("failed to load paged users: %w", err)
+			}
+		}
+	}
+
+	if notification.IncidentID != nil {
+		if err := db.DB.First(&event.Incident, *notification.IncidentID).Error; err != nil {
+			return event, fmt.Errorf("failed to load incident %d: %w", *notification.IncidentID, err)
+		}
+
+		if event.Incident.MonitorID != nil {
+			if err := db.DB.First(&event.Incident.Monitor, *event.Incident.MonitorID).Error; err != nil {
+				return event, fmt.Errorf("failed to load monitor %d: %w", *event.Incident.MonitorID, err)
+			}
+		}
+
+		if event.Type == models.TriggerIncidentAcknowledged && event.Incident.AcknowledgedByID != nil {
+			var user models.User
+			if err := db.DB.First(&user, *event.Incident.AcknowledgedByID).Error; err == nil {
+				event.User = &user
+			}
+		}
+
+		return event, nil
+	}
+
+	if notification.MonitorID == nil {
+		return event, fmt.Errorf("%w: the monitor was deleted", ErrUndeliverable)
+	}
+
+	if err := db.DB.First(&event.Monitor, *notification.MonitorID).Error; err != nil {
+		return event, fmt.Errorf("failed to load monitor %d: %w", *notification.MonitorID, err)
+	}
+
+	return event, nil
+}
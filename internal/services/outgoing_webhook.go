@@ -0,0 +1,233 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/monocle-dev/monocle/db"
+	"github.com/monocle-dev/monocle/internal/models"
+	"github.com/monocle-dev/monocle/internal/types"
+	"gorm.io/gorm"
+)
+
+// minWebhookSecretLength is the shortest secret a webhook can be signed with
+const minWebhookSecretLength = 16
+
+// genericWebhookConfig holds the settings of the webhook channel
+type genericWebhookConfig struct {
+	URL     string            `json:"url"`
+	Secret  string            `json:"secret"`  // Key of the X-Monocle-Signature HMAC
+	Headers map[string]string `json:"headers"` // Sent with every request
+}
+
+// webhookNotifier POSTs a types.WebhookEvent to any URL
+type webhookNotifier struct{}
+
+func parseGenericWebhookConfig(config json.RawMessage) (genericWebhookConfig, error) {
+	var settings genericWebhookConfig
+
+	if err := json.Unmarshal(config, &settings); err != nil {
+		return settings, errors.New("Invalid channel settings")
+	}
+
+	return settings, nil
+}
+
+func (webhookNotifier) Validate(config json.RawMessage) error {
+	settings, err := parseGenericWebhookConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if err := validateHTTPURL(settings.URL, "url"); err != nil {
+		return err
+	}
+
+	if len(settings.Secret) < minWebhookSecretLength {
+		return fmt.Errorf("secret must be at least %d characters long", minWebhookSecretLength)
+	}
+
+	for name, value := range settings.Headers {
+		if !validHeaderName(name) {
+			return fmt.Errorf("Invalid header name '%s'", name)
+		}
+
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("Invalid value of header '%s'", name)
+		}
+
+		switch canonical := http.CanonicalHeaderKey(name); {
+		case canonical == "Content-Type", canonical == "Content-Length", canonical == "Host",
+			strings.HasPrefix(canonical, "X-Monocle-"):
+			return fmt.Errorf("Header '%s' is set by Monocle", name)
+		}
+	}
+
+	return nil
+}
+
+// validHeaderName reports whether a header name only uses token characters
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, c := range name {
+		if c > 127 || c <= ' ' || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (webhookNotifier) Send(project models.Project, config json.RawMessage, event NotificationEvent) error {
+	settings, err := parseGenericWebhookConfig(config)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUndeliverable, err)
+	}
+
+	now := time.Now()
+
+	payload, err := webhookEventPayload(project, event, now)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	headers := make(map[string]string, len(settings.Headers)+4)
+	for name, value := range settings.Headers {
+		headers[name] = value
+	}
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	headers["X-Monocle-Event"] = event.Type
+	headers["X-Monocle-Delivery"] = strconv.FormatUint(uint64(event.NotificationID), 10)
+	headers["X-Monocle-Timestamp"] = timestamp
+	headers["X-Monocle-Signature"] = "sha256=" + SignWebhook(settings.Secret, timestamp, body)
+
+	return postJSON("Outgoing", settings.URL, body, headers)
+}
+
+// SignWebhook returns the hex encoded HMAC-SHA256 of "<timestamp>.<body>".
+// Receivers recompute it to verify a request and reject old timestamps to
+// stop replays.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookEventPayload builds the documented payload of an event
+func webhookEventPayload(project models.Project, event NotificationEvent, now time.Time) (types.WebhookEvent, error) {
+	payload := types.WebhookEvent{
+		Version:    types.WebhookEventVersion,
+		DeliveryID: event.NotificationID,
+		Event:      event.Type,
+		Test:       event.Test,
+		Timestamp:  now.UTC(),
+		Project:    types.WebhookProject{ID: project.ID, Name: project.Name},
+	}
+
+	monitor := event.Monitor
+
+	if event.Type != models.TriggerSSLExpiring {
+		incident := event.Incident
+		payload.Incident = &types.WebhookIncident{
+			ID:             incident.ID,
+			Title:          incident.Title,
+			Description:    incident.Description,
+			Status:         incident.Status,
+			Kind:           incident.Kind,
+			Severity:       incident.Severity,
+			Source:         incident.Source,
+			DedupKey:       incident.DedupKey,
+			MonitorID:      incident.MonitorID,
+			StartedAt:      incident.StartedAt,
+			AcknowledgedAt: incident.AcknowledgedAt,
+			ResolvedAt:     incident.ResolvedAt,
+		}
+
+		if incident.MonitorID != nil {
+			monitor = incident.Monitor
+		}
+	}
+
+	if monitor.Name != "" {
+		payload.Monitor = &types.WebhookMonitor{
+			ID:       monitor.ID,
+			Name:     monitor.Name,
+			Type:     monitor.Type,
+			Interval: monitor.Interval,
+			Tags:     append([]string{}, monitor.Tags...),
+		}
+	}
+
+	if monitor.ID != 0 {
+		var check models.MonitorCheck
+
+		err := db.DB.Where("monitor_id = ?", monitor.ID).Order("checked_at DESC").First(&check).Error
+
+		if err == nil {
+			payload.LastCheck = &types.WebhookCheck{
+				Status:       check.Status,
+				Message:      check.Message,
+				ResponseTime: check.ResponseTime,
+				Location:     check.Location,
+				Metrics:      json.RawMessage(check.Metrics),
+				CheckedAt:    check.CheckedAt,
+			}
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return payload, fmt.Errorf("failed to load latest check: %w", err)
+		}
+	}
+
+	switch event.Type {
+	case models.TriggerIncidentEscalated:
+		escalation := &types.WebhookEscalation{
+			Policy: event.Page.Policy,
+			Level:  event.Page.Level,
+			Levels: event.Page.Levels,
+			Users:  []types.WebhookUser{},
+		}
+		for _, user := range event.Page.Users {
+			escalation.Users = append(escalation.Users, webhookUser(user))
+		}
+		payload.Escalation = escalation
+	case models.TriggerIncidentReminder:
+		payload.Reminder = &types.WebhookReminder{
+			Count:       event.Reminder.Count,
+			MaxCount:    event.Reminder.MaxCount,
+			LatestError: event.Reminder.LatestError,
+		}
+	case models.TriggerIncidentAcknowledged:
+		if event.User != nil {
+			user := webhookUser(*event.User)
+			payload.AcknowledgedBy = &user
+		}
+	case models.TriggerSSLExpiring:
+		days := event.SSLDaysRemaining
+		payload.SSLDaysRemaining = &days
+	}
+
+	return payload, nil
+}
+
+func webhookUser(user models.User) types.WebhookUser {
+	return types.WebhookUser{ID: user.ID, Name: user.Name, Email: user.Email}
+}
@@ -0,0 +1,368 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/monocle-dev/monocle/internal/models"
+)
+
+// Endpoints of the paging services, replaced by stand-in servers in tests
+var (
+	PagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+	OpsgenieAPIURL     = "https://api.opsgenie.com"
+	OpsgenieEUAPIURL   = "https://api.eu.opsgenie.com"
+)
+
+// opsgenieMessageLimit is the longest alert message Opsgenie accepts
+const opsgenieMessageLimit = 130
+
+// pagingChannels keep an alert open until Monocle resolves it
+var pagingChannels = map[string]bool{"pagerduty": true, "opsgenie": true}
+
+// Actions sent to paging services. Acknowledged and resolved incidents and
+// renewed certificates acknowledge and resolve their alert, every other event
+// triggers it.
+const (
+	pageTrigger     = "trigger"
+	pageAcknowledge = "acknowledge"
+	pageResolve     = "resolve"
+)
+
+func pageAction(event NotificationEvent) string {
+	switch {
+	case event.Type == models.TriggerIncidentAcknowledged:
+		return pageAcknowledge
+	case event.Type == models.TriggerIncidentResolved, event.SSLRenewed:
+		return pageResolve
+	}
+	return pageTrigger
+}
+
+// sendPage sends the action of an event to a paging service. Test alerts are
+// resolved right after they were triggered, so none of them stays open.
+func sendPage(event NotificationEvent, send func(action string) error) error {
+	action := pageAction(event)
+
+	if err := send(action); err != nil || !event.Test || action != pageTrigger {
+		return err
+	}
+
+	return send(pageResolve)
+}
+
+// pageDedupKey identifies the alert of an incident, so that every event about
+// it updates the same alert
+func pageDedupKey(event NotificationEvent) string {
+	switch {
+	case event.Test:
+		return "monocle-test"
+	case event.Type == models.TriggerSSLExpiring:
+		return fmt.Sprintf("monocle-monitor-%d-ssl", event.Monitor.ID)
+	}
+	return fmt.Sprintf("monocle-incident-%d", event.Incident.ID)
+}
+
+// pageSummary is the one line title of an alert
+func pageSummary(project models.Project, event NotificationEvent) string {
+	if event.Type == models.TriggerSSLExpiring {
+		return fmt.Sprintf("[%s] SSL certificate of %s %s", project.Name, event.Monitor.Name, sslExpiryText(event.SSLDaysRemaining))
+	}
+	return fmt.Sprintf("[%s] %s", project.Name, event.Incident.Title)
+}
+
+// pageDetails are the custom details of an alert
+func pageDetails(project models.Project, event NotificationEvent) map[string]string {
+	details := map[string]string{"project": project.Name}
+
+	set := func(name, value string) {
+		if value != "" {
+			details[name] = value
+		}
+	}
+
+	if event.Type == models.TriggerSSLExpiring {
+		set("monitor", event.Monitor.Name)
+		set("monitor_type", event.Monitor.Type)
+		set("certificate", sslExpiryText(event.SSLDaysRemaining))
+		return details
+	}
+
+	incident := event.Incident
+
+	set("incident_id", fmt.Sprintf("%d", incident.ID))
+	if incident.MonitorID != nil {
+		set("monitor", incident.Monitor.Name)
+		set("monitor_type", incident.Monitor.Type)
+	}
+	set("severity", incident.Severity)
+	set("status", incident.Status)
+	set("kind", incident.Kind)
+	set("source", incident.Source)
+	set("description", incident.Description)
+
+	if incident.StartedAt != nil {
+		set("started_at", incident.StartedAt.UTC().Format(time.RFC3339))
+	}
+
+	switch event.Type {
+	case models.TriggerIncidentEscalated:
+		set("escalation", fmt.Sprintf("Level %d of %d (%s): %s", event.Page.Level, event.Page.Levels, event.Page.Policy, pagedUsers(event.Page)))
+	case models.TriggerIncidentReminder:
+		set("reminder", fmt.Sprintf("%d", event.Reminder.Count))
+		set("latest_error", event.Reminder.LatestError)
+	}
+
+	return details
+}
+
+// pageSeverity is the severity of an event's incident, SSL warnings are medium
+func pageSeverity(event NotificationEvent) string {
+	if event.Type == models.TriggerSSLExpiring {
+		return models.SeverityMedium
+	}
+	return event.Incident.Severity
+}
+
+type pagerDutyConfig struct {
+	RoutingKey string `json:"routing_key"` // Integration key of an Events API v2 integration
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Timestamp     string            `json:"timestamp,omitempty"`
+	Component     string            `json:"component,omitempty"`
+	Group         string            `json:"group,omitempty"`
+	Class         string            `json:"class,omitempty"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Client      string            `json:"client,omitempty"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"` // Only for triggers
+}
+
+// pagerDutySeverities maps incident severities to PagerDuty's
+var pagerDutySeverities = map[string]string{
+	models.SeverityLow:      "info",
+	models.SeverityMedium:   "warning",
+	models.SeverityHigh:     "error",
+	models.SeverityCritical: "critical",
+}
+
+type pagerDutyNotifier struct{}
+
+func parsePagerDutyConfig(config json.RawMessage) (pagerDutyConfig, error) {
+	var settings pagerDutyConfig
+
+	if err := json.Unmarshal(config, &settings); err != nil {
+		return settings, errors.New("Invalid channel settings")
+	}
+
+	if strings.TrimSpace(settings.RoutingKey) == "" {
+		return settings, errors.New("routing_key is required")
+	}
+
+	return settings, nil
+}
+
+func (pagerDutyNotifier) Validate(config json.RawMessage) error {
+	_, err := parsePagerDutyConfig(config)
+	return err
+}
+
+func (pagerDutyNotifier) Send(project models.Project, config json.RawMessage, event NotificationEvent) error {
+	settings, err := parsePagerDutyConfig(config)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUndeliverable, err)
+	}
+
+	return sendPage(event, func(action string) error {
+		return sendPagerDutyEvent(project, settings, event, action)
+	})
+}
+
+// sendPagerDutyEvent sends one action about an event to the Events API
+func sendPagerDutyEvent(project models.Project, settings pagerDutyConfig, event NotificationEvent, action string) error {
+	request := pagerDutyEvent{
+		RoutingKey:  settings.RoutingKey,
+		EventAction: action,
+		DedupKey:    pageDedupKey(event),
+		Client:      Username,
+	}
+
+	if request.EventAction == pageTrigger {
+		payload := &pagerDutyPayload{
+			Summary:       truncate(pageSummary(project, event), 1024),
+			Source:        "Monocle",
+			Severity:      pagerDutySeverities[pageSeverity(event)],
+			Group:         project.Name,
+			Class:         event.Incident.Kind,
+			CustomDetails: pageDetails(project, event),
+		}
+
+		if payload.Severity == "" {
+			payload.Severity = "error"
+		}
+
+		if event.Type == models.TriggerSSLExpiring {
+			payload.Component = event.Monitor.Name
+			payload.Class = "ssl_expiring"
+		} else if event.Incident.MonitorID != nil {
+			payload.Component = event.Incident.Monitor.Name
+		}
+
+		if event.Incident.StartedAt != nil {
+			payload.Timestamp = event.Incident.StartedAt.UTC().Format(time.RFC3339)
+		}
+
+		request.Payload = payload
+	}
+
+	return postWebhook("PagerDuty", PagerDutyEventsURL, request)
+}
+
+type opsgenieConfig struct {
+	APIKey string `json:"api_key"` // Key of an API integration
+	Region string `json:"region"`  // "us" (default) or "eu"
+}
+
+type opsgenieAlert struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias"`
+	Description string            `json:"description,omitempty"`
+	Entity      string            `json:"entity,omitempty"`
+	Source      string            `json:"source"`
+	Priority    string            `json:"priority"`
+	Tags        []string          `json:"tags,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+}
+
+// opsgenieAction acknowledges or closes an alert
+type opsgenieAction struct {
+	Source string `json:"source"`
+	User   string `json:"user,omitempty"`
+	Note   string `json:"note,omitempty"`
+}
+
+// opsgeniePriorities maps incident severities to Opsgenie priorities
+var opsgeniePriorities = map[string]string{
+	models.SeverityLow:      "P4",
+	models.SeverityMedium:   "P3",
+	models.SeverityHigh:     "P2",
+	models.SeverityCritical: "P1",
+}
+
+type opsgenieNotifier struct{}
+
+func parseOpsgenieConfig(config json.RawMessage) (opsgenieConfig, error) {
+	var settings opsgenieConfig
+
+	if err := json.Unmarshal(config, &settings); err != nil {
+		return settings, errors.New("Invalid channel settings")
+	}
+
+	if strings.TrimSpace(settings.APIKey) == "" {
+		return settings, errors.New("api_key is required")
+	}
+
+	if settings.Region != "" && settings.Region != "us" && settings.Region != "eu" {
+		return settings, errors.New("region must be us or eu")
+	}
+
+	return settings, nil
+}
+
+func (opsgenieNotifier) Validate(config json.RawMessage) error {
+	_, err := parseOpsgenieConfig(config)
+	return err
+}
+
+func (opsgenieNotifier) Send(project models.Project, config json.RawMessage, event NotificationEvent) error {
+	settings, err := parseOpsgenieConfig(config)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUndeliverable, err)
+	}
+
+	return sendPage(event, func(action string) error {
+		return sendOpsgenieRequest(project, settings, event, action)
+	})
+}
+
+// sendOpsgenieRequest creates, acknowledges or closes the alert of an event
+func sendOpsgenieRequest(project models.Project, settings opsgenieConfig, event NotificationEvent, action string) error {
+	base := OpsgenieAPIURL
+	if settings.Region == "eu" {
+		base = OpsgenieEUAPIURL
+	}
+
+	alias := pageDedupKey(event)
+	headers := map[string]string{"Authorization": "GenieKey " + settings.APIKey}
+
+	var target string
+	var request interface{}
+
+	switch action {
+	case pageAcknowledge:
+		target = fmt.Sprintf("%s/v2/alerts/%s/acknowledge?identifierType=alias", base, url.PathEscape(alias))
+		request = opsgenieAction{Source: "Monocle", User: acknowledgedBy(event.User), Note: "Acknowledged in Monocle"}
+	case pageResolve:
+		note := "Resolved in Monocle"
+		if event.SSLRenewed {
+			note = "Certificate was renewed"
+		}
+
+		target = fmt.Sprintf("%s/v2/alerts/%s/close?identifierType=alias", base, url.PathEscape(alias))
+		request = opsgenieAction{Source: "Monocle", Note: note}
+	default:
+		alert := opsgenieAlert{
+			Message:     truncate(pageSummary(project, event), opsgenieMessageLimit),
+			Alias:       alias,
+			Description: event.Incident.Description,
+			Source:      "Monocle",
+			Priority:    opsgeniePriorities[pageSeverity(event)],
+			Tags:        []string{"monocle", event.Type},
+			Details:     pageDetails(project, event),
+		}
+
+		if alert.Priority == "" {
+			alert.Priority = "P3"
+		}
+
+		if event.Type == models.TriggerSSLExpiring {
+			alert.Entity = event.Monitor.Name
+			alert.Tags = append(alert.Tags, event.Monitor.Tags...)
+		} else if event.Incident.MonitorID != nil {
+			alert.Entity = event.Incident.Monitor.Name
+			alert.Tags = append(alert.Tags, event.Incident.Monitor.Tags...)
+		}
+
+		target = base + "/v2/alerts"
+		request = alert
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Opsgenie payload: %w", err)
+	}
+
+	return postJSON("Opsgenie", target, body, headers)
+}
+
+// truncate shortens text to at most limit characters
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
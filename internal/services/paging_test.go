@@ -0,0 +1,231 @@
+package services
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/monocle-dev/monocle/internal/models"
+)
+
+// pageRequest is a request a stand-in paging service received
+type pageRequest struct {
+	path   string // Path and query
+	header http.Header
+	body   map[string]interface{}
+}
+
+// standInPagingServer records the requests it gets and points the paging
+// endpoints at itself for the duration of a test
+func standInPagingServer(t *testing.T) *[]pageRequest {
+	requests := &[]pageRequest{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+
+		var body map[string]interface{}
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("request to %s is not JSON: %v", r.URL, err)
+		}
+
+		*requests = append(*requests, pageRequest{path: r.URL.RequestURI(), header: r.Header, body: body})
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+
+	pagerDuty, opsgenie, opsgenieEU := PagerDutyEventsURL, OpsgenieAPIURL, OpsgenieEUAPIURL
+	PagerDutyEventsURL = server.URL + "/v2/enqueue"
+	OpsgenieAPIURL = server.URL
+	OpsgenieEUAPIURL = server.URL + "/eu"
+	t.Cleanup(func() {
+		PagerDutyEventsURL, OpsgenieAPIURL, OpsgenieEUAPIURL = pagerDuty, opsgenie, opsgenieEU
+	})
+
+	return requests
+}
+
+// pagingEvent is an event of a trigger type about incident 42
+func pagingEvent(trigger, severity string) NotificationEvent {
+	startedAt := time.Date(2026, 4, 1, 8, 0, 0, 0, time.UTC)
+	monitorID := uint(5)
+
+	incident := models.Incident{
+		Title:     "API is down",
+		Status:    models.IncidentStatusActive,
+		Kind:      models.IncidentKindDown,
+		Severity:  severity,
+		StartedAt: &startedAt,
+		MonitorID: &monitorID,
+		Monitor:   models.Monitor{Name: "API", Type: "http"},
+	}
+	incident.ID = 42
+
+	return NotificationEvent{Type: trigger, Incident: incident}
+}
+
+func TestPagerDutyActions(t *testing.T) {
+	tests := []struct {
+		trigger  string
+		severity string
+		action   string
+		payload  string // Expected PagerDuty severity, empty when no payload is sent
+	}{
+		{models.TriggerIncidentCreated, models.SeverityLow, "trigger", "info"},
+		{models.TriggerIncidentCreated, models.SeverityMedium, "trigger", "warning"},
+		{models.TriggerIncidentEscalated, models.SeverityHigh, "trigger", "error"},
+		{models.TriggerIncidentReminder, models.SeverityCritical, "trigger", "critical"},
+		{models.TriggerIncidentAcknowledged, models.SeverityHigh, "acknowledge", ""},
+		{models.TriggerIncidentResolved, models.SeverityHigh, "resolve", ""},
+	}
+
+	config := json.RawMessage(`{"routing_key": "R0UT1NG"}`)
+
+	for _, tt := range tests {
+		t.Run(tt.trigger+"/"+tt.severity, func(t *testing.T) {
+			requests := standInPagingServer(t)
+
+			if err := (pagerDutyNotifier{}).Send(models.Project{Name: "Shop"}, config, pagingEvent(tt.trigger, tt.severity)); err != nil {
+				t.Fatalf("send: %v", err)
+			}
+
+			if len(*requests) != 1 {
+				t.Fatalf("got %d requests, want 1", len(*requests))
+			}
+
+			request := (*requests)[0]
+			if request.path != "/v2/enqueue" {
+				t.Errorf("path = %s", request.path)
+			}
+			if got := request.body["routing_key"]; got != "R0UT1NG" {
+				t.Errorf("routing_key = %v", got)
+			}
+			if got := request.body["event_action"]; got != tt.action {
+				t.Errorf("event_action = %v, want %s", got, tt.action)
+			}
+			if got := request.body["dedup_key"]; got != "monocle-incident-42" {
+				t.Errorf("dedup_key = %v, want monocle-incident-42", got)
+			}
+
+			payload, hasPayload := request.body["payload"].(map[string]interface{})
+			if hasPayload != (tt.payload != "") {
+				t.Fatalf("payload = %v, want one only for triggers", request.body["payload"])
+			}
+			if hasPayload {
+				if got := payload["severity"]; got != tt.payload {
+					t.Errorf("payload severity = %v, want %s", got, tt.payload)
+				}
+				if got := payload["summary"]; got != "[Shop] API is down" {
+					t.Errorf("payload summary = %v", got)
+				}
+				if got := payload["component"]; got != "API" {
+					t.Errorf("payload component = %v", got)
+				}
+			}
+		})
+	}
+}
+
+func TestOpsgenieActions(t *testing.T) {
+	tests := []struct {
+		trigger  string
+		region   string
+		path     string
+		priority string // Expected priority of created alerts
+	}{
+		{models.TriggerIncidentCreated, "", "/v2/alerts", "P4"},
+		{models.TriggerIncidentEscalated, "eu", "/eu/v2/alerts", "P4"},
+		{models.TriggerIncidentAcknowledged, "", "/v2/alerts/monocle-incident-42/acknowledge?identifierType=alias", ""},
+		{models.TriggerIncidentResolved, "eu", "/eu/v2/alerts/monocle-incident-42/close?identifierType=alias", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.trigger+"/"+tt.region, func(t *testing.T) {
+			requests := standInPagingServer(t)
+
+			config := json.RawMessage(`{"api_key": "G3N1E", "region": "` + tt.region + `"}`)
+			if err := (opsgenieNotifier{}).Send(models.Project{Name: "Shop"}, config, pagingEvent(tt.trigger, models.SeverityLow)); err != nil {
+				t.Fatalf("send: %v", err)
+			}
+
+			if len(*requests) != 1 {
+				t.Fatalf("got %d requests, want 1", len(*requests))
+			}
+
+			request := (*requests)[0]
+			if request.path != tt.path {
+				t.Errorf("path = %s, want %s", request.path, tt.path)
+			}
+			if got := request.header.Get("Authorization"); got != "GenieKey G3N1E" {
+				t.Errorf("Authorization = %q", got)
+			}
+
+			if tt.priority != "" {
+				if got := request.body["alias"]; got != "monocle-incident-42" {
+					t.Errorf("alias = %v, want monocle-incident-42", got)
+				}
+				if got := request.body["priority"]; got != tt.priority {
+					t.Errorf("priority = %v, want %s", got, tt.priority)
+				}
+			}
+		})
+	}
+}
+
+func TestOpsgeniePriorities(t *testing.T) {
+	for severity, want := range map[string]string{
+		models.SeverityLow:      "P4",
+		models.SeverityMedium:   "P3",
+		models.SeverityHigh:     "P2",
+		models.SeverityCritical: "P1",
+		"":                      "P3",
+	} {
+		requests := standInPagingServer(t)
+
+		if err := (opsgenieNotifier{}).Send(models.Project{}, json.RawMessage(`{"api_key": "k"}`), pagingEvent(models.TriggerIncidentCreated, severity)); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+
+		if got := (*requests)[0].body["priority"]; got != want {
+			t.Errorf("priority of %q incident = %v, want %s", severity, got, want)
+		}
+	}
+}
+
+// Test alerts and renewed certificates must not leave alerts open
+func TestPagingClosesTestAndSSLAlerts(t *testing.T) {
+	requests := standInPagingServer(t)
+
+	test := testEvent(models.TriggerIncidentCreated, time.Now())
+	if err := (pagerDutyNotifier{}).Send(models.Project{}, json.RawMessage(`{"routing_key": "r"}`), test); err != nil {
+		t.Fatalf("send test event: %v", err)
+	}
+
+	monitor := models.Monitor{Name: "Shop", Type: "http"}
+	monitor.ID = 9
+
+	renewed := NotificationEvent{Type: models.TriggerSSLExpiring, Monitor: monitor, SSLRenewed: true}
+	if err := (opsgenieNotifier{}).Send(models.Project{}, json.RawMessage(`{"api_key": "k"}`), renewed); err != nil {
+		t.Fatalf("send renewal: %v", err)
+	}
+
+	if len(*requests) != 3 {
+		t.Fatalf("got %d requests, want 3", len(*requests))
+	}
+
+	for i, want := range []string{"trigger", "resolve"} {
+		request := (*requests)[i]
+		if got := request.body["event_action"]; got != want {
+			t.Errorf("test request %d: event_action = %v, want %s", i, got, want)
+		}
+		if got := request.body["dedup_key"]; got != "monocle-test" {
+			t.Errorf("test request %d: dedup_key = %v, want monocle-test", i, got)
+		}
+	}
+
+	if got := (*requests)[2].path; got != "/v2/alerts/monocle-monitor-9-ssl/close?identifierType=alias" {
+		t.Errorf("renewal closed %s, want the SSL alert of monitor 9", got)
+	}
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/monocle-dev/monocle/internal/models"
+	"gorm.io/gorm"
+)
+
+// maxPostmortemCheckSamples limits the failed checks quoted in a new postmortem
+const maxPostmortemCheckSamples = 10
+
+// DefaultPostmortemTemplate is used for projects without a template of their own
+const DefaultPostmortemTemplate = `## Summary
+
+_What happened, in a few sentences._
+
+## Impact
+
+- **Incident:** {{.Incident.Title}}
+- **Severity:** {{.Incident.Severity}}
+- **Started:** {{formatTime .Incident.StartedAt}}
+- **Resolved:** {{formatTime .Incident.ResolvedAt}}
+- **Duration:** {{.Duration}}
+
+### Affected Monitors
+
+{{range .AffectedMonitors}}- {{.Name}} ({{.Type}})
+{{end}}
+## Timeline
+
+{{range .Timeline}}- {{formatTime .CreatedAt}} {{.Type}}{{if .Message}}: {{.Message}}{{end}}
+{{end}}
+## Failed Check Samples
+
+{{range .FailedChecks}}- {{formatTime .CheckedAt}} [{{.Location}}] {{.Status}} after {{.ResponseTime}} ms: {{.Message}}
+{{else}}_No failed checks were recorded._
+{{end}}
+## Root Cause
+
+## Resolution
+
+## Lessons Learned
+`
+
+// PostmortemData is what postmortem templates are rendered with
+type PostmortemData struct {
+	Incident         models.Incident
+	Project          models.Project
+	Duration         string
+	AffectedMonitors []models.Monitor // The incident's monitor, its linked monitors and the monitors of incidents it caused
+	Timeline         []models.IncidentEvent
+	FailedChecks     []models.MonitorCheck // Samples from the incident window
+}
+
+var postmortemFuncs = template.FuncMap{
+	"formatTime": formatPostmortemTime,
+}
+
+// ParsePostmortemTemplate parses a postmortem template, the default one when text is empty
+func ParsePostmortemTemplate(text string) (*template.Template, error) {
+	if strings.TrimSpace(text) == "" {
+		text = DefaultPostmortemTemplate
+	}
+
+	return template.New("postmortem").Funcs(postmortemFuncs).Parse(text)
+}
+
+// LoadPostmortemData collects the timeline, check samples and affected monitors of an incident
+func LoadPostmortemData(tx *gorm.DB, project models.Project, incident models.Incident) (PostmortemData, error) {
+	data := PostmortemData{Incident: incident, Project: project, Duration: "Ongoing"}
+
+	end := time.Now()
+	if incident.ResolvedAt != nil {
+		end = *incident.ResolvedAt
+	}
+
+	if incident.StartedAt != nil && incident.ResolvedAt != nil {
+		data.Duration = incident.ResolvedAt.Sub(*incident.StartedAt).Round(time.Second).String()
+	}
+
+	if err := tx.Where("id = ? OR id IN (?) OR id IN (?)", incident.MonitorID,
+		tx.Model(&models.Incident{}).Select("monitor_id").Where("parent_incident_id = ?", incident.ID),
+		tx.Table("incident_monitors").Select("monitor_id").Where("incident_id = ?", incident.ID)).
+		Order("name").
+		Find(&data.AffectedMonitors).Error; err != nil {
+		return data, fmt.Errorf("failed to load affected monitors: %w", err)
+	}
+
+	// Incidents without a monitor of their own quote the checks of the monitors they are about
+	checkedMonitors := make([]uint, 0, len(data.AffectedMonitors))
+	if incident.MonitorID != nil {
+		checkedMonitors = append(checkedMonitors, *incident.MonitorID)
+	} else {
+		for _, monitor := range incident.LinkedMonitors {
+			checkedMonitors = append(checkedMonitors, monitor.ID)
+		}
+	}
+
+	if err := tx.Where("incident_id = ?", incident.ID).Order("created_at").Find(&data.Timeline).Error; err != nil {
+		return data, fmt.Errorf("failed to load timeline: %w", err)
+	}
+
+	query := tx.Where("monitor_id IN ? AND status <> ? AND checked_at <= ?", checkedMonitors, "success", end)
+	if incident.StartedAt != nil {
+		query = query.Where("checked_at >= ?", *incident.StartedAt)
+	}
+
+	if err := query.Order("checked_at").Limit(maxPostmortemCheckSamples).Find(&data.FailedChecks).Error; err != nil {
+		return data, fmt.Errorf("failed to load failed checks: %w", err)
+	}
+
+	return data, nil
+}
+
+// RenderPostmortem fills in the postmortem template of a project
+func RenderPostmortem(data PostmortemData) (string, error) {
+	tmpl, err := ParsePostmortemTemplate(data.Project.PostmortemTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// ExportPostmortemMarkdown renders a postmortem with its contributing factors
+// and action items as one Markdown document. names maps user IDs to names.
+func ExportPostmortemMarkdown(postmortem models.Postmortem, incident models.Incident, names map[uint]string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", postmortem.Title)
+	fmt.Fprintf(&b, "_Postmortem of incident #%d (%s), %s_\n\n", incident.ID, incident.Title, postmortem.Status)
+	b.WriteString(strings.TrimSpace(postmortem.Content))
+	b.WriteString("\n\n## Contributing Factors\n\n")
+
+	if len(postmortem.ContributingFactors) == 0 {
+		b.WriteString("_None recorded._\n")
+	}
+	for _, factor := range postmortem.ContributingFactors {
+		fmt.Fprintf(&b, "- %s\n", factor)
+	}
+
+	b.WriteString("\n## Action Items\n\n")
+
+	if len(postmortem.ActionItems) == 0 {
+		b.WriteString("_None recorded._\n")
+		return b.String()
+	}
+
+	b.WriteString("| Action | Owner | Due | Status |\n")
+	b.WriteString("| --- | --- | --- | --- |\n")
+
+	for _, item := range postmortem.ActionItems {
+		owner := "Unassigned"
+		if item.OwnerID != nil {
+			owner = names[*item.OwnerID]
+		}
+
+		due := "-"
+		if item.DueDate != nil {
+			due = item.DueDate.Format("2006-01-02")
+		}
+
+		fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", escapeMarkdownCell(item.Description), escapeMarkdownCell(owner), due, item.Status)
+	}
+
+	return b.String()
+}
+
+func escapeMarkdownCell(text string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(text, "|", `\|`), "\n", " ")
+}
+
+// formatPostmortemTime formats times and optional times for templates
+func formatPostmortemTime(value interface{}) string {
+	switch t := value.(type) {
+	case time.Time:
+		return t.UTC().Format("2006-01-02 15:04:05 UTC")
+	case *time.Time:
+		if t != nil {
+			return t.UTC().Format("2006-01-02 15:04:05 UTC")
+		}
+	}
+	return "-"
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/monocle-dev/monocle/internal/models"
+)
+
+// NtfyURL is the ntfy server used when a rule does not name one
+var NtfyURL = "https://ntfy.sh"
+
+// pushPriority rates a message from 1 (min) to 5 (max) the way ntfy does.
+// Critical incidents ring through, updates arrive quietly.
+func pushPriority(message Message) int {
+	switch message.Level {
+	case LevelCritical:
+		if message.Severity == models.SeverityCritical {
+			return 5
+		}
+		return 4
+	case LevelWarning:
+		return 3
+	}
+	return 2
+}
+
+// ntfyConfig holds the settings of the ntfy channel
+type ntfyConfig struct {
+	ServerURL string `json:"server_url"` // NtfyURL when empty
+	Topic     string `json:"topic"`
+	Token     string `json:"token"` // Access token of protected topics
+}
+
+type ntfyRequest struct {
+	Topic    string   `json:"topic"`
+	Title    string   `json:"title"`
+	Message  string   `json:"message"`
+	Priority int      `json:"priority"`
+	Tags     []string `json:"tags,omitempty"`
+	Markdown bool     `json:"markdown"`
+}
+
+// ntfyNotifier publishes push notifications to an ntfy topic
+type ntfyNotifier struct{}
+
+func parseNtfyConfig(config json.RawMessage) (ntfyConfig, error) {
+	var settings ntfyConfig
+
+	if err := json.Unmarshal(config, &settings); err != nil {
+		return settings, errors.New("Invalid channel settings")
+	}
+
+	if settings.Topic == "" || strings.ContainsAny(settings.Topic, "/?#") {
+		return settings, errors.New("topic is required and may not contain '/'")
+	}
+
+	if settings.ServerURL == "" {
+		return settings, nil
+	}
+
+	return settings, validateHTTPURL(settings.ServerURL, "server_url")
+}
+
+func (ntfyNotifier) Validate(config json.RawMessage) error {
+	_, err := parseNtfyConfig(config)
+	return err
+}
+
+func (n ntfyNotifier) Send(project models.Project, config json.RawMessage, event NotificationEvent) error {
+	return n.sendMessage(project, config, renderMessage(project, event, time.Now()))
+}
+
+func (ntfyNotifier) sendMessage(project models.Project, config json.RawMessage, message Message) error {
+	settings, err := parseNtfyConfig(config)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUndeliverable, err)
+	}
+
+	server := settings.ServerURL
+	if server == "" {
+		server = NtfyURL
+	}
+
+	body, err := json.Marshal(ntfyRequest{
+		Topic:    settings.Topic,
+		Title:    message.Heading(),
+		Message:  messageMarkdown(message),
+		Priority: pushPriority(message),
+		Tags:     []string{"monocle"},
+		Markdown: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal ntfy payload: %w", err)
+	}
+
+	headers := map[string]string{}
+	if settings.Token != "" {
+		headers["Authorization"] = "Bearer " + settings.Token
+	}
+
+	return postJSON("ntfy", strings.TrimSuffix(server, "/")+"/", body, headers)
+}
+
+// gotifyConfig holds the settings of the Gotify channel
+type gotifyConfig struct {
+	ServerURL string `json:"server_url"`
+	Token     string `json:"token"` // Token of a Gotify application
+}
+
+type gotifyRequest struct {
+	Title    string         `json:"title"`
+	Message  string         `json:"message"`
+	Priority int            `json:"priority"`
+	Extras   map[string]any `json:"extras"`
+}
+
+// gotifyNotifier sends push notifications through a Gotify server
+type gotifyNotifier struct{}
+
+func parseGotifyConfig(config json.RawMessage) (gotifyConfig, error) {
+	var settings gotifyConfig
+
+	if err := json.Unmarshal(config, &settings); err != nil {
+		return settings, errors.New("Invalid channel settings")
+	}
+
+	if settings.Token == "" {
+		return settings, errors.New("token is required")
+	}
+
+	if settings.ServerURL == "" {
+		return settings, errors.New("server_url is required")
+	}
+
+	return settings, validateHTTPURL(settings.ServerURL, "server_url")
+}
+
+func (gotifyNotifier) Validate(config json.RawMessage) error {
+	_, err := parseGotifyConfig(config)
+	return err
+}
+
+func (n gotifyNotifier) Send(project models.Project, config json.RawMessage, event NotificationEvent) error {
+	return n.sendMessage(project, config, renderMessage(project, event, time.Now()))
+}
+
+func (gotifyNotifier) sendMessage(project models.Project, config json.RawMessage, message Message) error {
+	settings, err := parseGotifyConfig(config)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUndeliverable, err)
+	}
+
+	body, err := json.Marshal(gotifyRequest{
+		Title:    message.Heading(),
+		Message:  messageMarkdown(message),
+		Priority: pushPriority(message) * 2, // Gotify rates from 0 to 10
+		Extras: map[string]any{
+			"client::display": map[string]string{"contentType": "text/markdown"},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal Gotify payload: %w", err)
+	}
+
+	return postJSON("Gotify", strings.TrimSuffix(settings.ServerURL, "/")+"/message", body, map[string]string{"X-Gotify-Key": settings.Token})
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/monocle-dev/monocle/internal/models"
+	"gorm.io/gorm"
+)
+
+// maxReminderDelay caps the backoff between two reminders, unless the interval itself is longer
+const maxReminderDelay = 24 * time.Hour
+
+// IncidentReminder is a repeated notification for an incident that stays open
+type IncidentReminder struct {
+	Count       int    // 1 for the first reminder
+	MaxCount    int    // 0 when there is no limit
+	LatestError string // Message of the latest failed check
+}
+
+// ScheduleReminders resets the reminders of an incident and schedules the
+// first one according to the settings of its project
+func ScheduleReminders(tx *gorm.DB, incident *models.Incident, now time.Time) error {
+	var project models.Project
+
+	err := tx.Select("reminder_interval, reminder_backoff, reminder_max_count").
+		First(&project, incident.ProjectID).Error
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to look up reminder settings: %w", err)
+	}
+
+	incident.RemindersSent = 0
+	incident.NextReminderAt = NextReminderAt(project, 0, now)
+
+	return nil
+}
+
+// NextReminderAt returns when the reminder after sent reminders is due, or nil
+// when the project sends no more reminders
+func NextReminderAt(project models.Project, sent int, now time.Time) *time.Time {
+	if project.ReminderInterval <= 0 {
+		return nil
+	}
+
+	if project.ReminderMaxCount > 0 && sent >= project.ReminderMaxCount {
+		return nil
+	}
+
+	backoff := project.ReminderBackoff
+	if backoff < 1 {
+		backoff = 1
+	}
+
+	interval := time.Duration(project.ReminderInterval) * time.Minute
+	limit := maxReminderDelay
+	if interval > limit {
+		limit = interval
+	}
+
+	delay := limit
+	if scaled := float64(interval) * math.Pow(backoff, float64(sent)); scaled < float64(limit) {
+		delay = time.Duration(scaled)
+	}
+
+	next := now.Add(delay)
+	return &next
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+)
+
+// TLS modes of an SMTP server
+const (
+	SMTPTLSStartTLS = "starttls" // Upgrade a plain connection, usually on port 587
+	SMTPTLSImplicit = "tls"      // TLS from the start, usually on port 465
+	SMTPTLSNone     = "none"     // Plain text, for local relays only
+)
+
+const smtpTimeout = 30 * time.Second
+
+// SMTPConfig holds how to reach an SMTP server
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string // No authentication when empty
+	Password string
+	TLS      string // One of the SMTPTLS* constants
+}
+
+// MailTransport hands finished messages to a mail server
+type MailTransport interface {
+	Send(from string, to []string, message []byte) error
+}
+
+// The sender and transport of email notifications, email is unavailable while mailTransport is nil
+var (
+	mailFrom      mail.Address
+	mailTransport MailTransport
+)
+
+// InitMailer sets up email notifications from the SMTP_* environment
+// variables. Email is unavailable when SMTP_HOST is not set.
+func InitMailer() error {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return nil
+	}
+
+	config := SMTPConfig{
+		Host:     host,
+		Port:     os.Getenv("SMTP_PORT"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		TLS:      strings.ToLower(os.Getenv("SMTP_TLS")),
+	}
+
+	if config.TLS == "" {
+		config.TLS = SMTPTLSStartTLS
+	}
+
+	if config.TLS != SMTPTLSStartTLS && config.TLS != SMTPTLSImplicit && config.TLS != SMTPTLSNone {
+		return fmt.Errorf("invalid SMTP_TLS %q, use starttls, tls or none", config.TLS)
+	}
+
+	if config.Port == "" {
+		config.Port = "587"
+		if config.TLS == SMTPTLSImplicit {
+			config.Port = "465"
+		}
+	}
+
+	from, err := mail.ParseAddress(os.Getenv("SMTP_FROM"))
+	if err != nil {
+		return fmt.Errorf("invalid SMTP_FROM: %w", err)
+	}
+
+	SetMailTransport(*from, NewSMTPTransport(config))
+	return nil
+}
+
+// SetMailTransport sets the sender of email notifications and how they are
+// sent, for example through an in-process SMTP server in tests
+func SetMailTransport(from mail.Address, transport MailTransport) {
+	mailFrom = from
+	mailTransport = transport
+}
+
+// SMTPTransport sends messages through an SMTP server
+type SMTPTransport struct {
+	config SMTPConfig
+}
+
+func NewSMTPTransport(config SMTPConfig) *SMTPTransport {
+	return &SMTPTransport{config: config}
+}
+
+func (t *SMTPTransport) Send(from string, to []string, message []byte) error {
+	addr := net.JoinHostPort(t.config.Host, t.config.Port)
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+	tlsConfig := &tls.Config{ServerName: t.config.Host}
+
+	var conn net.Conn
+	var err error
+
+	if t.config.TLS == SMTPTLSImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+
+	if err := conn.SetDeadline(time.Now().Add(smtpTimeout)); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, t.config.Host)
+	if err != nil {
+		conn.Close()
+		return smtpError("greet", err)
+	}
+
+	defer client.Close()
+
+	if t.config.TLS == SMTPTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("%w: SMTP server does not support STARTTLS", ErrUndeliverable)
+		}
+
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return smtpError("start TLS", err)
+		}
+	}
+
+	if t.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", t.config.Username, t.config.Password, t.config.Host)); err != nil {
+			return smtpError("authenticate", err)
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return smtpError("set sender", err)
+	}
+
+	for _, recipient := range to {
+		if err := client.Rcpt(recipient); err != nil {
+			return smtpError("add recipient "+recipient, err)
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return smtpError("start message", err)
+	}
+
+	if _, err := writer.Write(message); err != nil {
+		return smtpError("write message", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return smtpError("send message", err)
+	}
+
+	return client.Quit()
+}
+
+// smtpError describes a failed SMTP command. Permanent failures (5xx replies) are undeliverable.
+func smtpError(action string, err error) error {
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return fmt.Errorf("%w: failed to %s: %v", ErrUndeliverable, action, err)
+	}
+
+	return fmt.Errorf("failed to %s: %w", action, err)
+}
@@ -3,11 +3,15 @@ package services
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/monocle-dev/monocle/internal/models"
+	"gorm.io/gorm"
 )
 
 type DiscordWebhookField struct {
@@ -43,7 +47,7 @@ type SlackField struct {
 
 type SlackAttachment struct {
 	Color     string       `json:"color"`
-	Title     string       `json:"title"`
+	Title     string       `json:"title,omitempty"`
 	Text      string       `json:"text"`
 	Fields    []SlackField `json:"fields"`
 	Footer    string       `json:"footer"`
@@ -52,7 +56,7 @@ type SlackAttachment struct {
 
 type SlackWebhookRequest struct {
 	Username    string            `json:"username"`
-	IconEmoji   string            `json:"icon_emoji,omitempty"`
+	IconURL     string            `json:"icon_url,omitempty"`
 	Text        string            `json:"text"`
 	Attachments []SlackAttachment `json:"attachments"`
 }
@@ -61,258 +65,248 @@ const (
 	ColorRed    = 16711680 // #FF0000 - Incident created
 	ColorGreen  = 65280    // #00FF00 - Incident resolved
 	ColorOrange = 16753920 // #FFA500 - Warning
+	ColorBlue   = 3447003  // #3498DB - Incident acknowledged
 
 	Username  = "Monocle Monitor"
 	AvatarURL = "https://avatars.githubusercontent.com/u/219688397"
 )
 
-func SendIncidentCreatedNotification(project models.Project, incident models.Incident) error {
-	if project.DiscordWebhook != "" {
-		if err := sendDiscordIncidentCreated(project.DiscordWebhook, project, incident); err != nil {
-			return fmt.Errorf("discord: %w", err)
-		}
+// ShouldNotify reports whether incidents of a severity are important enough to notify the project about
+func ShouldNotify(project models.Project, severity string) bool {
+	return models.SeverityRank(severity) >= models.SeverityRank(project.NotifyMinSeverity)
+}
+
+// incidentSubject names what an incident is about, its monitor or else its title
+func incidentSubject(incident models.Incident) string {
+	if incident.MonitorID == nil {
+		return incident.Title
 	}
+	return incident.Monitor.Name
+}
 
-	if project.SlackWebhook != "" {
-		if err := sendSlackIncidentCreated(project.SlackWebhook, project, incident); err != nil {
-			return fmt.Errorf("slack: %w", err)
-		}
+func incidentMonitorName(incident models.Incident) string {
+	if incident.MonitorID == nil {
+		return "None"
 	}
+	return incident.Monitor.Name
+}
 
-	return nil
+// incidentMonitorType returns the type of the incident's monitor, or where the
+// incident came from when it has none
+func incidentMonitorType(incident models.Incident) string {
+	if incident.MonitorID == nil {
+		return incident.Source
+	}
+	return incident.Monitor.Type
 }
 
-func SendIncidentResolvedNotification(project models.Project, incident models.Incident) error {
-	if project.DiscordWebhook != "" {
-		if err := sendDiscordIncidentResolved(project.DiscordWebhook, project, incident); err != nil {
-			return fmt.Errorf("discord: %w", err)
-		}
+func incidentCheckInterval(incident models.Incident) string {
+	if incident.MonitorID == nil {
+		return "Not monitored"
 	}
+	return fmt.Sprintf("%d seconds", incident.Monitor.Interval)
+}
 
-	if project.SlackWebhook != "" {
-		if err := sendSlackIncidentResolved(project.SlackWebhook, project, incident); err != nil {
-			return fmt.Errorf("slack: %w", err)
-		}
+func acknowledgedBy(user *models.User) string {
+	if user == nil {
+		return "Unknown"
 	}
+	return user.Name
+}
 
-	return nil
+// QueueIncidentCreatedNotification announces a new incident, as monitor_degraded for degraded incidents
+func QueueIncidentCreatedNotification(tx *gorm.DB, project models.Project, incident models.Incident) error {
+	return QueueNotifications(tx, project, NotificationEvent{Type: incidentCreatedTrigger(incident), Incident: incident})
 }
 
-func sendDiscordIncidentCreated(webhookURL string, project models.Project, incident models.Incident) error {
-	startedAt := "Unknown"
-	if incident.StartedAt != nil {
-		startedAt = incident.StartedAt.Format("2006-01-02 15:04:05 UTC")
-	}
+// QueueIncidentSeverityRaisedNotification announces an open incident to the
+// channels that skipped it at its previous severity
+func QueueIncidentSeverityRaisedNotification(tx *gorm.DB, project models.Project, incident models.Incident, previousSeverity string) error {
+	return QueueNotifications(tx, project, NotificationEvent{
+		Type:             incidentCreatedTrigger(incident),
+		Incident:         incident,
+		PreviousSeverity: previousSeverity,
+	})
+}
 
-	payload := DiscordWebhookRequest{
-		Username:  Username,
-		AvatarURL: AvatarURL,
-		Embeds: []DiscordEmbed{
-			{
-				Title:       "🚨 **INCIDENT DETECTED**",
-				Description: fmt.Sprintf("**%s** has encountered an issue and requires attention.", incident.Monitor.Name),
-				Color:       ColorRed,
-				Fields: []DiscordWebhookField{
-					{Name: "📊 Monitor", Value: incident.Monitor.Name, Inline: true},
-					{Name: "🏷️ Monitor Type", Value: incident.Monitor.Type, Inline: true},
-					{Name: "⚠️ Status", Value: "**" + incident.Status + "**", Inline: true},
-					{Name: "📝 Incident Title", Value: incident.Title, Inline: false},
-					{Name: "📋 Description", Value: incident.Description, Inline: false},
-					{Name: "⏰ Started At", Value: startedAt, Inline: true},
-					{Name: "🔄 Check Interval", Value: fmt.Sprintf("%d seconds", incident.Monitor.Interval), Inline: true},
-				},
-				Footer: &DiscordFooter{
-					Text: fmt.Sprintf("Project: %s | Monocle Monitoring", project.Name),
-				},
-				Timestamp: time.Now().Format(time.RFC3339),
-			},
-		},
-	}
+func QueueIncidentResolvedNotification(tx *gorm.DB, project models.Project, incident models.Incident) error {
+	return QueueNotifications(tx, project, NotificationEvent{Type: models.TriggerIncidentResolved, Incident: incident})
+}
 
-	return sendDiscordWebhook(webhookURL, payload)
+// QueueIncidentAcknowledgedNotification tells the matching rules who took over an incident
+func QueueIncidentAcknowledgedNotification(tx *gorm.DB, project models.Project, incident models.Incident) error {
+	return QueueNotifications(tx, project, NotificationEvent{Type: models.TriggerIncidentAcknowledged, Incident: incident})
 }
 
-func sendDiscordIncidentResolved(webhookURL string, project models.Project, incident models.Incident) error {
-	startedAt := "Unknown"
-	resolvedAt := "Unknown"
-	duration := "Unknown"
+// QueueIncidentReminderNotification repeats the notification of an incident that is still open and unacknowledged
+func QueueIncidentReminderNotification(tx *gorm.DB, project models.Project, incident models.Incident, reminder IncidentReminder) error {
+	return QueueNotifications(tx, project, NotificationEvent{Type: models.TriggerIncidentReminder, Incident: incident, Reminder: reminder})
+}
 
-	if incident.StartedAt != nil {
-		startedAt = incident.StartedAt.Format("2006-01-02 15:04:05 UTC")
-	}
+// QueueSSLExpiringNotification warns the matching rules about a certificate that expires soon
+func QueueSSLExpiringNotification(tx *gorm.DB, project models.Project, monitor models.Monitor, days int) error {
+	return QueueNotifications(tx, project, NotificationEvent{Type: models.TriggerSSLExpiring, Monitor: monitor, SSLDaysRemaining: days})
+}
 
-	if incident.ResolvedAt != nil {
-		resolvedAt = incident.ResolvedAt.Format("2006-01-02 15:04:05 UTC")
-		if incident.StartedAt != nil {
-			duration = incident.ResolvedAt.Sub(*incident.StartedAt).Round(time.Second).String()
-		}
-	}
+// QueueSSLRenewedNotification resolves the alerts the matching paging rules
+// opened about the certificate of a monitor, once it was renewed
+func QueueSSLRenewedNotification(tx *gorm.DB, project models.Project, monitor models.Monitor) error {
+	return QueueNotifications(tx, project, NotificationEvent{Type: models.TriggerSSLExpiring, Monitor: monitor, SSLRenewed: true})
+}
 
-	payload := DiscordWebhookRequest{
-		Username:  Username,
-		AvatarURL: AvatarURL,
-		Embeds: []DiscordEmbed{
-			{
-				Title:       "✅ **INCIDENT RESOLVED**",
-				Description: fmt.Sprintf("**%s** is back to normal operation.", incident.Monitor.Name),
-				Color:       ColorGreen,
-				Fields: []DiscordWebhookField{
-					{Name: "📊 Monitor", Value: incident.Monitor.Name, Inline: true},
-					{Name: "🏷️ Monitor Type", Value: incident.Monitor.Type, Inline: true},
-					{Name: "✅ Status", Value: "**" + incident.Status + "**", Inline: true},
-					{Name: "📝 Incident Title", Value: incident.Title, Inline: false},
-					{Name: "⏰ Started At", Value: startedAt, Inline: true},
-					{Name: "🏁 Resolved At", Value: resolvedAt, Inline: true},
-					{Name: "⏱️ Duration", Value: duration, Inline: true},
-				},
-				Footer: &DiscordFooter{
-					Text: fmt.Sprintf("Project: %s | Monocle Monitoring", project.Name),
-				},
-				Timestamp: time.Now().Format(time.RFC3339),
-			},
-		},
+func incidentCreatedTrigger(incident models.Incident) string {
+	if incident.Kind == models.IncidentKindDegraded {
+		return models.TriggerMonitorDegraded
 	}
+	return models.TriggerIncidentCreated
+}
 
-	return sendDiscordWebhook(webhookURL, payload)
+// sslExpiryText describes how long a certificate is still valid
+func sslExpiryText(days int) string {
+	switch {
+	case days < 0:
+		return "expired"
+	case days == 0:
+		return "expires today"
+	case days == 1:
+		return "expires in 1 day"
+	}
+	return fmt.Sprintf("expires in %d days", days)
 }
 
-func sendSlackIncidentCreated(webhookURL string, project models.Project, incident models.Incident) error {
-	startedAt := "Unknown"
-	if incident.StartedAt != nil {
-		startedAt = incident.StartedAt.Format("2006-01-02 15:04:05 UTC")
+// discordPayload renders a message as a Discord embed
+func discordPayload(message Message) DiscordWebhookRequest {
+	fields := make([]DiscordWebhookField, 0, len(message.Fields))
+	for _, field := range message.Fields {
+		fields = append(fields, DiscordWebhookField{
+			Name:   field.Emoji + " " + field.Name,
+			Value:  truncate(field.Value, 1024),
+			Inline: field.Inline,
+		})
 	}
 
-	payload := SlackWebhookRequest{
-		Username:  Username,
-		IconEmoji: ":rotating_light:",
-		Text:      ":rotating_light: *INCIDENT DETECTED*",
-		Attachments: []SlackAttachment{
+	return DiscordWebhookRequest{
+		Username:  message.Username,
+		AvatarURL: message.AvatarURL,
+		Embeds: []DiscordEmbed{
 			{
-				Color: "danger",
-				Title: fmt.Sprintf("Monitor '%s' has encountered an issue", incident.Monitor.Name),
-				Text:  incident.Description,
-				Fields: []SlackField{
-					{Title: "Monitor", Value: incident.Monitor.Name, Short: true},
-					{Title: "Type", Value: incident.Monitor.Type, Short: true},
-					{Title: "Status", Value: incident.Status, Short: true},
-					{Title: "Interval", Value: fmt.Sprintf("%d seconds", incident.Monitor.Interval), Short: true},
-					{Title: "Incident Title", Value: incident.Title, Short: false},
-					{Title: "Started At", Value: startedAt, Short: false},
-				},
-				Footer:    fmt.Sprintf("Project: %s", project.Name),
-				Timestamp: time.Now().Unix(),
+				Title:       message.HeadingWith(func(s string) string { return "**" + s + "**" }),
+				Description: message.Marked(func(s string) string { return "**" + s + "**" }),
+				Color:       message.Color(),
+				Fields:      fields,
+				Footer:      &DiscordFooter{Text: message.Footer()},
+				Timestamp:   message.Timestamp.Format(time.RFC3339),
 			},
 		},
 	}
-
-	return sendSlackWebhook(webhookURL, payload)
 }
 
-func sendSlackIncidentResolved(webhookURL string, project models.Project, incident models.Incident) error {
-	startedAt := "Unknown"
-	resolvedAt := "Unknown"
-	duration := "Unknown"
+// slackAttachment renders a message as a Slack attachment, which Mattermost
+// understands as well. bold is the bold marker of the service's Markdown.
+func slackAttachment(message Message, bold string) SlackAttachment {
+	fields := make([]SlackField, 0, len(message.Fields))
+	for _, field := range message.Fields {
+		fields = append(fields, SlackField{Title: field.Name, Value: field.Value, Short: field.Inline})
+	}
 
-	if incident.StartedAt != nil {
-		startedAt = incident.StartedAt.Format("2006-01-02 15:04:05 UTC")
+	return SlackAttachment{
+		Color:     message.HexColor(),
+		Text:      message.Marked(func(s string) string { return bold + s + bold }),
+		Fields:    fields,
+		Footer:    message.Footer(),
+		Timestamp: message.Timestamp.Unix(),
 	}
+}
 
-	if incident.ResolvedAt != nil {
-		resolvedAt = incident.ResolvedAt.Format("2006-01-02 15:04:05 UTC")
-		if incident.StartedAt != nil {
-			duration = incident.ResolvedAt.Sub(*incident.StartedAt).Round(time.Second).String()
-		}
+func slackPayload(message Message) SlackWebhookRequest {
+	return SlackWebhookRequest{
+		Username:    message.Username,
+		IconURL:     message.AvatarURL,
+		Text:        message.HeadingWith(func(s string) string { return "*" + s + "*" }),
+		Attachments: []SlackAttachment{slackAttachment(message, "*")},
 	}
+}
 
-	payload := SlackWebhookRequest{
-		Username:  Username,
-		IconEmoji: ":white_check_mark:",
-		Text:      ":white_check_mark: *INCIDENT RESOLVED*",
-		Attachments: []SlackAttachment{
-			{
-				Color: "good",
-				Title: fmt.Sprintf("Monitor '%s' is back to normal operation", incident.Monitor.Name),
-				Text:  "The incident has been resolved and the monitor is functioning normally.",
-				Fields: []SlackField{
-					{Title: "Monitor", Value: incident.Monitor.Name, Short: true},
-					{Title: "Type", Value: incident.Monitor.Type, Short: true},
-					{Title: "Status", Value: incident.Status, Short: true},
-					{Title: "Duration", Value: duration, Short: true},
-					{Title: "Incident Title", Value: incident.Title, Short: false},
-					{Title: "Started At", Value: startedAt, Short: true},
-					{Title: "Resolved At", Value: resolvedAt, Short: true},
-				},
-				Footer:    fmt.Sprintf("Project: %s", project.Name),
-				Timestamp: time.Now().Unix(),
-			},
-		},
+// RetryAfterError is returned when a service rate limits its webhook
+type RetryAfterError struct {
+	Service string
+	Delay   time.Duration // Zero when the service did not say how long to wait
+}
+
+func (e *RetryAfterError) Error() string {
+	if e.Delay == 0 {
+		return fmt.Sprintf("%s webhook is rate limited", e.Service)
 	}
+	return fmt.Sprintf("%s webhook is rate limited, retry after %s", e.Service, e.Delay)
+}
 
-	return sendSlackWebhook(webhookURL, payload)
+var webhookClient = &http.Client{
+	Timeout: 10 * time.Second,
 }
 
-func sendDiscordWebhook(webhookURL string, payload DiscordWebhookRequest) error {
+// postWebhook posts a JSON payload to the webhook of a service
+func postWebhook(service, webhookURL string, payload interface{}) error {
 	body, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("failed to marshal Discord payload: %w", err)
+		return fmt.Errorf("failed to marshal %s payload: %w", service, err)
 	}
 
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
+	return postJSON(service, webhookURL, body, nil)
+}
 
+// postJSON posts a JSON body with extra headers to the webhook of a service.
+// Client errors other than timeouts and rate limits are undeliverable.
+func postJSON(service, webhookURL string, body []byte, headers map[string]string) error {
 	req, err := http.NewRequest("POST", webhookURL, bytes.NewBuffer(body))
 
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %v", ErrUndeliverable, err)
+	}
+
+	for name, value := range headers {
+		req.Header.Set(name, value)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := client.Do(req)
+	resp, err := webhookClient.Do(req)
 
 	if err != nil {
-		return err
+		// Webhook URLs and bot tokens are secrets, keep them out of the delivery log
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to send %s webhook: %w", service, err)
 	}
 
 	defer resp.Body.Close()
 
-	if resp.StatusCode >= 400 {
-		return fmt.Errorf("Discord webhook returned status %d", resp.StatusCode)
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return &RetryAfterError{Service: service, Delay: retryAfter(resp.Header.Get("Retry-After"), time.Now())}
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode >= 500:
+		return fmt.Errorf("%s webhook returned status %d", service, resp.StatusCode)
+	case resp.StatusCode >= 400:
+		return fmt.Errorf("%w: %s webhook returned status %d", ErrUndeliverable, service, resp.StatusCode)
 	}
 
 	return nil
 }
 
-func sendSlackWebhook(webhookURL string, payload SlackWebhookRequest) error {
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to marshal Slack payload: %w", err)
-	}
-
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-
-	req, err := http.NewRequest("POST", webhookURL, bytes.NewBuffer(body))
-
-	if err != nil {
-		return err
+// retryAfter parses a Retry-After header, given in seconds or as a date
+func retryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := client.Do(req)
-
-	if err != nil {
-		return fmt.Errorf("failed to send Slack webhook: %w", err)
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
 	}
 
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 400 {
-		return fmt.Errorf("Slack webhook returned status %d", resp.StatusCode)
+	if at, err := http.ParseTime(header); err == nil && at.After(now) {
+		return at.Sub(now)
 	}
 
-	return nil
+	return 0
 }
@@ -0,0 +1,30 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AgentMonitor is a monitor assigned to a remote probe agent
+type AgentMonitor struct {
+	ID        uint            `json:"id"`
+	Name      string          `json:"name"`
+	Type      string          `json:"type"`
+	Interval  int             `json:"interval"`
+	Config    json.RawMessage `json:"config"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// AgentResult is a single check result pushed by a remote probe agent
+type AgentResult struct {
+	MonitorID    uint               `json:"monitor_id" binding:"required"`
+	Status       string             `json:"status" binding:"required,oneof=success failure"`
+	ResponseTime int                `json:"response_time"` // Milliseconds
+	Message      string             `json:"message"`
+	Metrics      map[string]float64 `json:"metrics,omitempty"` // Checker metrics, thresholds are applied by the server
+	CheckedAt    time.Time          `json:"checked_at"`
+}
+
+type AgentResultsRequest struct {
+	Results []AgentResult `json:"results" binding:"required,dive"`
+}
@@ -0,0 +1,38 @@
+package types
+
+import (
+	"time"
+)
+
+// InboundAlertRequest is an alert sent to the generic alert endpoint
+type InboundAlertRequest struct {
+	Action      string     `json:"action" binding:"omitempty,oneof=trigger resolve"` // "trigger" by default
+	DedupKey    string     `json:"dedup_key"`                                        // The title by default
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Severity    string     `json:"severity"`
+	StartedAt   *time.Time `json:"started_at"`
+}
+
+// AlertmanagerWebhook is the webhook payload of Prometheus Alertmanager
+type AlertmanagerWebhook struct {
+	Version           string              `json:"version"`
+	GroupKey          string              `json:"groupKey"`
+	Status            string              `json:"status"`
+	Receiver          string              `json:"receiver"`
+	GroupLabels       map[string]string   `json:"groupLabels"`
+	CommonLabels      map[string]string   `json:"commonLabels"`
+	CommonAnnotations map[string]string   `json:"commonAnnotations"`
+	ExternalURL       string              `json:"externalURL"`
+	Alerts            []AlertmanagerAlert `json:"alerts" binding:"required,dive"`
+}
+
+type AlertmanagerAlert struct {
+	Status       string            `json:"status" binding:"required,oneof=firing resolved"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
@@ -7,6 +7,10 @@ import (
 
 const ContextUserKey = "user"
 
+const ContextAgentKey = "agent"
+
+const ContextAlertSourceKey = "alert_source"
+
 var (
 	// Default allowed origins for development
 	defaultOrigins = []string{
@@ -15,8 +19,18 @@ var (
 	}
 
 	AllowedOrigins = initAllowedOrigins()
+
+	// LocalLocation is the location name of checks run by this server's scheduler
+	LocalLocation = initLocalLocation()
 )
 
+func initLocalLocation() string {
+	if location := strings.TrimSpace(os.Getenv("MONOCLE_LOCATION")); location != "" {
+		return location
+	}
+	return "local"
+}
+
 func initAllowedOrigins() []string {
 	origins := make([]string, len(defaultOrigins))
 	copy(origins, defaultOrigins)
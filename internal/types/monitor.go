@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 type HttpConfig struct {
 	Method         string            `json:"method"`
 	URL            string            `json:"url"`
@@ -25,3 +27,72 @@ type DatabaseConfig struct {
 	Timeout  int    `json:"timeout"`
 	SSLMode  string `json:"ssl_mode,omitempty"` // For postgres
 }
+
+// GroupConfig derives the status of a group monitor from its member monitors
+type GroupConfig struct {
+	Members    []uint  `json:"members"`
+	Rule       string  `json:"rule"`       // "all", "any", "at_least" or "percentage"
+	Min        int     `json:"min"`        // Healthy members needed for "at_least"
+	Percentage float64 `json:"percentage"` // Healthy share needed for "percentage", 0-100
+}
+
+// SeverityRule raises the severity of an open incident once all of its
+// conditions hold. A zero condition is not checked.
+type SeverityRule struct {
+	AfterMinutes     int    `json:"after_minutes"`     // Incident open for at least this long
+	FailingLocations int    `json:"failing_locations"` // At least this many locations failing
+	Severity         string `json:"severity"`
+}
+
+type CheckResult struct {
+	Status       string             `json:"status"`        // "success", "degraded", "failure"
+	ResponseTime int                `json:"response_time"` // Milliseconds
+	Message      string             `json:"message"`
+	Metrics      map[string]float64 `json:"metrics,omitempty"`
+	CheckedAt    time.Time          `json:"checked_at"`
+}
+
+// MetricThreshold holds the warning and critical limits for a check metric
+type MetricThreshold struct {
+	Warning  *float64 `json:"warning,omitempty"`
+	Critical *float64 `json:"critical,omitempty"`
+	Below    bool     `json:"below"` // Lower values are worse, e.g. ssl_days_remaining
+}
+
+// Escalation target types
+const (
+	EscalationTargetUser     = "user"
+	EscalationTargetChannel  = "channel"
+	EscalationTargetSchedule = "schedule"
+)
+
+// EscalationLevel is a step of an escalation policy. Its targets are paged when
+// the level is reached and the next level follows after TimeoutMinutes.
+type EscalationLevel struct {
+	TimeoutMinutes int                `json:"timeout_minutes"`
+	Targets        []EscalationTarget `json:"targets"`
+}
+
+// EscalationTarget is who or where a level pages
+type EscalationTarget struct {
+	Type       string `json:"type"`                  // "user", "channel" or "schedule"
+	UserID     uint   `json:"user_id,omitempty"`     // For "user" targets
+	Channel    string `json:"channel,omitempty"`     // "discord" or "slack" for "channel" targets
+	ScheduleID uint   `json:"schedule_id,omitempty"` // For "schedule" targets, pages whoever is on call
+}
+
+// Rotation handoff periods
+const (
+	RotationDaily  = "daily"
+	RotationWeekly = "weekly"
+)
+
+// RotationLayer hands an on-call shift from one participant to the next
+type RotationLayer struct {
+	Name         string     `json:"name"`
+	Rotation     string     `json:"rotation"`      // "daily" or "weekly"
+	ShiftLength  int        `json:"shift_length"`  // Days or weeks per shift, defaults to 1
+	Start        time.Time  `json:"start"`         // First handoff, later handoffs keep its wall clock time in the schedule's timezone
+	End          *time.Time `json:"end,omitempty"` // The layer stops covering the schedule at this time
+	Participants []uint     `json:"participants"`  // User IDs in rotation order
+}
@@ -0,0 +1,85 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEventVersion is the version of the outgoing webhook payload. It is
+// raised when a field is removed or changes meaning, new fields may be added
+// to a version at any time.
+const WebhookEventVersion = "1"
+
+// WebhookEvent is the JSON body the webhook notification channel POSTs
+type WebhookEvent struct {
+	Version    string           `json:"version"`
+	DeliveryID uint             `json:"delivery_id"` // The same for every retry of a delivery, 0 for test sends
+	Event      string           `json:"event"`       // The trigger type, e.g. "incident_created"
+	Test       bool             `json:"test"`        // Sent from the test endpoint with made up data
+	Timestamp  time.Time        `json:"timestamp"`
+	Project    WebhookProject   `json:"project"`
+	Incident   *WebhookIncident `json:"incident"`   // Null for monitor events
+	Monitor    *WebhookMonitor  `json:"monitor"`    // Null for incidents without a monitor
+	LastCheck  *WebhookCheck    `json:"last_check"` // Latest check of the monitor
+
+	Escalation       *WebhookEscalation `json:"escalation,omitempty"`         // For incident_escalated
+	Reminder         *WebhookReminder   `json:"reminder,omitempty"`           // For incident_reminder
+	AcknowledgedBy   *WebhookUser       `json:"acknowledged_by,omitempty"`    // For incident_acknowledged
+	SSLDaysRemaining *int               `json:"ssl_days_remaining,omitempty"` // For ssl_expiring
+}
+
+type WebhookProject struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+type WebhookIncident struct {
+	ID             uint       `json:"id"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	Status         string     `json:"status"`
+	Kind           string     `json:"kind"`
+	Severity       string     `json:"severity"`
+	Source         string     `json:"source"`
+	DedupKey       string     `json:"dedup_key,omitempty"`
+	MonitorID      *uint      `json:"monitor_id"`
+	StartedAt      *time.Time `json:"started_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
+}
+
+type WebhookMonitor struct {
+	ID       uint     `json:"id"`
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Interval int      `json:"interval"`
+	Tags     []string `json:"tags"`
+}
+
+type WebhookCheck struct {
+	Status       string          `json:"status"`
+	Message      string          `json:"message"`
+	ResponseTime int             `json:"response_time"` // Milliseconds
+	Location     string          `json:"location"`
+	Metrics      json.RawMessage `json:"metrics,omitempty"`
+	CheckedAt    time.Time       `json:"checked_at"`
+}
+
+type WebhookEscalation struct {
+	Policy string        `json:"policy"`
+	Level  int           `json:"level"`
+	Levels int           `json:"levels"`
+	Users  []WebhookUser `json:"users"`
+}
+
+type WebhookReminder struct {
+	Count       int    `json:"count"`
+	MaxCount    int    `json:"max_count"` // 0 when there is no limit
+	LatestError string `json:"latest_error"`
+}
+
+type WebhookUser struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/monocle-dev/monocle/internal/middleware"
+	"github.com/monocle-dev/monocle/internal/models"
 	"github.com/monocle-dev/monocle/internal/types"
 )
 
@@ -33,3 +34,35 @@ func GetCurrentUserID(ctx *gin.Context) (uint, error) {
 
 	return user.ID, nil
 }
+
+func GetCurrentAgent(ctx *gin.Context) (models.Agent, error) {
+	agent, exists := ctx.Get(types.ContextAgentKey)
+
+	if !exists {
+		return models.Agent{}, fmt.Errorf("Agent not authenticated")
+	}
+
+	authenticatedAgent, ok := agent.(models.Agent)
+
+	if !ok {
+		return models.Agent{}, fmt.Errorf("Invalid agent type in context")
+	}
+
+	return authenticatedAgent, nil
+}
+
+func GetCurrentAlertSource(ctx *gin.Context) (models.AlertSource, error) {
+	source, exists := ctx.Get(types.ContextAlertSourceKey)
+
+	if !exists {
+		return models.AlertSource{}, fmt.Errorf("Alert source not authenticated")
+	}
+
+	authenticatedSource, ok := source.(models.AlertSource)
+
+	if !ok {
+		return models.AlertSource{}, fmt.Errorf("Invalid alert source type in context")
+	}
+
+	return authenticatedSource, nil
+}
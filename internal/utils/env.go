@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+)
+
+// GetEnvInt reads a positive integer from the environment, falling back to def
+func GetEnvInt(name string, def int) int {
+	if value, err := strconv.Atoi(os.Getenv(name)); err == nil && value > 0 {
+		return value
+	}
+	return def
+}
+
+// GetEnvCount reads a non-negative integer from the environment, falling back
+// to def, for settings where 0 turns something off
+func GetEnvCount(name string, def int) int {
+	if value, err := strconv.Atoi(os.Getenv(name)); err == nil && value >= 0 {
+		return value
+	}
+	return def
+}
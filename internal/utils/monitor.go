@@ -63,6 +63,38 @@ func GetProjectMonitorID(ctx *gin.Context) (uint64, uint64, error) {
 	return projectID, monitorID, nil
 }
 
+func GetIncidentID(ctx *gin.Context) (uint64, error) {
+	incidentIDStr := ctx.Param("incident_id")
+
+	if incidentIDStr == "" {
+		return 0, errors.New("Incident ID not found")
+	}
+
+	incidentID, err := strconv.ParseUint(incidentIDStr, 10, 64)
+
+	if err != nil {
+		return 0, errors.New("Invalid Incident ID")
+	}
+
+	return incidentID, nil
+}
+
+func GetProjectIncidentID(ctx *gin.Context) (uint64, uint64, error) {
+	projectID, err := GetProjectID(ctx)
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	incidentID, err := GetIncidentID(ctx)
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return projectID, incidentID, nil
+}
+
 func ExtractRawDomain(input string) (string, error) {
 	if input == "" {
 		return "", errors.New("input cannot be empty")